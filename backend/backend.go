@@ -0,0 +1,530 @@
+// Package backend define a interface única de acesso ao subsistema de rede,
+// compartilhada pelas interfaces tview e Bubble Tea.
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotSupported indica que o backend não implementa a operação
+	ErrNotSupported = errors.New("operação não suportada por este backend")
+	// ErrNotFound indica que o dispositivo ou perfil não existe
+	ErrNotFound = errors.New("não encontrado")
+	// ErrNotActive indica que o perfil a desativar já está inativo
+	ErrNotActive = errors.New("perfil não está ativo")
+)
+
+// Device representa um dispositivo de rede e seu estado atual
+type Device struct {
+	Name       string   // Nome do dispositivo (eth0, wlan0, ...)
+	Type       string   // Tipo (ethernet, wifi, tun, ...)
+	State      string   // Estado (connected, disconnected, unavailable, ...)
+	Connection string   // Nome do perfil ativo no dispositivo
+	MAC        string   // Endereço MAC
+	IPv4       []string // Endereços IPv4 em notação CIDR
+	IPv6       []string // Endereços IPv6 em notação CIDR
+	Gateway    string   // Gateway IPv4
+	Gateway6   string   // Gateway IPv6
+	DNS        []string // Servidores DNS
+}
+
+// Settings guarda propriedades de um perfil usando os nomes de propriedade
+// do NetworkManager (ex.: "ipv4.method", "ipv4.addresses")
+type Settings map[string]string
+
+// Keys retorna as chaves em ordem alfabética, para gerar comandos estáveis
+func (s Settings) Keys() []string {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Clone retorna uma cópia independente das configurações
+func (s Settings) Clone() Settings {
+	c := make(Settings, len(s))
+	for k, v := range s {
+		c[k] = v
+	}
+	return c
+}
+
+// Propriedades que guardam segredos (senhas e chaves privadas)
+var secretKeys = map[string]bool{
+	"802-11-wireless-security.psk":      true,
+	"802-11-wireless-security.wep-key0": true,
+	"802-11-wireless-security.wep-key1": true,
+	"802-11-wireless-security.wep-key2": true,
+	"802-11-wireless-security.wep-key3": true,
+	"802-1x.password":                   true,
+	"802-1x.private-key-password":       true,
+	"wireguard.private-key":             true,
+	"vpn.secrets":                       true,
+}
+
+// SecretMask substitui o valor das propriedades secretas ao exibi-las
+const SecretMask = "********"
+
+// IsSecret informa se a propriedade guarda um segredo
+func IsSecret(key string) bool {
+	return secretKeys[key]
+}
+
+// Propriedade cujo valor contém segredos: a chave compartilhada de cada peer
+const peersKey = "wireguard.peers"
+
+// Masked retorna uma cópia das configurações com os segredos substituídos
+// por SecretMask, para exibição
+func (s Settings) Masked() Settings {
+	c := s.Clone()
+	for k, v := range c {
+		if v != "" && IsSecret(k) {
+			c[k] = SecretMask
+		}
+	}
+	if v := c[peersKey]; hasPeerKeys(v) {
+		c[peersKey] = maskPeerKeys(v, SecretMask)
+	}
+	return c
+}
+
+// WithoutSecrets retorna uma cópia das configurações sem os segredos, para
+// gravá-las fora do NetworkManager (pacotes e histórico)
+func (s Settings) WithoutSecrets() Settings {
+	c := s.Clone()
+	for k, v := range c {
+		if v != "" && IsSecret(k) {
+			delete(c, k)
+		}
+	}
+	if v := c[peersKey]; hasPeerKeys(v) {
+		c[peersKey] = maskPeerKeys(v, "")
+	}
+	return c
+}
+
+// hasPeerKeys informa se o valor de wireguard.peers contém chaves
+// compartilhadas
+func hasPeerKeys(value string) bool {
+	return strings.Contains(value, "preshared-key=")
+}
+
+// maskPeerKeys substitui a chave compartilhada dos peers em wireguard.peers
+// por mask; com mask vazia, o atributo é removido
+func maskPeerKeys(value, mask string) string {
+	items := strings.Split(value, ",")
+	for i, item := range items {
+		fields := strings.Fields(item)
+		kept := fields[:0]
+		for _, field := range fields {
+			if strings.HasPrefix(field, "preshared-key=") {
+				if mask == "" {
+					continue
+				}
+				field = "preshared-key=" + mask
+			}
+			kept = append(kept, field)
+		}
+		items[i] = strings.Join(kept, " ")
+	}
+	return strings.Join(items, ", ")
+}
+
+// Atributos de rota aceitos pelo NetworkManager ("nome=valor" depois do
+// destino, do próximo salto e da métrica), com o tipo D-Bus de cada um em
+// route-data
+var routeAttributes = map[string]string{
+	"advmss": "u", "cwnd": "u", "initcwnd": "u", "initrwnd": "u", "mtu": "u",
+	"rto_min": "u", "table": "u", "weight": "u", "window": "u",
+	"lock-cwnd": "b", "lock-initcwnd": "b", "lock-initrwnd": "b", "lock-mtu": "b",
+	"lock-window": "b", "onlink": "b", "quickack": "b",
+	"scope": "y", "tos": "y",
+	"from": "s", "src": "s", "type": "s",
+}
+
+// Tipos de rota aceitos no atributo type
+var routeTypes = map[string]bool{
+	"unicast": true, "local": true, "blackhole": true, "unreachable": true, "prohibit": true, "throw": true,
+}
+
+// RouteAttribute valida o atributo de rota name=value e retorna o valor com
+// o tipo usado em route-data (uint32, bool, byte ou string). Os backends
+// aceitam o mesmo conjunto de atributos.
+func RouteAttribute(name, value string) (interface{}, error) {
+	kind, ok := routeAttributes[name]
+	if !ok {
+		return nil, fmt.Errorf("atributo de rota desconhecido: %s", name)
+	}
+	invalid := fmt.Errorf("valor inválido no atributo de rota %s: %s", name, value)
+	switch kind {
+	case "u":
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, invalid
+		}
+		return uint32(n), nil
+	case "y":
+		n, err := strconv.ParseUint(value, 0, 8)
+		if err != nil {
+			return nil, invalid
+		}
+		return byte(n), nil
+	case "b":
+		switch strings.ToLower(value) {
+		case "true", "yes", "on", "1":
+			return true, nil
+		case "false", "no", "off", "0":
+			return false, nil
+		}
+		return nil, invalid
+	}
+	switch {
+	case name == "src" && net.ParseIP(value) == nil,
+		name == "type" && !routeTypes[value]:
+		return nil, invalid
+	case name == "from":
+		if _, _, err := net.ParseCIDR(value); err != nil && net.ParseIP(value) == nil {
+			return nil, invalid
+		}
+	}
+	return value, nil
+}
+
+// Profile representa um perfil de conexão
+type Profile struct {
+	UUID     string   // Identificador único do perfil
+	Name     string   // Nome do perfil
+	Type     string   // Tipo (ethernet, wifi, vpn, ...)
+	Device   string   // Dispositivo associado (vazio se nenhum)
+	Active   bool     // Se o perfil está ativo
+	Settings Settings // Propriedades do perfil
+}
+
+// ID retorna o identificador preferido do perfil (UUID ou, na falta, o nome)
+func (p Profile) ID() string {
+	if p.UUID != "" {
+		return p.UUID
+	}
+	return p.Name
+}
+
+// Autoconnect informa se o perfil é ativado automaticamente; sem a
+// propriedade, vale o padrão do NetworkManager (sim)
+func (p Profile) Autoconnect() bool {
+	return p.Settings["connection.autoconnect"] != "no"
+}
+
+// Priority retorna a prioridade da ativação automática; entre perfis do mesmo
+// dispositivo, o de maior prioridade é ativado primeiro
+func (p Profile) Priority() int {
+	n, _ := strconv.Atoi(p.Settings["connection.autoconnect-priority"])
+	return n
+}
+
+// LastUsed retorna a última ativação do perfil (connection.timestamp), ou
+// zero se ele nunca foi ativado
+func (p Profile) LastUsed() time.Time {
+	n, err := strconv.ParseInt(p.Settings["connection.timestamp"], 10, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(n, 0)
+}
+
+// AccessPoint representa uma rede Wi-Fi encontrada na varredura
+type AccessPoint struct {
+	SSID      string `json:"ssid" yaml:"ssid"`
+	BSSID     string `json:"bssid" yaml:"bssid"`
+	Signal    int    `json:"signal" yaml:"signal"`       // Qualidade do sinal de 0 a 100
+	DBm       int    `json:"dbm" yaml:"dbm"`             // Nível do sinal; estimado pela qualidade se o backend não o informa
+	Frequency int    `json:"frequency" yaml:"frequency"` // Frequência em MHz (0 se desconhecida)
+	Channel   int    `json:"channel" yaml:"channel"`
+	Secured   bool   `json:"secured" yaml:"secured"`
+	Security  string `json:"security" yaml:"security"` // Security* (open, wep, wpa-psk, sae ou wpa-eap)
+}
+
+// Band retorna a faixa de frequência do ponto de acesso (2.4, 5 ou 6 GHz)
+func (ap AccessPoint) Band() string {
+	return FrequencyBand(ap.Frequency)
+}
+
+// Backend é a interface comum para listar dispositivos, ler e alterar perfis,
+// ativar conexões e fazer varredura Wi-Fi
+type Backend interface {
+	// Name retorna o nome do backend
+	Name() string
+	// Devices lista os dispositivos de rede
+	Devices() ([]Device, error)
+	// Profiles lista todos os perfis de conexão
+	Profiles() ([]Profile, error)
+	// Profile obtém um perfil pelo UUID ou nome, com todas as propriedades
+	Profile(id string) (Profile, error)
+	// AddProfile cria um novo perfil e retorna o perfil criado
+	AddProfile(p Profile) (Profile, error)
+	// ModifyProfile altera propriedades de um perfil existente
+	ModifyProfile(id string, settings Settings) error
+	// DeleteProfile remove um perfil
+	DeleteProfile(id string) error
+	// Activate ativa um perfil
+	Activate(id string) error
+	// Deactivate desativa um perfil
+	Deactivate(id string) error
+	// Scan faz a varredura de redes Wi-Fi
+	Scan() ([]AccessPoint, error)
+}
+
+// Watcher é implementado pelos backends capazes de avisar quando o estado da
+// rede muda (dispositivos, endereços ou rotas)
+type Watcher interface {
+	// Watch envia um aviso no canal retornado a cada mudança, até que stop
+	// seja fechado. Avisos próximos podem ser agrupados em um só.
+	Watch(stop <-chan struct{}) (<-chan struct{}, error)
+}
+
+// Cloner é implementado pelos backends capazes de copiar um perfil junto com
+// os segredos, que Profile não retorna
+type Cloner interface {
+	// CloneProfile cria uma cópia do perfil com outro nome e um novo UUID
+	CloneProfile(id, name string) (Profile, error)
+}
+
+// SecretReader é implementado pelos backends que guardam os segredos fora das
+// propriedades retornadas por Profile
+type SecretReader interface {
+	// ProfileWithSecrets obtém o perfil com todas as propriedades, incluindo
+	// os segredos
+	ProfileWithSecrets(id string) (Profile, error)
+}
+
+// Planner é implementado pelos backends capazes de descrever, sem executar,
+// os comandos que executariam
+type Planner interface {
+	// Plan descreve ModifyProfile seguido de Activate
+	Plan(id string, settings Settings) []string
+	// PlanAdd descreve AddProfile
+	PlanAdd(p Profile) []string
+}
+
+// VPN é implementado pelos backends capazes de importar e acompanhar túneis
+// VPN
+type VPN interface {
+	// ImportVPN cria um perfil a partir de um arquivo WireGuard (.conf) ou
+	// OpenVPN (.ovpn); kind é VPNWireGuard ou VPNOpenVPN
+	ImportVPN(kind, path string) (Profile, error)
+	// WireGuardStatus informa o estado dos peers do túnel WireGuard ativo no
+	// dispositivo, incluindo o último handshake
+	WireGuardStatus(device string) ([]PeerStatus, error)
+}
+
+// WiFi é implementado pelos backends que delegam ao NetworkManager a
+// varredura e a conexão a redes sem fio
+type WiFi interface {
+	// Rescan pede uma nova varredura ao dispositivo (a todos, se vazio)
+	Rescan(device string) error
+	// ConnectWiFi conecta a uma rede aberta ainda sem perfil salvo; o perfil
+	// criado fica salvo com conexão automática. hidden indica uma rede que não
+	// anuncia o SSID. Redes com senha são criadas com AddProfile, que não
+	// expõe a senha na linha de comando.
+	ConnectWiFi(device, ssid string, hidden bool) (Profile, error)
+}
+
+// Change é a alteração de uma propriedade de perfil
+type Change struct {
+	Key    string `json:"key" yaml:"key"`
+	Before string `json:"before" yaml:"before"`
+	After  string `json:"after" yaml:"after"`
+}
+
+// Changed informa se o valor da propriedade muda
+func (c Change) Changed() bool {
+	return c.Before != c.After
+}
+
+// DiffSettings compara, para cada propriedade de after, o valor atual em
+// current com o novo valor, em ordem alfabética
+func DiffSettings(current, after Settings) []Change {
+	changes := make([]Change, 0, len(after))
+	for _, key := range after.Keys() {
+		changes = append(changes, Change{Key: key, Before: current[key], After: after[key]})
+	}
+	return changes
+}
+
+// notify envia um aviso sem bloquear; se já houver um pendente, os dois são
+// agrupados
+func notify(events chan struct{}) {
+	select {
+	case events <- struct{}{}:
+	default:
+	}
+}
+
+var (
+	mu      sync.RWMutex
+	current Backend
+)
+
+// Default retorna o backend em uso, detectando-o na primeira chamada
+func Default() Backend {
+	mu.RLock()
+	b := current
+	mu.RUnlock()
+	if b != nil {
+		return b
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if current == nil {
+		current = Detect()
+	}
+	return current
+}
+
+// SetDefault define o backend usado pelas interfaces
+func SetDefault(b Backend) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = b
+}
+
+// Detect escolhe o backend adequado para o sistema: NetworkManager via D-Bus
+// quando o serviço está no barramento, nmcli quando apenas o comando está
+// disponível e iproute2 nos demais casos
+func Detect() Backend {
+	if b, err := NewDBus(); err == nil {
+		return b
+	}
+	if _, err := exec.LookPath("nmcli"); err == nil {
+		return NewNetworkManager()
+	}
+	return NewIPRoute()
+}
+
+// ProfileType retorna o tipo de perfil do NetworkManager correspondente ao
+// tipo de dispositivo ("ethernet" → "802-3-ethernet"); outros tipos são
+// retornados sem alteração
+func ProfileType(deviceType string) string {
+	switch deviceType {
+	case "ethernet":
+		return "802-3-ethernet"
+	case "wifi":
+		return "802-11-wireless"
+	default:
+		return deviceType
+	}
+}
+
+// Tipos de interfaces virtuais criadas a partir de perfis
+var virtualTypes = map[string]bool{"vlan": true, "bond": true, "bridge": true, "team": true}
+
+// IsVirtualType informa se o tipo de perfil cria uma interface virtual (VLAN,
+// bond, bridge ou team)
+func IsVirtualType(profileType string) bool {
+	return virtualTypes[profileType]
+}
+
+// ProfilesForDevice lista os perfis que podem ser usados no dispositivo: os
+// associados a ele e os genéricos (sem interface definida) do mesmo tipo. O
+// perfil ativo vem primeiro.
+func ProfilesForDevice(b Backend, dev Device) ([]Profile, error) {
+	profiles, err := b.Profiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var active, others []Profile
+	for _, p := range profiles {
+		if p.Device != "" && p.Device != dev.Name {
+			continue
+		}
+		if p.Device == "" {
+			// Perfis inativos nem sempre informam a interface na listagem
+			if ProfileType(p.Type) != ProfileType(dev.Type) {
+				continue
+			}
+			full, err := b.Profile(p.ID())
+			if err != nil {
+				return nil, err
+			}
+			if iface := full.Settings["connection.interface-name"]; iface != "" && iface != dev.Name {
+				continue
+			}
+		}
+
+		if p.Active {
+			active = append(active, p)
+		} else {
+			others = append(others, p)
+		}
+	}
+	return append(active, others...), nil
+}
+
+// CloneProfile copia o perfil com outro nome. Nos backends sem Cloner, as
+// propriedades retornadas por Profile são copiadas com AddProfile.
+func CloneProfile(b Backend, id, name string) (Profile, error) {
+	if c, ok := b.(Cloner); ok {
+		return c.CloneProfile(id, name)
+	}
+
+	p, err := b.Profile(id)
+	if err != nil {
+		return Profile{}, err
+	}
+	settings := p.Settings.Clone()
+	for _, key := range []string{"connection.id", "connection.uuid", "connection.timestamp"} {
+		delete(settings, key)
+	}
+	device := p.Device
+	if iface, ok := settings["connection.interface-name"]; ok {
+		device = iface
+	}
+	return b.AddProfile(Profile{Name: name, Type: p.Type, Device: device, Settings: settings})
+}
+
+// ProfileWithSecrets obtém o perfil junto com os segredos. Nos backends sem
+// SecretReader, Profile já retorna todas as propriedades gravadas.
+func ProfileWithSecrets(b Backend, id string) (Profile, error) {
+	if r, ok := b.(SecretReader); ok {
+		return r.ProfileWithSecrets(id)
+	}
+	return b.Profile(id)
+}
+
+// FindProfileForDevice retorna o perfil ativo do dispositivo ou, na falta, o
+// primeiro perfil associado a ele
+func FindProfileForDevice(b Backend, device string) (Profile, error) {
+	profiles, err := b.Profiles()
+	if err != nil {
+		return Profile{}, err
+	}
+
+	var found *Profile
+	for i := range profiles {
+		if profiles[i].Device != device {
+			continue
+		}
+		if profiles[i].Active {
+			return profiles[i], nil
+		}
+		if found == nil {
+			found = &profiles[i]
+		}
+	}
+	if found == nil {
+		return Profile{}, ErrNotFound
+	}
+	return *found, nil
+}
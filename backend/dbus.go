@@ -0,0 +1,675 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Nomes e caminhos do NetworkManager no barramento de sistema
+const (
+	nmDest             = "org.freedesktop.NetworkManager"
+	nmPath             = "/org/freedesktop/NetworkManager"
+	nmSettingsPath     = "/org/freedesktop/NetworkManager/Settings"
+	nmIface            = "org.freedesktop.NetworkManager"
+	nmDeviceIface      = "org.freedesktop.NetworkManager.Device"
+	nmWirelessIface    = "org.freedesktop.NetworkManager.Device.Wireless"
+	nmActiveIface      = "org.freedesktop.NetworkManager.Connection.Active"
+	nmIP4ConfigIface   = "org.freedesktop.NetworkManager.IP4Config"
+	nmIP6ConfigIface   = "org.freedesktop.NetworkManager.IP6Config"
+	nmSettingsIface    = "org.freedesktop.NetworkManager.Settings"
+	nmConnectionIface  = "org.freedesktop.NetworkManager.Settings.Connection"
+	nmAccessPointIface = "org.freedesktop.NetworkManager.AccessPoint"
+)
+
+// Grupos de configuração que guardam segredos, lidos antes de um Update para
+// que não sejam apagados
+var secretSettings = []string{"802-11-wireless-security", "802-1x", "vpn", "wireguard"}
+
+// DBus implementa Backend falando diretamente com o NetworkManager pelo
+// barramento de sistema, sem interpretar a saída textual do nmcli
+type DBus struct {
+	conn *dbus.Conn
+}
+
+// NewDBus conecta ao barramento de sistema (respeitando
+// DBUS_SYSTEM_BUS_ADDRESS) e verifica se o NetworkManager está presente
+func NewDBus() (*DBus, error) {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar ao D-Bus de sistema: %w", err)
+	}
+
+	var hasOwner bool
+	if err := conn.BusObject().Call("org.freedesktop.DBus.NameHasOwner", 0, nmDest).Store(&hasOwner); err != nil {
+		return nil, fmt.Errorf("erro ao consultar o D-Bus: %w", err)
+	}
+	if !hasOwner {
+		return nil, fmt.Errorf("NetworkManager não está presente no D-Bus")
+	}
+	return NewDBusWithConn(conn), nil
+}
+
+// NewDBusWithConn cria o backend sobre uma conexão já estabelecida, o que
+// permite usá-lo com um barramento privado e um serviço NetworkManager falso
+func NewDBusWithConn(conn *dbus.Conn) *DBus {
+	return &DBus{conn: conn}
+}
+
+// Name retorna o nome do backend
+func (d *DBus) Name() string {
+	return "dbus"
+}
+
+// Devices lista os dispositivos com endereços, gateways e DNS
+func (d *DBus) Devices() ([]Device, error) {
+	var paths []dbus.ObjectPath
+	if err := d.object(nmPath).Call(nmIface+".GetDevices", 0).Store(&paths); err != nil {
+		return nil, fmt.Errorf("erro ao obter dispositivos: %w", err)
+	}
+
+	var devices []Device
+	for _, path := range paths {
+		dev, err := d.device(path)
+		if err != nil {
+			return nil, err
+		}
+		if dev.Type == "loopback" {
+			continue
+		}
+		devices = append(devices, dev)
+	}
+	return devices, nil
+}
+
+// device lê as propriedades de um dispositivo
+func (d *DBus) device(path dbus.ObjectPath) (Device, error) {
+	props, err := d.properties(path, nmDeviceIface)
+	if err != nil {
+		return Device{}, fmt.Errorf("erro ao ler dispositivo %s: %w", path, err)
+	}
+
+	dev := Device{
+		Name:  variantString(props["Interface"]),
+		Type:  deviceTypeName(variantUint32(props["DeviceType"])),
+		State: deviceStateName(variantUint32(props["State"])),
+		MAC:   variantString(props["HwAddress"]),
+	}
+
+	if active, ok := props["ActiveConnection"].Value().(dbus.ObjectPath); ok && active != "/" {
+		if id, err := d.object(active).GetProperty(nmActiveIface + ".Id"); err == nil {
+			dev.Connection = variantString(id)
+		}
+	}
+
+	if cfg, ok := props["Ip4Config"].Value().(dbus.ObjectPath); ok && cfg != "/" {
+		ip4, err := d.properties(cfg, nmIP4ConfigIface)
+		if err == nil {
+			dev.IPv4 = addressData(ip4["AddressData"])
+			dev.Gateway = variantString(ip4["Gateway"])
+			for _, ns := range mapList(ip4["NameserverData"]) {
+				dev.DNS = append(dev.DNS, variantString(ns["address"]))
+			}
+		}
+	}
+
+	if cfg, ok := props["Ip6Config"].Value().(dbus.ObjectPath); ok && cfg != "/" {
+		ip6, err := d.properties(cfg, nmIP6ConfigIface)
+		if err == nil {
+			dev.IPv6 = addressData(ip6["AddressData"])
+			dev.Gateway6 = variantString(ip6["Gateway"])
+			if servers, ok := ip6["Nameservers"].Value().([][]byte); ok {
+				for _, raw := range servers {
+					dev.DNS = append(dev.DNS, bytesToIP(raw))
+				}
+			}
+		}
+	}
+
+	return dev, nil
+}
+
+// Profiles lista todos os perfis salvos no NetworkManager
+func (d *DBus) Profiles() ([]Profile, error) {
+	var paths []dbus.ObjectPath
+	if err := d.object(nmSettingsPath).Call(nmSettingsIface+".ListConnections", 0).Store(&paths); err != nil {
+		return nil, fmt.Errorf("erro ao listar perfis: %w", err)
+	}
+
+	active, err := d.activeDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	var profiles []Profile
+	for _, path := range paths {
+		raw, err := d.getSettings(path)
+		if err != nil {
+			return nil, err
+		}
+		p := profileFromSettings(raw)
+		p.Settings = nil
+		if dev, ok := active[p.UUID]; ok {
+			p.Active = true
+			if dev != "" {
+				p.Device = dev
+			}
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, nil
+}
+
+// Profile obtém um perfil com todas as propriedades
+func (d *DBus) Profile(id string) (Profile, error) {
+	return d.profile(id, false)
+}
+
+// ProfileWithSecrets obtém o perfil junto com os segredos (GetSecrets)
+func (d *DBus) ProfileWithSecrets(id string) (Profile, error) {
+	return d.profile(id, true)
+}
+
+// profile lê as configurações do perfil e o estado da ativação
+func (d *DBus) profile(id string, secrets bool) (Profile, error) {
+	path, err := d.findConnection(id)
+	if err != nil {
+		return Profile{}, err
+	}
+	read := d.getSettings
+	if secrets {
+		read = d.settingsWithSecrets
+	}
+	raw, err := read(path)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	p := profileFromSettings(raw)
+	active, err := d.activeDevices()
+	if err != nil {
+		return Profile{}, err
+	}
+	if dev, ok := active[p.UUID]; ok {
+		p.Active = true
+		if dev != "" {
+			p.Device = dev
+		}
+	}
+	return p, nil
+}
+
+// AddProfile cria um perfil com Settings.AddConnection
+func (d *DBus) AddProfile(p Profile) (Profile, error) {
+	settings := p.Settings.Clone()
+	settings["connection.id"] = p.Name
+	settings["connection.type"] = p.Type
+	if p.Device != "" {
+		settings["connection.interface-name"] = p.Device
+	}
+	if settings["connection.uuid"] == "" {
+		settings["connection.uuid"] = newUUID()
+	}
+
+	raw := connectionSettings{}
+	if err := raw.apply(settings); err != nil {
+		return Profile{}, err
+	}
+
+	var path dbus.ObjectPath
+	if err := d.object(nmSettingsPath).Call(nmSettingsIface+".AddConnection", 0, raw).Store(&path); err != nil {
+		return Profile{}, fmt.Errorf("erro ao criar perfil %s: %w", p.Name, err)
+	}
+	return d.Profile(settings["connection.uuid"])
+}
+
+// ModifyProfile altera propriedades do perfil com Settings.Connection.Update
+func (d *DBus) ModifyProfile(id string, settings Settings) error {
+	if len(settings) == 0 {
+		return nil
+	}
+
+	path, err := d.findConnection(id)
+	if err != nil {
+		return err
+	}
+	// Update substitui o perfil inteiro, então os segredos precisam ir junto
+	raw, err := d.settingsWithSecrets(path)
+	if err != nil {
+		return err
+	}
+
+	if err := raw.apply(settings); err != nil {
+		return err
+	}
+	if err := d.object(path).Call(nmConnectionIface+".Update", 0, raw).Err; err != nil {
+		return fmt.Errorf("erro ao modificar perfil %s: %w", id, err)
+	}
+	return nil
+}
+
+// CloneProfile copia o perfil, com os segredos, para um novo perfil criado
+// com Settings.AddConnection
+func (d *DBus) CloneProfile(id, name string) (Profile, error) {
+	path, err := d.findConnection(id)
+	if err != nil {
+		return Profile{}, err
+	}
+	raw, err := d.settingsWithSecrets(path)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	uuid := newUUID()
+	if err := raw.apply(Settings{"connection.id": name, "connection.uuid": uuid, "connection.timestamp": ""}); err != nil {
+		return Profile{}, err
+	}
+	var created dbus.ObjectPath
+	if err := d.object(nmSettingsPath).Call(nmSettingsIface+".AddConnection", 0, raw).Store(&created); err != nil {
+		return Profile{}, fmt.Errorf("erro ao copiar perfil %s: %w", id, err)
+	}
+	return d.Profile(uuid)
+}
+
+// DeleteProfile remove um perfil
+func (d *DBus) DeleteProfile(id string) error {
+	path, err := d.findConnection(id)
+	if err != nil {
+		return err
+	}
+	if err := d.object(path).Call(nmConnectionIface+".Delete", 0).Err; err != nil {
+		return fmt.Errorf("erro ao remover perfil %s: %w", id, err)
+	}
+	return nil
+}
+
+// Activate ativa um perfil, deixando o NetworkManager escolher o dispositivo
+func (d *DBus) Activate(id string) error {
+	path, err := d.findConnection(id)
+	if err != nil {
+		return err
+	}
+
+	var active dbus.ObjectPath
+	err = d.object(nmPath).Call(nmIface+".ActivateConnection", 0,
+		path, dbus.ObjectPath("/"), dbus.ObjectPath("/")).Store(&active)
+	if err != nil {
+		return fmt.Errorf("erro ao ativar perfil %s: %w", id, err)
+	}
+	return nil
+}
+
+// Deactivate desativa a conexão ativa correspondente ao perfil
+func (d *DBus) Deactivate(id string) error {
+	p, err := d.Profile(id)
+	if err != nil {
+		return err
+	}
+
+	actives, err := d.activeConnections()
+	if err != nil {
+		return err
+	}
+	for _, active := range actives {
+		uuid, err := d.object(active).GetProperty(nmActiveIface + ".Uuid")
+		if err != nil || variantString(uuid) != p.UUID {
+			continue
+		}
+		if err := d.object(nmPath).Call(nmIface+".DeactivateConnection", 0, active).Err; err != nil {
+			return fmt.Errorf("erro ao desativar perfil %s: %w", id, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("perfil %s: %w", id, ErrNotActive)
+}
+
+// Scan lista os pontos de acesso vistos pelos dispositivos Wi-Fi
+func (d *DBus) Scan() ([]AccessPoint, error) {
+	var paths []dbus.ObjectPath
+	if err := d.object(nmPath).Call(nmIface+".GetDevices", 0).Store(&paths); err != nil {
+		return nil, fmt.Errorf("erro ao obter dispositivos: %w", err)
+	}
+
+	var aps []AccessPoint
+	for _, path := range paths {
+		devType, err := d.object(path).GetProperty(nmDeviceIface + ".DeviceType")
+		if err != nil || deviceTypeName(variantUint32(devType)) != "wifi" {
+			continue
+		}
+
+		var apPaths []dbus.ObjectPath
+		if err := d.object(path).Call(nmWirelessIface+".GetAllAccessPoints", 0).Store(&apPaths); err != nil {
+			return nil, fmt.Errorf("erro na varredura Wi-Fi: %w", err)
+		}
+		for _, apPath := range apPaths {
+			props, err := d.properties(apPath, nmAccessPointIface)
+			if err != nil {
+				continue
+			}
+			ssid, _ := props["Ssid"].Value().([]byte)
+			security := apSecurity(variantUint32(props["Flags"]), variantUint32(props["WpaFlags"]), variantUint32(props["RsnFlags"]))
+			signal := int(variantUint32(props["Strength"]))
+			freq := int(variantUint32(props["Frequency"]))
+			aps = append(aps, AccessPoint{
+				SSID:      string(ssid),
+				BSSID:     variantString(props["HwAddress"]),
+				Signal:    signal,
+				DBm:       qualityToDBm(signal),
+				Frequency: freq,
+				Channel:   FrequencyChannel(freq),
+				Secured:   security != SecurityOpen,
+				Security:  security,
+			})
+		}
+	}
+	return aps, nil
+}
+
+// Rescan pede uma nova varredura (RequestScan) aos dispositivos Wi-Fi
+func (d *DBus) Rescan(device string) error {
+	var paths []dbus.ObjectPath
+	if err := d.object(nmPath).Call(nmIface+".GetDevices", 0).Store(&paths); err != nil {
+		return fmt.Errorf("erro ao obter dispositivos: %w", err)
+	}
+
+	for _, path := range paths {
+		props, err := d.properties(path, nmDeviceIface)
+		if err != nil || deviceTypeName(variantUint32(props["DeviceType"])) != "wifi" {
+			continue
+		}
+		if device != "" && variantString(props["Interface"]) != device {
+			continue
+		}
+		if err := d.object(path).Call(nmWirelessIface+".RequestScan", 0, map[string]dbus.Variant{}).Err; err != nil {
+			return fmt.Errorf("erro ao pedir varredura Wi-Fi: %w", err)
+		}
+	}
+	return nil
+}
+
+// ConnectWiFi cria o perfil da rede, com conexão automática, e o ativa no
+// dispositivo com AddAndActivateConnection
+func (d *DBus) ConnectWiFi(device, ssid string, hidden bool) (Profile, error) {
+	var devPath dbus.ObjectPath
+	if err := d.object(nmPath).Call(nmIface+".GetDeviceByIpIface", 0, device).Store(&devPath); err != nil {
+		return Profile{}, fmt.Errorf("dispositivo %s: %w", device, ErrNotFound)
+	}
+
+	settings := Settings{
+		"connection.id":          ssid,
+		"connection.type":        WiFiType,
+		"connection.uuid":        newUUID(),
+		"connection.autoconnect": "yes",
+		"802-11-wireless.ssid":   ssid,
+	}
+	if hidden {
+		settings["802-11-wireless.hidden"] = "yes"
+	}
+
+	raw := connectionSettings{}
+	if err := raw.apply(settings); err != nil {
+		return Profile{}, err
+	}
+
+	var path, active dbus.ObjectPath
+	err := d.object(nmPath).Call(nmIface+".AddAndActivateConnection", 0,
+		raw, devPath, dbus.ObjectPath("/")).Store(&path, &active)
+	if err != nil {
+		return Profile{}, fmt.Errorf("erro ao conectar em %s: %w", ssid, err)
+	}
+	return d.Profile(settings["connection.uuid"])
+}
+
+// Plan descreve as chamadas D-Bus que ModifyProfile e Activate farão
+func (d *DBus) Plan(id string, settings Settings) []string {
+	path := dbus.ObjectPath(id)
+	if found, err := d.findConnection(id); err == nil {
+		path = found
+	}
+
+	var props []string
+	masked := settings.Masked()
+	for _, key := range masked.Keys() {
+		props = append(props, fmt.Sprintf("%s=%s", key, shellQuote(masked[key])))
+	}
+	return []string{
+		fmt.Sprintf("%s.Update %s (%s)", nmConnectionIface, path, strings.Join(props, " ")),
+		fmt.Sprintf("%s.ActivateConnection %s", nmIface, path),
+	}
+}
+
+// PlanAdd descreve a chamada D-Bus que AddProfile fará
+func (d *DBus) PlanAdd(p Profile) []string {
+	props := []string{
+		"connection.id=" + shellQuote(p.Name),
+		"connection.type=" + shellQuote(p.Type),
+	}
+	if p.Device != "" {
+		props = append(props, "connection.interface-name="+shellQuote(p.Device))
+	}
+	masked := p.Settings.Masked()
+	for _, key := range masked.Keys() {
+		props = append(props, fmt.Sprintf("%s=%s", key, shellQuote(masked[key])))
+	}
+	return []string{fmt.Sprintf("%s.AddConnection (%s)", nmSettingsIface, strings.Join(props, " "))}
+}
+
+// ImportVPN importa o arquivo com "nmcli connection import": o D-Bus não
+// oferece importação, que depende dos plugins de VPN do nmcli
+func (d *DBus) ImportVPN(kind, path string) (Profile, error) {
+	uuid, err := nmcliImport(kind, path)
+	if err != nil {
+		return Profile{}, err
+	}
+	return d.Profile(uuid)
+}
+
+// WireGuardStatus lê o estado dos peers com "wg show"
+func (d *DBus) WireGuardStatus(device string) ([]PeerStatus, error) {
+	return wireGuardStatus(device)
+}
+
+// Watch acompanha os sinais emitidos pelo NetworkManager (mudanças de estado
+// dos dispositivos, de configuração IP e de conexões ativas)
+func (d *DBus) Watch(stop <-chan struct{}) (<-chan struct{}, error) {
+	match := []dbus.MatchOption{dbus.WithMatchSender(nmDest)}
+	if err := d.conn.AddMatchSignal(match...); err != nil {
+		return nil, fmt.Errorf("erro ao assinar sinais do NetworkManager: %w", err)
+	}
+
+	signals := make(chan *dbus.Signal, 16)
+	d.conn.Signal(signals)
+
+	events := make(chan struct{}, 1)
+	go func() {
+		defer close(events)
+		defer d.conn.RemoveMatchSignal(match...)
+		defer d.conn.RemoveSignal(signals)
+
+		for {
+			select {
+			case <-stop:
+				return
+			case sig, ok := <-signals:
+				if !ok {
+					return
+				}
+				if strings.HasPrefix(string(sig.Path), nmPath) {
+					notify(events)
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// object retorna o objeto remoto do NetworkManager no caminho informado
+func (d *DBus) object(path dbus.ObjectPath) dbus.BusObject {
+	return d.conn.Object(nmDest, path)
+}
+
+// properties lê todas as propriedades de uma interface
+func (d *DBus) properties(path dbus.ObjectPath, iface string) (map[string]dbus.Variant, error) {
+	var props map[string]dbus.Variant
+	err := d.object(path).Call("org.freedesktop.DBus.Properties.GetAll", 0, iface).Store(&props)
+	return props, err
+}
+
+// getSettings lê as configurações (sem segredos) de um perfil
+func (d *DBus) getSettings(path dbus.ObjectPath) (connectionSettings, error) {
+	var raw connectionSettings
+	if err := d.object(path).Call(nmConnectionIface+".GetSettings", 0).Store(&raw); err != nil {
+		return nil, fmt.Errorf("erro ao ler perfil %s: %w", path, err)
+	}
+	return raw, nil
+}
+
+// settingsWithSecrets lê as configurações do perfil junto com os segredos,
+// que GetSettings não retorna
+func (d *DBus) settingsWithSecrets(path dbus.ObjectPath) (connectionSettings, error) {
+	raw, err := d.getSettings(path)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := d.object(path)
+	for _, group := range secretSettings {
+		if _, ok := raw[group]; !ok {
+			continue
+		}
+		var secrets connectionSettings
+		if err := obj.Call(nmConnectionIface+".GetSecrets", 0, group).Store(&secrets); err == nil {
+			for key, value := range secrets[group] {
+				if group == "wireguard" && key == "peers" {
+					// Os segredos dos peers trazem só a chave pública e a compartilhada
+					raw[group][key] = mergePeerSecrets(raw[group][key], value)
+					continue
+				}
+				raw[group][key] = value
+			}
+		}
+	}
+	return raw, nil
+}
+
+// findConnection localiza o caminho D-Bus de um perfil pelo UUID ou nome
+func (d *DBus) findConnection(id string) (dbus.ObjectPath, error) {
+	var path dbus.ObjectPath
+	if err := d.object(nmSettingsPath).Call(nmSettingsIface+".GetConnectionByUuid", 0, id).Store(&path); err == nil {
+		return path, nil
+	}
+
+	var paths []dbus.ObjectPath
+	if err := d.object(nmSettingsPath).Call(nmSettingsIface+".ListConnections", 0).Store(&paths); err != nil {
+		return "", fmt.Errorf("erro ao listar perfis: %w", err)
+	}
+	for _, p := range paths {
+		raw, err := d.getSettings(p)
+		if err != nil {
+			continue
+		}
+		if variantString(raw["connection"]["id"]) == id {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("perfil %s: %w", id, ErrNotFound)
+}
+
+// activeConnections lista os caminhos das conexões ativas
+func (d *DBus) activeConnections() ([]dbus.ObjectPath, error) {
+	v, err := d.object(nmPath).GetProperty(nmIface + ".ActiveConnections")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter conexões ativas: %w", err)
+	}
+	paths, _ := v.Value().([]dbus.ObjectPath)
+	return paths, nil
+}
+
+// activeDevices relaciona o UUID de cada perfil ativo ao seu dispositivo
+func (d *DBus) activeDevices() (map[string]string, error) {
+	actives, err := d.activeConnections()
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]string{}
+	for _, active := range actives {
+		props, err := d.properties(active, nmActiveIface)
+		if err != nil {
+			continue
+		}
+
+		var names []string
+		devices, _ := props["Devices"].Value().([]dbus.ObjectPath)
+		for _, dev := range devices {
+			if v, err := d.object(dev).GetProperty(nmDeviceIface + ".Interface"); err == nil {
+				names = append(names, variantString(v))
+			}
+		}
+		result[variantString(props["Uuid"])] = strings.Join(names, ",")
+	}
+	return result, nil
+}
+
+// deviceTypeName converte NMDeviceType no nome usado pelo nmcli
+func deviceTypeName(t uint32) string {
+	switch t {
+	case 1:
+		return "ethernet"
+	case 2:
+		return "wifi"
+	case 5:
+		return "bt"
+	case 8:
+		return "gsm"
+	case 10:
+		return "bond"
+	case 11:
+		return "vlan"
+	case 13:
+		return "bridge"
+	case 14:
+		return "generic"
+	case 15:
+		return "team"
+	case 16:
+		return "tun"
+	case 17:
+		return "ip-tunnel"
+	case 18:
+		return "macvlan"
+	case 19:
+		return "vxlan"
+	case 20:
+		return "veth"
+	case 22:
+		return "dummy"
+	case 29:
+		return "wireguard"
+	case 32:
+		return "loopback"
+	}
+	return "unknown"
+}
+
+// deviceStateName converte NMDeviceState no estado usado pelo nmcli
+func deviceStateName(s uint32) string {
+	switch {
+	case s == 10:
+		return "unmanaged"
+	case s == 20:
+		return "unavailable"
+	case s == 30:
+		return "disconnected"
+	case s >= 40 && s < 100:
+		return "connecting"
+	case s == 100:
+		return "connected"
+	case s == 110:
+		return "deactivating"
+	case s == 120:
+		return "failed"
+	}
+	return "unknown"
+}
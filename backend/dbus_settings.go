@@ -0,0 +1,684 @@
+package backend
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// connectionSettings é o formato a{sa{sv}} usado pelo NetworkManager
+type connectionSettings map[string]map[string]dbus.Variant
+
+// Apelidos de grupos e tipos aceitos pelo nmcli
+var settingAliases = map[string]string{
+	"ethernet": "802-3-ethernet",
+	"wifi":     "802-11-wireless",
+	"wifi-sec": "802-11-wireless-security",
+}
+
+// Tipos das propriedades mais comuns, usados quando o perfil ainda não tem a
+// propriedade e não há de onde copiar a assinatura
+var knownSignatures = map[string]string{
+	"connection.autoconnect":          "b",
+	"connection.autoconnect-priority": "i",
+	"ipv4.dns-search":                 "as",
+	"ipv4.never-default":              "b",
+	"ipv4.ignore-auto-dns":            "b",
+	"ipv4.may-fail":                   "b",
+	"ipv4.route-metric":               "x",
+	"ipv4.route-table":                "u",
+	"ipv6.dns-search":                 "as",
+	"ipv6.never-default":              "b",
+	"ipv6.ignore-auto-dns":            "b",
+	"ipv6.may-fail":                   "b",
+	"ipv6.route-metric":               "x",
+	"ipv6.route-table":                "u",
+	"bond.options":                    "a{ss}",
+	"bridge.stp":                      "b",
+	"vlan.id":                         "u",
+	"wireguard.listen-port":           "u",
+	"wireguard.mtu":                   "u",
+	"802-1x.eap":                      "as",
+	"802-11-wireless.hidden":          "b",
+	"802-11-wireless.ssid":            "ay",
+	"802-3-ethernet.mtu":              "u",
+}
+
+// Propriedades 802-1x.* com certificados ou chaves, que o NetworkManager
+// guarda como bytes no esquema "file://caminho\x00"
+var certificateKeys = map[string]bool{
+	"ca-cert": true, "client-cert": true, "private-key": true,
+	"phase2-ca-cert": true, "phase2-client-cert": true, "phase2-private-key": true,
+}
+
+// Prefixo do esquema de caminho dos certificados 802.1X
+const certificateScheme = "file://"
+
+// profileFromSettings converte as configurações D-Bus em Profile
+func profileFromSettings(raw connectionSettings) Profile {
+	settings := Settings{}
+	for group, props := range raw {
+		for key, value := range props {
+			flatKey, flat, ok := flattenProperty(group, key, value)
+			if ok {
+				settings[flatKey] = flat
+			}
+		}
+	}
+
+	return Profile{
+		Name:     settings["connection.id"],
+		UUID:     settings["connection.uuid"],
+		Type:     settings["connection.type"],
+		Device:   settings["connection.interface-name"],
+		Settings: settings,
+	}
+}
+
+// flattenProperty converte uma propriedade D-Bus no par chave/valor do nmcli
+func flattenProperty(group, key string, value dbus.Variant) (string, string, bool) {
+	isIP := group == "ipv4" || group == "ipv6"
+
+	switch {
+	case isIP && key == "address-data":
+		return group + ".addresses", strings.Join(addressData(value), ","), true
+	case isIP && key == "route-data":
+		var routes []string
+		for _, r := range mapList(value) {
+			route := fmt.Sprintf("%s/%d", variantString(r["dest"]), variantUint32(r["prefix"]))
+			if hop := variantString(r["next-hop"]); hop != "" {
+				route += " " + hop
+			}
+			if m, ok := r["metric"]; ok {
+				route += " " + strconv.FormatUint(uint64(variantUint32(m)), 10)
+			}
+			if t, ok := r["table"]; ok {
+				route += " table=" + strconv.FormatUint(uint64(variantUint32(t)), 10)
+			}
+			route += routeAttributeText(r)
+			routes = append(routes, route)
+		}
+		return group + ".routes", strings.Join(routes, ","), true
+	case isIP && key == "routing-rules":
+		var rules []string
+		for _, r := range mapList(value) {
+			rules = append(rules, formatRoutingRule(r))
+		}
+		return group + ".routing-rules", strings.Join(rules, ","), true
+	case isIP && (key == "addresses" || key == "routes"):
+		// Formatos legados, substituídos por address-data e route-data
+		return "", "", false
+	case group == "802-1x" && certificateKeys[key]:
+		raw, _ := value.Value().([]byte)
+		path := strings.TrimSuffix(string(raw), "\x00")
+		if !strings.HasPrefix(path, certificateScheme) {
+			// Certificado embutido no perfil, sem caminho a exibir
+			return "", "", false
+		}
+		return group + "." + key, strings.TrimPrefix(path, certificateScheme), true
+	case group == "wireguard" && key == "peers":
+		var peers []WireGuardPeer
+		for _, m := range mapList(value) {
+			peers = append(peers, peerFromVariant(m))
+		}
+		return "wireguard.peers", FormatWireGuardPeers(peers), true
+	case group == "ipv4" && key == "dns":
+		var servers []string
+		if list, ok := value.Value().([]uint32); ok {
+			for _, n := range list {
+				b := make([]byte, 4)
+				binary.LittleEndian.PutUint32(b, n)
+				servers = append(servers, net.IP(b).String())
+			}
+		}
+		return "ipv4.dns", strings.Join(servers, ","), true
+	case group == "ipv6" && key == "dns":
+		var servers []string
+		if list, ok := value.Value().([][]byte); ok {
+			for _, raw := range list {
+				servers = append(servers, bytesToIP(raw))
+			}
+		}
+		return "ipv6.dns", strings.Join(servers, ","), true
+	}
+
+	switch v := value.Value().(type) {
+	case string:
+		return group + "." + key, v, true
+	case bool:
+		if v {
+			return group + "." + key, "yes", true
+		}
+		return group + "." + key, "no", true
+	case int32, uint32, int64, uint64, byte:
+		return group + "." + key, fmt.Sprint(v), true
+	case []string:
+		return group + "." + key, strings.Join(v, ","), true
+	case []byte:
+		return group + "." + key, string(v), true
+	case map[string]string:
+		// Opções no formato do nmcli: "mode=active-backup,miimon=100"
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		options := make([]string, len(keys))
+		for i, k := range keys {
+			options[i] = k + "=" + v[k]
+		}
+		return group + "." + key, strings.Join(options, ","), true
+	}
+	return "", "", false
+}
+
+// apply aplica propriedades no formato do nmcli sobre as configurações D-Bus.
+// Valores vazios removem a propriedade, voltando ao padrão do NetworkManager.
+func (raw connectionSettings) apply(settings Settings) error {
+	for _, flatKey := range settings.Keys() {
+		value := settings[flatKey]
+
+		parts := strings.SplitN(flatKey, ".", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("propriedade inválida: %s", flatKey)
+		}
+		group, key := parts[0], parts[1]
+		if alias, ok := settingAliases[group]; ok {
+			group = alias
+		}
+		if group == "connection" && key == "type" {
+			if alias, ok := settingAliases[value]; ok {
+				value = alias
+			}
+		}
+		if raw[group] == nil {
+			raw[group] = map[string]dbus.Variant{}
+		}
+
+		if err := raw.setProperty(group, key, value); err != nil {
+			return fmt.Errorf("propriedade %s: %w", flatKey, err)
+		}
+	}
+	return nil
+}
+
+// setProperty converte e grava uma única propriedade
+func (raw connectionSettings) setProperty(group, key, value string) error {
+	props := raw[group]
+	isIP := group == "ipv4" || group == "ipv6"
+	items := splitList(value)
+
+	switch {
+	case isIP && key == "addresses":
+		delete(props, "addresses")
+		if len(items) == 0 {
+			delete(props, "address-data")
+			return nil
+		}
+		var data []map[string]dbus.Variant
+		for _, item := range items {
+			ip, ipNet, err := net.ParseCIDR(item)
+			if err != nil {
+				return err
+			}
+			prefix, _ := ipNet.Mask.Size()
+			data = append(data, map[string]dbus.Variant{
+				"address": dbus.MakeVariant(ip.String()),
+				"prefix":  dbus.MakeVariant(uint32(prefix)),
+			})
+		}
+		props["address-data"] = dbus.MakeVariant(data)
+		return nil
+
+	case isIP && key == "routes":
+		delete(props, "routes")
+		if len(items) == 0 {
+			delete(props, "route-data")
+			return nil
+		}
+		var data []map[string]dbus.Variant
+		for _, item := range items {
+			route, err := parseRoute(item)
+			if err != nil {
+				return err
+			}
+			data = append(data, route)
+		}
+		props["route-data"] = dbus.MakeVariant(data)
+		return nil
+
+	case isIP && key == "routing-rules":
+		if len(items) == 0 {
+			delete(props, "routing-rules")
+			return nil
+		}
+		family := int32(syscall.AF_INET)
+		if group == "ipv6" {
+			family = syscall.AF_INET6
+		}
+		var data []map[string]dbus.Variant
+		for _, item := range items {
+			rule, err := parseRoutingRule(family, item)
+			if err != nil {
+				return err
+			}
+			data = append(data, rule)
+		}
+		props["routing-rules"] = dbus.MakeVariant(data)
+		return nil
+
+	case group == "802-1x" && certificateKeys[key]:
+		if value == "" {
+			delete(props, key)
+			return nil
+		}
+		props[key] = dbus.MakeVariant([]byte(certificateScheme + value + "\x00"))
+		return nil
+
+	case group == "wireguard" && key == "peers":
+		peers, err := ParseWireGuardPeers(value)
+		if err != nil {
+			return err
+		}
+		// Mantém a chave compartilhada dos peers que não a informam
+		secrets := map[string]dbus.Variant{}
+		if current, ok := props["peers"]; ok {
+			for _, m := range mapList(current) {
+				if psk, ok := m["preshared-key"]; ok {
+					secrets[variantString(m["public-key"])] = psk
+				}
+			}
+		}
+		data := []map[string]dbus.Variant{}
+		for _, peer := range peers {
+			m := peerToVariant(peer)
+			if psk, ok := secrets[peer.PublicKey]; ok && peer.PresharedKey == "" {
+				m["preshared-key"] = psk
+			}
+			data = append(data, m)
+		}
+		props["peers"] = dbus.MakeVariant(data)
+		return nil
+
+	case group == "ipv4" && key == "dns":
+		var list []uint32
+		for _, item := range items {
+			ip := net.ParseIP(item).To4()
+			if ip == nil {
+				return fmt.Errorf("endereço IPv4 inválido: %s", item)
+			}
+			list = append(list, binary.LittleEndian.Uint32(ip))
+		}
+		props["dns"] = dbus.MakeVariant(list)
+		return nil
+
+	case group == "ipv6" && key == "dns":
+		var list [][]byte
+		for _, item := range items {
+			ip := net.ParseIP(item)
+			if ip == nil || ip.To4() != nil {
+				return fmt.Errorf("endereço IPv6 inválido: %s", item)
+			}
+			list = append(list, []byte(ip.To16()))
+		}
+		props["dns"] = dbus.MakeVariant(list)
+		return nil
+	}
+
+	if value == "" {
+		delete(props, key)
+		return nil
+	}
+
+	// Usa a assinatura já existente no perfil ou a conhecida para a chave
+	signature := knownSignatures[group+"."+key]
+	if current, ok := props[key]; ok {
+		signature = current.Signature().String()
+	}
+
+	v, err := parseVariant(signature, value)
+	if err != nil {
+		return err
+	}
+	props[key] = v
+	return nil
+}
+
+// parseVariant converte um valor textual na assinatura D-Bus informada
+func parseVariant(signature, value string) (dbus.Variant, error) {
+	switch signature {
+	case "b":
+		switch strings.ToLower(value) {
+		case "yes", "true", "on", "1":
+			return dbus.MakeVariant(true), nil
+		case "no", "false", "off", "0":
+			return dbus.MakeVariant(false), nil
+		}
+		return dbus.Variant{}, fmt.Errorf("valor booleano inválido: %s", value)
+	case "i":
+		n, err := strconv.ParseInt(value, 10, 32)
+		return dbus.MakeVariant(int32(n)), err
+	case "u":
+		n, err := strconv.ParseUint(value, 10, 32)
+		return dbus.MakeVariant(uint32(n)), err
+	case "x":
+		n, err := strconv.ParseInt(value, 10, 64)
+		return dbus.MakeVariant(n), err
+	case "t":
+		n, err := strconv.ParseUint(value, 10, 64)
+		return dbus.MakeVariant(n), err
+	case "as":
+		return dbus.MakeVariant(splitList(value)), nil
+	case "ay":
+		return dbus.MakeVariant([]byte(value)), nil
+	case "a{ss}":
+		options := map[string]string{}
+		for _, item := range splitList(value) {
+			k, v, ok := strings.Cut(item, "=")
+			if !ok {
+				return dbus.Variant{}, fmt.Errorf("opção inválida: %s", item)
+			}
+			options[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+		return dbus.MakeVariant(options), nil
+	}
+	return dbus.MakeVariant(value), nil
+}
+
+// routeAttributeText descreve os demais atributos da rota (src, onlink,
+// mtu...) no formato do nmcli, em ordem alfabética
+func routeAttributeText(r map[string]dbus.Variant) string {
+	var names []string
+	for name := range r {
+		if _, ok := routeAttributes[name]; ok && name != "table" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, " %s=%v", name, r[name].Value())
+	}
+	return b.String()
+}
+
+// parseRoute interpreta uma rota no formato do nmcli:
+// "destino/prefixo [próximo-salto] [métrica] [atributo=valor...]", com os
+// atributos de RouteAttribute
+func parseRoute(s string) (map[string]dbus.Variant, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("rota vazia")
+	}
+
+	// Sem prefixo, a rota é para um único host
+	dest := fields[0]
+	if !strings.Contains(dest, "/") {
+		if strings.Contains(dest, ":") {
+			dest += "/128"
+		} else {
+			dest += "/32"
+		}
+	}
+
+	_, ipNet, err := net.ParseCIDR(dest)
+	if err != nil {
+		return nil, fmt.Errorf("rota inválida: %s", s)
+	}
+	prefix, _ := ipNet.Mask.Size()
+	route := map[string]dbus.Variant{
+		"dest":   dbus.MakeVariant(ipNet.IP.String()),
+		"prefix": dbus.MakeVariant(uint32(prefix)),
+	}
+
+	for _, field := range fields[1:] {
+		name, value, isAttr := strings.Cut(field, "=")
+		switch {
+		case isAttr:
+			v, err := RouteAttribute(name, value)
+			if err != nil {
+				return nil, fmt.Errorf("rota %s: %w", s, err)
+			}
+			route[name] = dbus.MakeVariant(v)
+		case net.ParseIP(field) != nil:
+			route["next-hop"] = dbus.MakeVariant(field)
+		default:
+			n, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("métrica inválida na rota: %s", s)
+			}
+			route["metric"] = dbus.MakeVariant(uint32(n))
+		}
+	}
+	return route, nil
+}
+
+// parseRoutingRule interpreta uma regra de roteamento no formato do nmcli:
+// "priority N [not] [from X/len] [to Y/len] [iif nome] [oif nome]
+// [fwmark M[/máscara]] table T"
+func parseRoutingRule(family int32, s string) (map[string]dbus.Variant, error) {
+	rule := map[string]dbus.Variant{"family": dbus.MakeVariant(family)}
+	fields := strings.Fields(s)
+
+	for i := 0; i < len(fields); i++ {
+		keyword := fields[i]
+		if keyword == "not" {
+			rule["invert"] = dbus.MakeVariant(true)
+			continue
+		}
+		if i+1 >= len(fields) {
+			return nil, fmt.Errorf("regra inválida, falta o valor de %s: %s", keyword, s)
+		}
+		i++
+		value := fields[i]
+
+		switch keyword {
+		case "priority", "table":
+			n, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("%s inválido na regra: %s", keyword, s)
+			}
+			rule[keyword] = dbus.MakeVariant(uint32(n))
+		case "from", "to":
+			addr, length, err := parseRulePrefix(value)
+			if err != nil {
+				return nil, fmt.Errorf("endereço inválido na regra: %s", s)
+			}
+			rule[keyword] = dbus.MakeVariant(addr)
+			rule[keyword+"-len"] = dbus.MakeVariant(length)
+		case "iif", "oif":
+			rule[keyword+"name"] = dbus.MakeVariant(value)
+		case "fwmark":
+			mark, mask, hasMask := strings.Cut(value, "/")
+			n, err := strconv.ParseUint(mark, 0, 32)
+			if err != nil {
+				return nil, fmt.Errorf("fwmark inválido na regra: %s", s)
+			}
+			rule["fwmark"] = dbus.MakeVariant(uint32(n))
+			if hasMask {
+				m, err := strconv.ParseUint(mask, 0, 32)
+				if err != nil {
+					return nil, fmt.Errorf("máscara do fwmark inválida na regra: %s", s)
+				}
+				rule["fwmask"] = dbus.MakeVariant(uint32(m))
+			}
+		default:
+			return nil, fmt.Errorf("atributo de regra não suportado: %s", keyword)
+		}
+	}
+
+	if _, ok := rule["priority"]; !ok {
+		return nil, fmt.Errorf("regra sem prioridade: %s", s)
+	}
+	return rule, nil
+}
+
+// parseRulePrefix separa endereço e tamanho do prefixo; sem prefixo, a regra
+// vale para um único endereço
+func parseRulePrefix(value string) (string, byte, error) {
+	addr, length, hasLength := strings.Cut(value, "/")
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return "", 0, fmt.Errorf("endereço inválido: %s", value)
+	}
+	bits := 128
+	if ip.To4() != nil {
+		bits = 32
+	}
+	if !hasLength {
+		return ip.String(), byte(bits), nil
+	}
+	n, err := strconv.Atoi(length)
+	if err != nil || n < 0 || n > bits {
+		return "", 0, fmt.Errorf("prefixo inválido: %s", value)
+	}
+	return ip.String(), byte(n), nil
+}
+
+// formatRoutingRule converte uma regra D-Bus no formato do nmcli
+func formatRoutingRule(r map[string]dbus.Variant) string {
+	parts := []string{"priority", strconv.FormatUint(uint64(variantUint32(r["priority"])), 10)}
+	if invert, _ := r["invert"].Value().(bool); invert {
+		parts = append(parts, "not")
+	}
+	for _, keyword := range []string{"from", "to"} {
+		if addr := variantString(r[keyword]); addr != "" {
+			parts = append(parts, keyword, fmt.Sprintf("%s/%d", addr, variantUint32(r[keyword+"-len"])))
+		}
+	}
+	for _, keyword := range []string{"iif", "oif"} {
+		if name := variantString(r[keyword+"name"]); name != "" {
+			parts = append(parts, keyword, name)
+		}
+	}
+	if _, ok := r["fwmark"]; ok {
+		mark := fmt.Sprintf("0x%x", variantUint32(r["fwmark"]))
+		if _, ok := r["fwmask"]; ok {
+			mark += fmt.Sprintf("/0x%x", variantUint32(r["fwmask"]))
+		}
+		parts = append(parts, "fwmark", mark)
+	}
+	if _, ok := r["table"]; ok {
+		parts = append(parts, "table", strconv.FormatUint(uint64(variantUint32(r["table"])), 10))
+	}
+	return strings.Join(parts, " ")
+}
+
+// addressData converte AddressData (aa{sv}) em endereços CIDR
+func addressData(v dbus.Variant) []string {
+	var addrs []string
+	for _, a := range mapList(v) {
+		addrs = append(addrs, fmt.Sprintf("%s/%d", variantString(a["address"]), variantUint32(a["prefix"])))
+	}
+	return addrs
+}
+
+// mapList converte um variant aa{sv} em lista de mapas
+func mapList(v dbus.Variant) []map[string]dbus.Variant {
+	list, _ := v.Value().([]map[string]dbus.Variant)
+	return list
+}
+
+// variantString extrai uma string de um variant
+func variantString(v dbus.Variant) string {
+	switch s := v.Value().(type) {
+	case string:
+		return s
+	case dbus.ObjectPath:
+		return string(s)
+	}
+	return ""
+}
+
+// variantUint32 extrai um inteiro sem sinal de um variant
+func variantUint32(v dbus.Variant) uint32 {
+	switch n := v.Value().(type) {
+	case uint32:
+		return n
+	case int32:
+		return uint32(n)
+	case byte:
+		return uint32(n)
+	case uint64:
+		return uint32(n)
+	case int64:
+		return uint32(n)
+	}
+	return 0
+}
+
+// bytesToIP formata um endereço recebido como array de bytes
+func bytesToIP(raw []byte) string {
+	if len(raw) != 4 && len(raw) != 16 {
+		return ""
+	}
+	return net.IP(raw).String()
+}
+
+// peerFromVariant converte um peer WireGuard do D-Bus
+func peerFromVariant(m map[string]dbus.Variant) WireGuardPeer {
+	peer := WireGuardPeer{
+		PublicKey: variantString(m["public-key"]),
+		Endpoint:  variantString(m["endpoint"]),
+	}
+	if ips, ok := m["allowed-ips"].Value().([]string); ok {
+		peer.AllowedIPs = ips
+	}
+	if _, ok := m["persistent-keepalive"]; ok {
+		peer.PersistentKeepalive = int(variantUint32(m["persistent-keepalive"]))
+	}
+	if _, ok := m["preshared-key"]; ok {
+		peer.PresharedKey = variantString(m["preshared-key"])
+	}
+	return peer
+}
+
+// peerToVariant converte um peer WireGuard para o D-Bus
+func peerToVariant(peer WireGuardPeer) map[string]dbus.Variant {
+	m := map[string]dbus.Variant{
+		"public-key":  dbus.MakeVariant(peer.PublicKey),
+		"allowed-ips": dbus.MakeVariant(nonNilStrings(peer.AllowedIPs)),
+	}
+	if peer.Endpoint != "" {
+		m["endpoint"] = dbus.MakeVariant(peer.Endpoint)
+	}
+	if peer.PersistentKeepalive > 0 {
+		m["persistent-keepalive"] = dbus.MakeVariant(uint32(peer.PersistentKeepalive))
+	}
+	if peer.PresharedKey != "" {
+		m["preshared-key"] = dbus.MakeVariant(peer.PresharedKey)
+	}
+	return m
+}
+
+// mergePeerSecrets acrescenta aos peers as chaves compartilhadas lidas com
+// GetSecrets, que retorna apenas a chave pública e o segredo de cada peer
+func mergePeerSecrets(peers, secrets dbus.Variant) dbus.Variant {
+	psk := map[string]dbus.Variant{}
+	for _, m := range mapList(secrets) {
+		if key, ok := m["preshared-key"]; ok {
+			psk[variantString(m["public-key"])] = key
+		}
+	}
+	merged := mapList(peers)
+	for _, m := range merged {
+		if key, ok := psk[variantString(m["public-key"])]; ok {
+			m["preshared-key"] = key
+		}
+	}
+	return dbus.MakeVariant(merged)
+}
+
+// nonNilStrings evita enviar uma lista nula, que o D-Bus não aceita
+func nonNilStrings(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
@@ -0,0 +1,622 @@
+package backend
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/prop"
+)
+
+// Configuração mínima de um barramento privado para os testes
+const testBusConfig = `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-BUS Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:path=%s</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`
+
+// startTestBus inicia um dbus-daemon privado e retorna o endereço
+func startTestBus(t *testing.T) string {
+	t.Helper()
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon não encontrado")
+	}
+
+	dir := t.TempDir()
+	config := filepath.Join(dir, "bus.conf")
+	if err := os.WriteFile(config, []byte(fmt.Sprintf(testBusConfig, filepath.Join(dir, "bus"))), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(daemon, "--config-file="+config, "--nofork", "--print-address=1")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("dbus-daemon não iniciou: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("endereço do barramento: %v", err)
+	}
+	return strings.TrimSpace(address)
+}
+
+// Segredos que GetSettings omite e GetSecrets retorna, por grupo
+var fakeSecretKeys = map[string]map[string]bool{
+	"802-11-wireless-security": {"psk": true},
+	"802-1x":                   {"password": true, "private-key-password": true},
+}
+
+// fakeNM é um serviço NetworkManager mínimo: dispositivos, perfis
+// (Settings), ativação e varredura Wi-Fi
+type fakeNM struct {
+	t    *testing.T
+	conn *dbus.Conn
+
+	mu          sync.Mutex
+	root        *prop.Properties
+	devices     map[string]*prop.Properties // Propriedades por interface
+	devicePaths map[string]dbus.ObjectPath
+	connections map[dbus.ObjectPath]connectionSettings
+	active      map[dbus.ObjectPath]dbus.ObjectPath // Conexão ativa → perfil
+	next        int
+	scans       []string
+}
+
+// newFakeNM publica o serviço no barramento e retorna o backend conectado a ele
+func newFakeNM(t *testing.T) (*fakeNM, *DBus) {
+	address := startTestBus(t)
+
+	conn, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("conexão do serviço: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	nm := &fakeNM{
+		t:           t,
+		conn:        conn,
+		devices:     map[string]*prop.Properties{},
+		devicePaths: map[string]dbus.ObjectPath{},
+		connections: map[dbus.ObjectPath]connectionSettings{},
+		active:      map[dbus.ObjectPath]dbus.ObjectPath{},
+	}
+	nm.export()
+
+	reply, err := conn.RequestName(nmDest, dbus.NameFlagDoNotQueue)
+	if err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("nome %s: %v (%v)", nmDest, reply, err)
+	}
+
+	client, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("conexão do cliente: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return nm, NewDBusWithConn(client)
+}
+
+func (nm *fakeNM) export() {
+	must := func(err error) {
+		if err != nil {
+			nm.t.Fatal(err)
+		}
+	}
+	must(nm.conn.Export(fakeRoot{nm}, nmPath, nmIface))
+	must(nm.conn.Export(fakeSettings{nm}, nmSettingsPath, nmSettingsIface))
+
+	var err error
+	nm.root, err = prop.Export(nm.conn, nmPath, prop.Map{
+		nmIface: {"ActiveConnections": {Value: []dbus.ObjectPath{}}},
+	})
+	must(err)
+
+	ip4 := dbus.ObjectPath("/org/freedesktop/NetworkManager/IP4Config/1")
+	_, err = prop.Export(nm.conn, ip4, prop.Map{
+		nmIP4ConfigIface: {
+			"AddressData": {Value: []map[string]dbus.Variant{{
+				"address": dbus.MakeVariant("192.168.1.10"),
+				"prefix":  dbus.MakeVariant(uint32(24)),
+			}}},
+			"Gateway": {Value: "192.168.1.1"},
+			"NameserverData": {Value: []map[string]dbus.Variant{{
+				"address": dbus.MakeVariant("1.1.1.1"),
+			}}},
+		},
+	})
+	must(err)
+
+	nm.addDevice(1, "lo", 32, ip4)
+	nm.addDevice(2, "eth0", 1, ip4)
+	nm.addDevice(3, "wlan0", 2, "/")
+	must(nm.conn.Export(fakeWireless{nm, "wlan0"}, nm.devicePaths["wlan0"], nmWirelessIface))
+
+	ap := dbus.ObjectPath("/org/freedesktop/NetworkManager/AccessPoint/1")
+	_, err = prop.Export(nm.conn, ap, prop.Map{
+		nmAccessPointIface: {
+			"Ssid":      {Value: []byte("Casa")},
+			"HwAddress": {Value: "AA:BB:CC:DD:EE:FF"},
+			"Flags":     {Value: uint32(1)},
+			"WpaFlags":  {Value: uint32(0)},
+			"RsnFlags":  {Value: uint32(0x100)},
+			"Strength":  {Value: byte(70)},
+			"Frequency": {Value: uint32(5180)},
+		},
+	})
+	must(err)
+
+	nm.addConnection(connectionSettings{
+		"connection": {
+			"id":             dbus.MakeVariant("Cabeada"),
+			"uuid":           dbus.MakeVariant("11111111-1111-1111-1111-111111111111"),
+			"type":           dbus.MakeVariant("802-3-ethernet"),
+			"interface-name": dbus.MakeVariant("eth0"),
+		},
+		"ipv4": {"method": dbus.MakeVariant("auto")},
+	})
+	nm.addConnection(connectionSettings{
+		"connection": {
+			"id":   dbus.MakeVariant("Casa"),
+			"uuid": dbus.MakeVariant("22222222-2222-2222-2222-222222222222"),
+			"type": dbus.MakeVariant("802-11-wireless"),
+		},
+		"802-11-wireless":          {"ssid": dbus.MakeVariant([]byte("Casa"))},
+		"802-11-wireless-security": {"key-mgmt": dbus.MakeVariant("wpa-psk"), "psk": dbus.MakeVariant("segredo123")},
+		"ipv4":                     {"method": dbus.MakeVariant("auto")},
+	})
+	nm.activate("/org/freedesktop/NetworkManager/Settings/1", "eth0")
+}
+
+// addDevice publica um dispositivo com as propriedades lidas pelo backend
+func (nm *fakeNM) addDevice(n int, name string, deviceType uint32, ip4 dbus.ObjectPath) {
+	path := dbus.ObjectPath(fmt.Sprintf("/org/freedesktop/NetworkManager/Devices/%d", n))
+	props, err := prop.Export(nm.conn, path, prop.Map{
+		nmDeviceIface: {
+			"Interface":        {Value: name},
+			"DeviceType":       {Value: deviceType},
+			"State":            {Value: uint32(30)},
+			"HwAddress":        {Value: fmt.Sprintf("00:11:22:33:44:%02d", n)},
+			"ActiveConnection": {Value: dbus.ObjectPath("/")},
+			"Ip4Config":        {Value: ip4},
+			"Ip6Config":        {Value: dbus.ObjectPath("/")},
+		},
+	})
+	if err != nil {
+		nm.t.Fatal(err)
+	}
+	nm.devices[name] = props
+	nm.devicePaths[name] = path
+}
+
+// addConnection publica um perfil salvo
+func (nm *fakeNM) addConnection(settings connectionSettings) dbus.ObjectPath {
+	nm.next++
+	path := dbus.ObjectPath(fmt.Sprintf("/org/freedesktop/NetworkManager/Settings/%d", nm.next))
+	nm.connections[path] = settings
+	if err := nm.conn.Export(fakeConnection{nm, path}, path, nmConnectionIface); err != nil {
+		nm.t.Fatal(err)
+	}
+	return path
+}
+
+// activate publica a conexão ativa do perfil no dispositivo
+func (nm *fakeNM) activate(profile dbus.ObjectPath, device string) dbus.ObjectPath {
+	nm.next++
+	path := dbus.ObjectPath(fmt.Sprintf("/org/freedesktop/NetworkManager/ActiveConnection/%d", nm.next))
+	settings := nm.connections[profile]
+	_, err := prop.Export(nm.conn, path, prop.Map{
+		nmActiveIface: {
+			"Id":      {Value: settings["connection"]["id"].Value()},
+			"Uuid":    {Value: settings["connection"]["uuid"].Value()},
+			"Devices": {Value: []dbus.ObjectPath{nm.devicePaths[device]}},
+		},
+	})
+	if err != nil {
+		nm.t.Fatal(err)
+	}
+	nm.active[path] = profile
+	nm.devices[device].SetMust(nmDeviceIface, "ActiveConnection", path)
+	nm.devices[device].SetMust(nmDeviceIface, "State", uint32(100))
+	nm.root.SetMust(nmIface, "ActiveConnections", nm.activePaths())
+	return path
+}
+
+func (nm *fakeNM) activePaths() []dbus.ObjectPath {
+	paths := []dbus.ObjectPath{}
+	for path := range nm.active {
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// stored retorna as configurações gravadas no serviço, com os segredos
+func (nm *fakeNM) stored(uuid string) connectionSettings {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	for _, settings := range nm.connections {
+		if variantString(settings["connection"]["uuid"]) == uuid {
+			return settings
+		}
+	}
+	return nil
+}
+
+func notFound(what string) *dbus.Error {
+	return dbus.NewError("org.freedesktop.NetworkManager.Settings.InvalidConnection", []interface{}{what + " não existe"})
+}
+
+// fakeRoot implementa org.freedesktop.NetworkManager
+type fakeRoot struct{ nm *fakeNM }
+
+func (r fakeRoot) GetDevices() ([]dbus.ObjectPath, *dbus.Error) {
+	var paths []dbus.ObjectPath
+	for _, name := range []string{"lo", "eth0", "wlan0"} {
+		paths = append(paths, r.nm.devicePaths[name])
+	}
+	return paths, nil
+}
+
+func (r fakeRoot) GetDeviceByIpIface(name string) (dbus.ObjectPath, *dbus.Error) {
+	if path, ok := r.nm.devicePaths[name]; ok {
+		return path, nil
+	}
+	return "/", notFound(name)
+}
+
+func (r fakeRoot) ActivateConnection(profile, device, specific dbus.ObjectPath) (dbus.ObjectPath, *dbus.Error) {
+	r.nm.mu.Lock()
+	defer r.nm.mu.Unlock()
+	settings, ok := r.nm.connections[profile]
+	if !ok {
+		return "/", notFound(string(profile))
+	}
+	name := variantString(settings["connection"]["interface-name"])
+	if variantString(settings["connection"]["type"]) == "802-11-wireless" {
+		name = "wlan0"
+	}
+	return r.nm.activate(profile, name), nil
+}
+
+func (r fakeRoot) DeactivateConnection(active dbus.ObjectPath) *dbus.Error {
+	r.nm.mu.Lock()
+	defer r.nm.mu.Unlock()
+	if _, ok := r.nm.active[active]; !ok {
+		return notFound(string(active))
+	}
+	delete(r.nm.active, active)
+	for _, props := range r.nm.devices {
+		if current, _ := props.GetMust(nmDeviceIface, "ActiveConnection").(dbus.ObjectPath); current == active {
+			props.SetMust(nmDeviceIface, "ActiveConnection", dbus.ObjectPath("/"))
+			props.SetMust(nmDeviceIface, "State", uint32(30))
+		}
+	}
+	r.nm.root.SetMust(nmIface, "ActiveConnections", r.nm.activePaths())
+	return nil
+}
+
+// fakeSettings implementa org.freedesktop.NetworkManager.Settings
+type fakeSettings struct{ nm *fakeNM }
+
+func (s fakeSettings) ListConnections() ([]dbus.ObjectPath, *dbus.Error) {
+	s.nm.mu.Lock()
+	defer s.nm.mu.Unlock()
+	var paths []dbus.ObjectPath
+	for i := 1; i <= s.nm.next; i++ {
+		path := dbus.ObjectPath(fmt.Sprintf("/org/freedesktop/NetworkManager/Settings/%d", i))
+		if _, ok := s.nm.connections[path]; ok {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+func (s fakeSettings) GetConnectionByUuid(uuid string) (dbus.ObjectPath, *dbus.Error) {
+	s.nm.mu.Lock()
+	defer s.nm.mu.Unlock()
+	for path, settings := range s.nm.connections {
+		if variantString(settings["connection"]["uuid"]) == uuid {
+			return path, nil
+		}
+	}
+	return "/", notFound(uuid)
+}
+
+func (s fakeSettings) AddConnection(settings map[string]map[string]dbus.Variant) (dbus.ObjectPath, *dbus.Error) {
+	s.nm.mu.Lock()
+	defer s.nm.mu.Unlock()
+	return s.nm.addConnection(settings), nil
+}
+
+// fakeConnection implementa org.freedesktop.NetworkManager.Settings.Connection
+type fakeConnection struct {
+	nm   *fakeNM
+	path dbus.ObjectPath
+}
+
+func (c fakeConnection) GetSettings() (map[string]map[string]dbus.Variant, *dbus.Error) {
+	c.nm.mu.Lock()
+	defer c.nm.mu.Unlock()
+	result := map[string]map[string]dbus.Variant{}
+	for group, props := range c.nm.connections[c.path] {
+		result[group] = map[string]dbus.Variant{}
+		for key, value := range props {
+			if !fakeSecretKeys[group][key] {
+				result[group][key] = value
+			}
+		}
+	}
+	return result, nil
+}
+
+func (c fakeConnection) GetSecrets(group string) (map[string]map[string]dbus.Variant, *dbus.Error) {
+	c.nm.mu.Lock()
+	defer c.nm.mu.Unlock()
+	secrets := map[string]dbus.Variant{}
+	for key, value := range c.nm.connections[c.path][group] {
+		if fakeSecretKeys[group][key] {
+			secrets[key] = value
+		}
+	}
+	return map[string]map[string]dbus.Variant{group: secrets}, nil
+}
+
+// Update substitui o perfil inteiro, como o NetworkManager: segredos ausentes
+// são apagados
+func (c fakeConnection) Update(settings map[string]map[string]dbus.Variant) *dbus.Error {
+	c.nm.mu.Lock()
+	defer c.nm.mu.Unlock()
+	c.nm.connections[c.path] = settings
+	return nil
+}
+
+func (c fakeConnection) Delete() *dbus.Error {
+	c.nm.mu.Lock()
+	defer c.nm.mu.Unlock()
+	delete(c.nm.connections, c.path)
+	c.nm.conn.Export(nil, c.path, nmConnectionIface)
+	return nil
+}
+
+// fakeWireless implementa org.freedesktop.NetworkManager.Device.Wireless
+type fakeWireless struct {
+	nm     *fakeNM
+	device string
+}
+
+func (w fakeWireless) RequestScan(options map[string]dbus.Variant) *dbus.Error {
+	w.nm.mu.Lock()
+	defer w.nm.mu.Unlock()
+	w.nm.scans = append(w.nm.scans, w.device)
+	return nil
+}
+
+func (w fakeWireless) GetAllAccessPoints() ([]dbus.ObjectPath, *dbus.Error) {
+	return []dbus.ObjectPath{"/org/freedesktop/NetworkManager/AccessPoint/1"}, nil
+}
+
+func TestDBusDevices(t *testing.T) {
+	_, b := newFakeNM(t)
+
+	devices, err := b.Devices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(devices) != 2 {
+		t.Fatalf("esperava eth0 e wlan0 (sem o loopback), obteve %+v", devices)
+	}
+	eth := devices[0]
+	if eth.Name != "eth0" || eth.Type != "ethernet" || eth.State != "connected" || eth.Connection != "Cabeada" {
+		t.Errorf("eth0 inesperado: %+v", eth)
+	}
+	if len(eth.IPv4) != 1 || eth.IPv4[0] != "192.168.1.10/24" || eth.Gateway != "192.168.1.1" {
+		t.Errorf("endereços de eth0: %v via %s", eth.IPv4, eth.Gateway)
+	}
+	if len(eth.DNS) != 1 || eth.DNS[0] != "1.1.1.1" {
+		t.Errorf("DNS de eth0: %v", eth.DNS)
+	}
+	if wlan := devices[1]; wlan.Name != "wlan0" || wlan.Type != "wifi" || wlan.State != "disconnected" {
+		t.Errorf("wlan0 inesperado: %+v", wlan)
+	}
+}
+
+func TestDBusProfiles(t *testing.T) {
+	_, b := newFakeNM(t)
+
+	profiles, err := b.Profiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 2 {
+		t.Fatalf("esperava 2 perfis, obteve %+v", profiles)
+	}
+	if p := profiles[0]; p.Name != "Cabeada" || !p.Active || p.Device != "eth0" || p.Type != "802-3-ethernet" {
+		t.Errorf("perfil cabeado inesperado: %+v", p)
+	}
+	if p := profiles[1]; p.Name != "Casa" || p.Active {
+		t.Errorf("perfil Wi-Fi inesperado: %+v", p)
+	}
+
+	p, err := b.Profile("Casa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Settings["802-11-wireless.ssid"] != "Casa" || p.Settings["ipv4.method"] != "auto" {
+		t.Errorf("propriedades de Casa: %v", p.Settings)
+	}
+	if _, ok := p.Settings["802-11-wireless-security.psk"]; ok {
+		t.Errorf("Profile não deveria retornar segredos: %v", p.Settings)
+	}
+
+	p, err = b.ProfileWithSecrets("22222222-2222-2222-2222-222222222222")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Settings["802-11-wireless-security.psk"]; got != "segredo123" {
+		t.Errorf("ProfileWithSecrets: psk = %q", got)
+	}
+
+	if _, err := b.Profile("inexistente"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("perfil inexistente: esperava ErrNotFound, obteve %v", err)
+	}
+}
+
+func TestDBusModifyProfileKeepsSecrets(t *testing.T) {
+	nm, b := newFakeNM(t)
+
+	err := b.ModifyProfile("Casa", Settings{
+		"ipv4.method":    "manual",
+		"ipv4.addresses": "10.0.0.5/24",
+		"ipv4.gateway":   "10.0.0.1",
+		"ipv4.dns":       "9.9.9.9",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored := nm.stored("22222222-2222-2222-2222-222222222222")
+	if got := variantString(stored["802-11-wireless-security"]["psk"]); got != "segredo123" {
+		t.Errorf("Update apagou a senha: psk = %q", got)
+	}
+	p, err := b.Profile("Casa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"ipv4.method":    "manual",
+		"ipv4.addresses": "10.0.0.5/24",
+		"ipv4.gateway":   "10.0.0.1",
+		"ipv4.dns":       "9.9.9.9",
+	} {
+		if got := p.Settings[key]; got != want {
+			t.Errorf("%s = %q, esperava %q", key, got, want)
+		}
+	}
+}
+
+func TestDBusActivateDeactivate(t *testing.T) {
+	_, b := newFakeNM(t)
+
+	if err := b.Activate("Casa"); err != nil {
+		t.Fatal(err)
+	}
+	p, err := b.Profile("Casa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Active || p.Device != "wlan0" {
+		t.Fatalf("depois de Activate: ativo=%v dispositivo=%q", p.Active, p.Device)
+	}
+
+	if err := b.Deactivate("Casa"); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := b.Profile("Casa"); p.Active {
+		t.Error("o perfil continua ativo depois de Deactivate")
+	}
+	if err := b.Deactivate("Casa"); !errors.Is(err, ErrNotActive) {
+		t.Errorf("Deactivate de um perfil inativo: esperava ErrNotActive, obteve %v", err)
+	}
+}
+
+func TestDBusAddDeleteProfile(t *testing.T) {
+	nm, b := newFakeNM(t)
+
+	created, err := b.AddProfile(Profile{
+		Name:   "Escritório",
+		Type:   "802-3-ethernet",
+		Device: "eth0",
+		Settings: Settings{
+			"ipv4.method":            "manual",
+			"ipv4.addresses":         "172.16.0.2/16",
+			"connection.autoconnect": "no",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.UUID == "" || created.Name != "Escritório" || created.Settings["ipv4.addresses"] != "172.16.0.2/16" {
+		t.Fatalf("perfil criado inesperado: %+v", created)
+	}
+	stored := nm.stored(created.UUID)
+	if autoconnect, _ := stored["connection"]["autoconnect"].Value().(bool); autoconnect || stored["connection"]["autoconnect"].Signature().String() != "b" {
+		t.Errorf("connection.autoconnect gravado como %v", stored["connection"]["autoconnect"])
+	}
+
+	if err := b.DeleteProfile(created.UUID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Profile(created.UUID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("perfil removido: esperava ErrNotFound, obteve %v", err)
+	}
+}
+
+func TestDBusScan(t *testing.T) {
+	nm, b := newFakeNM(t)
+
+	if err := b.Rescan("wlan0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Rescan("eth0"); err != nil {
+		t.Fatal(err)
+	}
+	nm.mu.Lock()
+	scans := strings.Join(nm.scans, ",")
+	nm.mu.Unlock()
+	if scans != "wlan0" {
+		t.Errorf("RequestScan chamado para %q, esperava só wlan0", scans)
+	}
+
+	aps, err := b.Scan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(aps) != 1 {
+		t.Fatalf("esperava 1 ponto de acesso, obteve %+v", aps)
+	}
+	ap := aps[0]
+	if ap.SSID != "Casa" || ap.Signal != 70 || ap.Frequency != 5180 || ap.Channel != 36 || !ap.Secured {
+		t.Errorf("ponto de acesso inesperado: %+v", ap)
+	}
+}
+
+func TestDBusWatch(t *testing.T) {
+	nm, b := newFakeNM(t)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	events, err := b.Watch(stop)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nm.conn.Emit(nmPath, nmIface+".StateChanged", uint32(70))
+	select {
+	case <-events:
+	case <-time.After(5 * time.Second):
+		t.Fatal("nenhum aviso depois do sinal StateChanged")
+	}
+}
@@ -0,0 +1,130 @@
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"networkmanager-tui/logger"
+)
+
+// Timeout para comandos externos
+const commandTimeout = 15 * time.Second
+
+// commandError é o erro de um comando que terminou com código diferente de zero
+type commandError struct {
+	name string
+	code int
+	msg  string
+}
+
+func (e *commandError) Error() string {
+	return fmt.Sprintf("%s falhou (código %d): %s", e.name, e.code, e.msg)
+}
+
+// run executa um comando com timeout e retorna a saída padrão. Em caso de
+// erro, a saída de erro do comando é incluída na mensagem.
+func run(name string, args ...string) (string, error) {
+	return runInput("", name, args...)
+}
+
+// runInput executa o comando como run, com input na entrada padrão. A
+// entrada não é registrada no log, e por isso pode conter segredos.
+func runInput(input, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	logger.Command(name, args, time.Since(start), err)
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("timeout ao executar %s", name)
+	}
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", &commandError{name: name, code: exitErr.ExitCode(), msg: msg}
+		}
+		return "", fmt.Errorf("erro ao executar %s: %w", name, err)
+	}
+	return stdout.String(), nil
+}
+
+// watchCommand executa um comando de monitoramento (ex.: "nmcli monitor") e
+// envia um aviso a cada linha impressa, até que stop seja fechado
+func watchCommand(stop <-chan struct{}, name string, args ...string) (<-chan struct{}, error) {
+	cmd := exec.Command(name, args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao preparar %s: %w", name, err)
+	}
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		logger.Command(name, args, time.Since(start), err)
+		return nil, fmt.Errorf("erro ao iniciar %s: %w", name, err)
+	}
+
+	events := make(chan struct{}, 1)
+	done := make(chan struct{})
+
+	// Encerra o comando quando o chamador não quiser mais avisos
+	go func() {
+		select {
+		case <-stop:
+			cmd.Process.Kill()
+		case <-done:
+		}
+	}()
+
+	go func() {
+		defer close(events)
+		defer close(done)
+
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			notify(events)
+		}
+		// Registra quando o monitor termina, inclusive ao ser encerrado
+		logger.Command(name, args, time.Since(start), cmd.Wait())
+	}()
+
+	return events, nil
+}
+
+// shellQuote coloca o argumento entre aspas simples quando necessário, para
+// exibir comandos que podem ser copiados para o shell
+func shellQuote(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+	if strings.IndexFunc(arg, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:,=@%+", r))
+	}) < 0 {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// shellJoin monta a linha de comando com os argumentos já protegidos
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
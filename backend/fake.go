@@ -0,0 +1,426 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fake implementa Backend em memória, para o modo de desenvolvimento e para
+// exercitar as interfaces sem tocar na rede do host
+type Fake struct {
+	mu       sync.Mutex
+	devices  []Device
+	profiles []Profile
+	aps      []AccessPoint
+	watchers []chan struct{}
+}
+
+// NewFake cria um backend em memória com dados de exemplo
+func NewFake() *Fake {
+	return &Fake{
+		devices: []Device{
+			{
+				Name:       "eth0",
+				Type:       "ethernet",
+				State:      "connected",
+				Connection: "Ethernet Connection",
+				MAC:        "52:54:00:12:34:56",
+				IPv4:       []string{"192.168.1.100/24"},
+				IPv6:       []string{"fe80::1234:5678:abcd:ef12/64"},
+				Gateway:    "192.168.1.1",
+				DNS:        []string{"8.8.8.8", "8.8.4.4"},
+			},
+			{
+				Name:  "eth1",
+				Type:  "ethernet",
+				State: "disconnected",
+				MAC:   "52:54:00:12:34:57",
+			},
+			{
+				Name:  "eth2",
+				Type:  "ethernet",
+				State: "disconnected",
+				MAC:   "52:54:00:12:34:58",
+			},
+			{
+				Name:  "wlan0",
+				Type:  "wifi",
+				State: "disconnected",
+				MAC:   "52:54:00:ab:cd:ef",
+			},
+			{
+				Name:       "tun0",
+				Type:       "tun",
+				State:      "connected",
+				Connection: "VPN Connection",
+				IPv4:       []string{"10.8.0.2/24"},
+				Gateway:    "10.8.0.1",
+				DNS:        []string{"10.8.0.1"},
+			},
+		},
+		profiles: []Profile{
+			{
+				UUID:   "5b1f7a56-2d0e-4c2b-9c3e-1a2b3c4d5e6f",
+				Name:   "Ethernet Connection",
+				Type:   "802-3-ethernet",
+				Device: "eth0",
+				Active: true,
+				Settings: Settings{
+					"ipv4.method":                     "manual",
+					"ipv4.addresses":                  "192.168.1.100/24",
+					"ipv4.gateway":                    "192.168.1.1",
+					"ipv4.dns":                        "8.8.8.8,8.8.4.4",
+					"ipv6.method":                     "auto",
+					"connection.autoconnect-priority": "10",
+					"connection.timestamp":            "1700000000",
+				},
+			},
+			{
+				UUID:   "9f8e7d6c-5b4a-4392-8170-6f5e4d3c2b1a",
+				Name:   "Wi-Fi Network",
+				Type:   "802-11-wireless",
+				Device: "wlan0",
+				Settings: Settings{
+					"802-11-wireless.ssid":              "Office",
+					"802-11-wireless-security.key-mgmt": "wpa-psk",
+					"802-11-wireless-security.psk":      "office-secret",
+					"connection.autoconnect":            "yes",
+					"connection.timestamp":              "1699990000",
+					"ipv4.method":                       "auto",
+					"ipv6.method":                       "auto",
+				},
+			},
+			{
+				UUID:   "0a1b2c3d-4e5f-4a6b-8c7d-8e9f0a1b2c3d",
+				Name:   "VPN Connection",
+				Type:   "vpn",
+				Device: "tun0",
+				Active: true,
+				Settings: Settings{
+					"vpn.service-type":       openVPNService,
+					"vpn.data":               "remote = vpn.example.com:1194, dev = tun",
+					"connection.autoconnect": "no",
+					"connection.timestamp":   "1700000000",
+					"ipv4.method":            "auto",
+					"ipv6.method":            "disabled",
+				},
+			},
+		},
+		aps: []AccessPoint{
+			{SSID: "Office", BSSID: "00:11:22:33:44:55", Signal: 82, DBm: -59, Frequency: 5180, Channel: 36, Secured: true, Security: SecurityPSK},
+			{SSID: "Office", BSSID: "00:11:22:33:44:65", Signal: 58, DBm: -71, Frequency: 2437, Channel: 6, Secured: true, Security: SecurityPSK},
+			{SSID: "Corp", BSSID: "00:11:22:33:44:57", Signal: 77, DBm: -62, Frequency: 5500, Channel: 100, Secured: true, Security: SecurityEAP},
+			{SSID: "Guest", BSSID: "00:11:22:33:44:56", Signal: 64, DBm: -68, Frequency: 2412, Channel: 1, Secured: false, Security: SecurityOpen},
+			{SSID: "Lab", BSSID: "66:77:88:99:AA:BB", Signal: 31, DBm: -85, Frequency: 6115, Channel: 33, Secured: true, Security: SecuritySAE},
+		},
+	}
+}
+
+// Name retorna o nome do backend
+func (f *Fake) Name() string {
+	return "fake"
+}
+
+// Devices lista os dispositivos simulados
+func (f *Fake) Devices() ([]Device, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	devices := make([]Device, len(f.devices))
+	copy(devices, f.devices)
+	return devices, nil
+}
+
+// Profiles lista os perfis simulados
+func (f *Fake) Profiles() ([]Profile, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	profiles := make([]Profile, len(f.profiles))
+	for i, p := range f.profiles {
+		p.Settings = p.Settings.Clone()
+		profiles[i] = p
+	}
+	return profiles, nil
+}
+
+// Profile obtém um perfil pelo UUID ou nome
+func (f *Fake) Profile(id string) (Profile, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i, err := f.index(id)
+	if err != nil {
+		return Profile{}, err
+	}
+	p := f.profiles[i]
+	p.Settings = p.Settings.Clone()
+	return p, nil
+}
+
+// AddProfile adiciona um perfil em memória
+func (f *Fake) AddProfile(p Profile) (Profile, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.index(p.Name); err == nil {
+		return Profile{}, fmt.Errorf("perfil %s já existe", p.Name)
+	}
+	// Como no NetworkManager, connection.uuid escolhe o UUID do perfil
+	if p.UUID = p.Settings["connection.uuid"]; p.UUID == "" {
+		p.UUID = newUUID()
+	}
+	p.Active = false
+	p.Settings = p.Settings.Clone()
+	f.profiles = append(f.profiles, p)
+	f.changed()
+	return p, nil
+}
+
+// ModifyProfile altera propriedades de um perfil em memória
+func (f *Fake) ModifyProfile(id string, settings Settings) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i, err := f.index(id)
+	if err != nil {
+		return err
+	}
+	if f.profiles[i].Settings == nil {
+		f.profiles[i].Settings = Settings{}
+	}
+	for k, v := range settings {
+		f.profiles[i].Settings[k] = v
+	}
+	if name, ok := settings["connection.id"]; ok {
+		f.profiles[i].Name = name
+	}
+	if f.profiles[i].Active {
+		f.applyToDevice(f.profiles[i])
+	}
+	f.changed()
+	return nil
+}
+
+// DeleteProfile remove um perfil em memória
+func (f *Fake) DeleteProfile(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i, err := f.index(id)
+	if err != nil {
+		return err
+	}
+	f.profiles = append(f.profiles[:i], f.profiles[i+1:]...)
+	f.changed()
+	return nil
+}
+
+// Activate marca o perfil como ativo e atualiza o dispositivo simulado
+func (f *Fake) Activate(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i, err := f.index(id)
+	if err != nil {
+		return err
+	}
+	for j := range f.profiles {
+		if f.profiles[j].Device == f.profiles[i].Device {
+			f.profiles[j].Active = false
+		}
+	}
+	f.profiles[i].Active = true
+	if f.profiles[i].Settings == nil {
+		f.profiles[i].Settings = Settings{}
+	}
+	f.profiles[i].Settings["connection.timestamp"] = strconv.FormatInt(time.Now().Unix(), 10)
+	f.applyToDevice(f.profiles[i])
+	f.changed()
+	return nil
+}
+
+// Deactivate marca o perfil como inativo
+func (f *Fake) Deactivate(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i, err := f.index(id)
+	if err != nil {
+		return err
+	}
+	if !f.profiles[i].Active {
+		return fmt.Errorf("perfil %s: %w", id, ErrNotActive)
+	}
+	f.profiles[i].Active = false
+	for j := range f.devices {
+		if f.devices[j].Name == f.profiles[i].Device {
+			f.devices[j] = Device{
+				Name:  f.devices[j].Name,
+				Type:  f.devices[j].Type,
+				MAC:   f.devices[j].MAC,
+				State: "disconnected",
+			}
+		}
+	}
+	f.changed()
+	return nil
+}
+
+// Scan retorna as redes Wi-Fi simuladas
+func (f *Fake) Scan() ([]AccessPoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	aps := make([]AccessPoint, len(f.aps))
+	copy(aps, f.aps)
+	return aps, nil
+}
+
+// ImportVPN interpreta o arquivo e adiciona o perfil em memória
+func (f *Fake) ImportVPN(kind, path string) (Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Profile{}, fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+
+	var p Profile
+	switch kind {
+	case VPNWireGuard:
+		p, err = ParseWireGuardConf(path, data)
+	case VPNOpenVPN:
+		p, err = parseOpenVPNConf(path, data)
+		f.mu.Lock()
+		for i := 0; p.Device == "" || f.hasDevice(p.Device); i++ {
+			p.Device = fmt.Sprintf("tun%d", i)
+		}
+		f.mu.Unlock()
+	default:
+		return Profile{}, fmt.Errorf("%s: %w", kind, ErrNotSupported)
+	}
+	if err != nil {
+		return Profile{}, err
+	}
+	return f.AddProfile(p)
+}
+
+// WireGuardStatus simula os peers do túnel ativo no dispositivo, com
+// handshake recente
+func (f *Fake) WireGuardStatus(device string) ([]PeerStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, p := range f.profiles {
+		if p.Device != device || p.Type != VPNWireGuard || !p.Active {
+			continue
+		}
+		peers, err := ParseWireGuardPeers(p.Settings["wireguard.peers"])
+		if err != nil {
+			return nil, err
+		}
+		status := make([]PeerStatus, len(peers))
+		for i, peer := range peers {
+			status[i] = PeerStatus{
+				PublicKey:       peer.PublicKey,
+				Endpoint:        peer.Endpoint,
+				AllowedIPs:      peer.AllowedIPs,
+				LatestHandshake: time.Now().Add(-time.Duration(30+i*45) * time.Second),
+				RxBytes:         int64(184320 * (i + 1)),
+				TxBytes:         int64(92160 * (i + 1)),
+			}
+		}
+		return status, nil
+	}
+	return nil, fmt.Errorf("túnel %s: %w", device, ErrNotFound)
+}
+
+// Watch avisa a cada alteração feita nos dados simulados
+func (f *Fake) Watch(stop <-chan struct{}) (<-chan struct{}, error) {
+	events := make(chan struct{}, 1)
+
+	f.mu.Lock()
+	f.watchers = append(f.watchers, events)
+	f.mu.Unlock()
+
+	go func() {
+		<-stop
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for i, w := range f.watchers {
+			if w == events {
+				f.watchers = append(f.watchers[:i], f.watchers[i+1:]...)
+				break
+			}
+		}
+		close(events)
+	}()
+	return events, nil
+}
+
+// changed avisa os observadores; deve ser chamado com f.mu travado
+func (f *Fake) changed() {
+	for _, w := range f.watchers {
+		notify(w)
+	}
+}
+
+// index retorna a posição do perfil com o UUID ou nome informado
+func (f *Fake) index(id string) (int, error) {
+	for i, p := range f.profiles {
+		if p.UUID == id || p.Name == id {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("perfil %s: %w", id, ErrNotFound)
+}
+
+// applyToDevice reflete as configurações do perfil no dispositivo simulado
+func (f *Fake) applyToDevice(p Profile) {
+	// Interfaces virtuais e túneis passam a existir ao serem ativados
+	if (IsVirtualType(p.Type) || VPNKind(p) != "") && !f.hasDevice(p.Device) {
+		f.devices = append(f.devices, Device{Name: p.Device, Type: p.Type})
+	}
+
+	for j := range f.devices {
+		dev := &f.devices[j]
+		if dev.Name != p.Device {
+			continue
+		}
+
+		dev.State = "connected"
+		dev.Connection = p.Name
+		if p.Settings["connection.master"] != "" {
+			// Portas de bond, bridge ou team não têm configuração IP própria
+			continue
+		}
+		if p.Settings["ipv4.method"] == "manual" {
+			dev.IPv4 = splitList(p.Settings["ipv4.addresses"])
+			dev.Gateway = p.Settings["ipv4.gateway"]
+		}
+		switch p.Settings["ipv6.method"] {
+		case "manual":
+			dev.IPv6 = splitList(p.Settings["ipv6.addresses"])
+			dev.Gateway6 = p.Settings["ipv6.gateway"]
+		case "disabled":
+			dev.IPv6 = nil
+			dev.Gateway6 = ""
+		}
+		if dns := p.Settings["ipv4.dns"]; dns != "" {
+			dev.DNS = splitList(strings.Join([]string{dns, p.Settings["ipv6.dns"]}, ","))
+		}
+	}
+}
+
+// hasDevice informa se o dispositivo simulado existe
+func (f *Fake) hasDevice(name string) bool {
+	for _, dev := range f.devices {
+		if dev.Name == name {
+			return true
+		}
+	}
+	return false
+}
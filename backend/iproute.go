@@ -0,0 +1,669 @@
+package backend
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Diretório padrão onde o backend iproute2 guarda seus perfis
+const defaultProfileDir = "/etc/networkmanager-tui/profiles"
+
+// Diretório onde o backend iproute2 grava, na ativação, os arquivos lidos
+// pelos comandos externos (chaves WireGuard, configuração do wpa_supplicant)
+const runtimeDir = "/run/networkmanager-tui"
+
+// IPRoute implementa Backend para hosts sem NetworkManager, usando os
+// comandos do iproute2. Como o iproute2 não tem o conceito de perfil, os
+// perfis são guardados em arquivos JSON e aplicados na ativação.
+type IPRoute struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewIPRoute cria um backend iproute2 com o diretório de perfis padrão
+func NewIPRoute() *IPRoute {
+	return NewIPRouteWithDir(defaultProfileDir)
+}
+
+// NewIPRouteWithDir cria um backend iproute2 guardando perfis em dir
+func NewIPRouteWithDir(dir string) *IPRoute {
+	return &IPRoute{dir: dir}
+}
+
+// Name retorna o nome do backend
+func (r *IPRoute) Name() string {
+	return "iproute2"
+}
+
+// Devices lista as interfaces do sistema com seus endereços e rotas
+func (r *IPRoute) Devices() ([]Device, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar interfaces: %w", err)
+	}
+
+	addrOut, err := run("ip", "-o", "addr", "show")
+	if err != nil {
+		return nil, err
+	}
+	routeOut, _ := run("ip", "route", "show", "default")
+	route6Out, _ := run("ip", "-6", "route", "show", "default")
+	dns := readResolvConf()
+
+	profiles, _ := r.loadProfiles()
+
+	var devices []Device
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+
+		dev := Device{
+			Name:     iface.Name,
+			Type:     guessDeviceType(iface.Name),
+			MAC:      iface.HardwareAddr.String(),
+			Gateway:  defaultGateway(routeOut, iface.Name),
+			Gateway6: defaultGateway(route6Out, iface.Name),
+		}
+		dev.IPv4, dev.IPv6 = parseAddrLines(addrOut, iface.Name)
+
+		switch {
+		case iface.Flags&net.FlagUp == 0:
+			dev.State = "disconnected"
+		case len(dev.IPv4) > 0 || len(dev.IPv6) > 0:
+			dev.State = "connected"
+			dev.DNS = dns
+		default:
+			dev.State = "connecting"
+		}
+
+		for _, p := range profiles {
+			if p.Device == dev.Name && p.Active {
+				dev.Connection = p.Name
+			}
+		}
+		devices = append(devices, dev)
+	}
+	return devices, nil
+}
+
+// Profiles lista os perfis salvos
+func (r *IPRoute) Profiles() ([]Profile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.loadProfiles()
+}
+
+// Profile obtém um perfil pelo UUID ou nome
+func (r *IPRoute) Profile(id string) (Profile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.findProfile(id)
+}
+
+// AddProfile grava um novo perfil
+func (r *IPRoute) AddProfile(p Profile) (Profile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p.Name == "" {
+		return Profile{}, fmt.Errorf("nome do perfil é obrigatório")
+	}
+	if _, err := r.findProfile(p.Name); err == nil {
+		return Profile{}, fmt.Errorf("perfil %s já existe", p.Name)
+	}
+	if p.Type == "team" || p.Settings["connection.slave-type"] == "team" {
+		// O team depende do daemon teamd, gerenciado pelo NetworkManager
+		return Profile{}, fmt.Errorf("team: %w", ErrNotSupported)
+	}
+
+	p.UUID = newUUID()
+	p.Active = false
+	p.Settings = p.Settings.Clone()
+	p.Settings["connection.id"] = p.Name
+	p.Settings["connection.uuid"] = p.UUID
+	p.Settings["connection.type"] = p.Type
+	p.Settings["connection.interface-name"] = p.Device
+
+	if err := r.saveProfile(p); err != nil {
+		return Profile{}, err
+	}
+	return p, nil
+}
+
+// ModifyProfile altera propriedades do perfil e reaplica se estiver ativo
+func (r *IPRoute) ModifyProfile(id string, settings Settings) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, err := r.findProfile(id)
+	if err != nil {
+		return err
+	}
+	for k, v := range settings {
+		p.Settings[k] = v
+	}
+	if name, ok := settings["connection.id"]; ok {
+		p.Name = name
+	}
+	if dev, ok := settings["connection.interface-name"]; ok {
+		p.Device = dev
+	}
+
+	if err := r.saveProfile(p); err != nil {
+		return err
+	}
+	if p.Active {
+		return applyProfile(p)
+	}
+	return nil
+}
+
+// DeleteProfile remove o arquivo do perfil
+func (r *IPRoute) DeleteProfile(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, err := r.findProfile(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(r.profilePath(p)); err != nil {
+		return fmt.Errorf("erro ao remover perfil %s: %w", p.Name, err)
+	}
+	return nil
+}
+
+// Activate aplica o perfil no dispositivo associado
+func (r *IPRoute) Activate(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, err := r.findProfile(id)
+	if err != nil {
+		return err
+	}
+	if p.Device == "" {
+		return fmt.Errorf("perfil %s não tem dispositivo associado", p.Name)
+	}
+	if err := applyProfile(p); err != nil {
+		return err
+	}
+
+	// Apenas um perfil ativo por dispositivo
+	profiles, _ := r.loadProfiles()
+	for _, other := range profiles {
+		if other.Device == p.Device && other.Active && other.UUID != p.UUID {
+			other.Active = false
+			r.saveProfile(other)
+		}
+	}
+
+	p.Active = true
+	p.Settings["connection.timestamp"] = strconv.FormatInt(time.Now().Unix(), 10)
+	return r.saveProfile(p)
+}
+
+// Deactivate remove os endereços do dispositivo e o desliga
+func (r *IPRoute) Deactivate(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, err := r.findProfile(id)
+	if err != nil {
+		return err
+	}
+	if p.Device != "" {
+		if usesWPASupplicant(p) {
+			run("wpa_cli", "-i", p.Device, "terminate")
+		}
+		if _, err := run("ip", "addr", "flush", "dev", p.Device); err != nil {
+			return err
+		}
+		if _, err := run("ip", "link", "set", "dev", p.Device, "down"); err != nil {
+			return err
+		}
+	}
+
+	p.Active = false
+	return r.saveProfile(p)
+}
+
+// Scan faz a varredura com "iwlist" em cada dispositivo Wi-Fi, para hosts
+// sem NetworkManager
+func (r *IPRoute) Scan() ([]AccessPoint, error) {
+	devices, err := r.Devices()
+	if err != nil {
+		return nil, err
+	}
+
+	var aps []AccessPoint
+	found := false
+	for _, dev := range devices {
+		if dev.Type != "wifi" {
+			continue
+		}
+		found = true
+		out, err := run("iwlist", dev.Name, "scan")
+		if err != nil {
+			return nil, fmt.Errorf("erro na varredura Wi-Fi: %w", err)
+		}
+		aps = append(aps, parseIwlistScan(out)...)
+	}
+	if !found {
+		return nil, fmt.Errorf("dispositivo Wi-Fi: %w", ErrNotFound)
+	}
+	return aps, nil
+}
+
+// ImportVPN importa um arquivo WireGuard como perfil; OpenVPN depende do
+// plugin do NetworkManager
+func (r *IPRoute) ImportVPN(kind, path string) (Profile, error) {
+	if kind != VPNWireGuard {
+		return Profile{}, fmt.Errorf("%s: %w", kind, ErrNotSupported)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Profile{}, fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+	p, err := ParseWireGuardConf(path, data)
+	if err != nil {
+		return Profile{}, err
+	}
+	return r.AddProfile(p)
+}
+
+// WireGuardStatus lê o estado dos peers com "wg show"
+func (r *IPRoute) WireGuardStatus(device string) ([]PeerStatus, error) {
+	return wireGuardStatus(device)
+}
+
+// Watch acompanha mudanças de enlace, endereços e rotas com "ip monitor"
+func (r *IPRoute) Watch(stop <-chan struct{}) (<-chan struct{}, error) {
+	return watchCommand(stop, "ip", "monitor", "link", "address", "route")
+}
+
+// Plan descreve os comandos que ModifyProfile e Activate executarão
+func (r *IPRoute) Plan(id string, settings Settings) []string {
+	r.mu.Lock()
+	p, err := r.findProfile(id)
+	r.mu.Unlock()
+	if err != nil {
+		return nil
+	}
+
+	for k, v := range settings {
+		p.Settings[k] = v
+	}
+
+	var lines []string
+	for _, st := range applySteps(p) {
+		lines = append(lines, shellJoin(st.args))
+	}
+	if dns := profileDNS(p); len(dns) > 0 {
+		lines = append(lines, "# /etc/resolv.conf: nameserver "+strings.Join(dns, ", nameserver "))
+	}
+	if search := profileDNSSearch(p); len(search) > 0 {
+		lines = append(lines, "# /etc/resolv.conf: search "+strings.Join(search, " "))
+	}
+	return lines
+}
+
+// PlanAdd descreve a gravação do perfil feita por AddProfile
+func (r *IPRoute) PlanAdd(p Profile) []string {
+	return []string{fmt.Sprintf("# %s: %s (%s)", r.dir, p.Name, p.Device)}
+}
+
+// step é um comando executado na aplicação de um perfil
+type step struct {
+	args     []string
+	optional bool // Falhas são ignoradas
+}
+
+// applySteps retorna os comandos que aplicam endereços, gateway, rotas e
+// regras de roteamento do perfil
+func applySteps(p Profile) []step {
+	dev := p.Device
+	if master := p.Settings["connection.master"]; master != "" {
+		return portSteps(dev, master)
+	}
+
+	steps := linkSteps(p)
+	steps = append(steps, step{args: []string{"ip", "link", "set", "dev", dev, "up"}})
+
+	for _, family := range []string{"ipv4", "ipv6"} {
+		flag := "-4"
+		if family == "ipv6" {
+			flag = "-6"
+		}
+
+		switch p.Settings[family+".method"] {
+		case "manual":
+			steps = append(steps, step{args: []string{"ip", flag, "addr", "flush", "dev", dev}})
+			for _, addr := range splitList(p.Settings[family+".addresses"]) {
+				steps = append(steps, step{args: []string{"ip", flag, "addr", "add", addr, "dev", dev}})
+			}
+			if gw := p.Settings[family+".gateway"]; gw != "" && p.Settings[family+".never-default"] != "yes" {
+				args := []string{"ip", flag, "route", "replace", "default", "via", gw, "dev", dev}
+				if metric := p.Settings[family+".route-metric"]; metric != "" && metric != "-1" {
+					args = append(args, "metric", metric)
+				}
+				steps = append(steps, step{args: args})
+			}
+		case "disabled":
+			steps = append(steps, step{args: []string{"ip", flag, "addr", "flush", "dev", dev}})
+			continue
+		case "auto", "":
+			// Endereçamento dinâmico fica a cargo do cliente DHCP, se existir
+			if family == "ipv4" {
+				if _, err := exec.LookPath("dhclient"); err == nil {
+					steps = append(steps,
+						step{args: []string{"dhclient", "-r", dev}, optional: true},
+						step{args: []string{"dhclient", dev}})
+				}
+			}
+		}
+
+		for _, route := range splitList(p.Settings[family+".routes"]) {
+			steps = append(steps, step{args: routeArgs(flag, route, dev)})
+		}
+		for _, rule := range splitList(p.Settings[family+".routing-rules"]) {
+			// Remove a regra de mesma prioridade, se houver, para não duplicar
+			fields := strings.Fields(rule)
+			if priority := ruleField(fields, "priority"); priority != "" {
+				steps = append(steps, step{args: []string{"ip", flag, "rule", "del", "priority", priority}, optional: true})
+			}
+			steps = append(steps, step{args: append([]string{"ip", flag, "rule", "add"}, fields...)})
+		}
+	}
+	return steps
+}
+
+// linkSteps cria a interface virtual do perfil (VLAN, bond, bridge ou
+// WireGuard). Se ela já existir, o erro é ignorado.
+func linkSteps(p Profile) []step {
+	dev := p.Device
+	switch p.Type {
+	case VPNWireGuard:
+		return wireGuardSteps(p)
+	case WiFiType, "802-3-ethernet":
+		if usesWPASupplicant(p) {
+			return wpaSupplicantSteps(p)
+		}
+	case "vlan":
+		return []step{{args: []string{"ip", "link", "add", "link", p.Settings["vlan.parent"],
+			"name", dev, "type", "vlan", "id", p.Settings["vlan.id"]}, optional: true}}
+	case "bond":
+		args := []string{"ip", "link", "add", dev, "type", "bond"}
+		for _, option := range splitList(p.Settings["bond.options"]) {
+			// primary só pode ser definido depois que as portas entram no bond
+			if key, value, ok := strings.Cut(option, "="); ok && key != "primary" {
+				args = append(args, key, value)
+			}
+		}
+		return []step{{args: args, optional: true}}
+	case "bridge":
+		stp := "0"
+		if p.Settings["bridge.stp"] == "yes" {
+			stp = "1"
+		}
+		return []step{
+			{args: []string{"ip", "link", "add", dev, "type", "bridge"}, optional: true},
+			{args: []string{"ip", "link", "set", "dev", dev, "type", "bridge", "stp_state", stp}},
+		}
+	}
+	return nil
+}
+
+// portSteps inclui o dispositivo como porta da interface master
+func portSteps(dev, master string) []step {
+	return []step{
+		{args: []string{"ip", "link", "set", "dev", dev, "down"}, optional: true},
+		{args: []string{"ip", "link", "set", "dev", dev, "master", master}},
+		{args: []string{"ip", "link", "set", "dev", dev, "up"}},
+	}
+}
+
+// routeArgs converte uma rota no formato do nmcli
+// ("destino/prefixo [próximo-salto] [métrica] [atributo=valor]...") no
+// comando "ip route replace" equivalente
+func routeArgs(flag, route, dev string) []string {
+	fields := strings.Fields(route)
+	args := []string{"ip", flag, "route", "replace", fields[0]}
+	for _, field := range fields[1:] {
+		key, value, isAttr := strings.Cut(field, "=")
+		switch {
+		case isAttr && key == "onlink":
+			if value == "true" || value == "yes" {
+				args = append(args, "onlink")
+			}
+		case isAttr:
+			args = append(args, key, value)
+		case net.ParseIP(field) != nil:
+			args = append(args, "via", field)
+		default:
+			args = append(args, "metric", field)
+		}
+	}
+	return append(args, "dev", dev)
+}
+
+// ruleField retorna o valor que segue a palavra-chave em uma regra
+func ruleField(fields []string, keyword string) string {
+	for i := 0; i+1 < len(fields); i++ {
+		if fields[i] == keyword {
+			return fields[i+1]
+		}
+	}
+	return ""
+}
+
+// profileDNS retorna os servidores DNS IPv4 e IPv6 do perfil
+func profileDNS(p Profile) []string {
+	return append(splitList(p.Settings["ipv4.dns"]), splitList(p.Settings["ipv6.dns"])...)
+}
+
+// profileDNSSearch retorna os domínios de busca IPv4 e IPv6 do perfil
+func profileDNSSearch(p Profile) []string {
+	return append(splitList(p.Settings["ipv4.dns-search"]), splitList(p.Settings["ipv6.dns-search"])...)
+}
+
+// applyProfile aplica endereços, gateway, rotas e DNS do perfil com o iproute2
+func applyProfile(p Profile) error {
+	if p.Type == VPNWireGuard {
+		if err := writeWireGuardKeys(p); err != nil {
+			return err
+		}
+	}
+	if usesWPASupplicant(p) {
+		if err := writeWPAConfig(p); err != nil {
+			return err
+		}
+	}
+	for _, st := range applySteps(p) {
+		if _, err := run(st.args[0], st.args[1:]...); err != nil && !st.optional {
+			return err
+		}
+	}
+
+	dns, search := profileDNS(p), profileDNSSearch(p)
+	if len(dns) > 0 || len(search) > 0 {
+		return writeResolvConf(dns, search)
+	}
+	return nil
+}
+
+// loadProfiles lê todos os perfis do diretório
+func (r *IPRoute) loadProfiles() ([]Profile, error) {
+	files, err := os.ReadDir(r.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler diretório de perfis: %w", err)
+	}
+
+	var profiles []Profile
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(r.dir, file.Name()))
+		if err != nil {
+			continue
+		}
+		var p Profile
+		if err := json.Unmarshal(data, &p); err != nil {
+			continue
+		}
+		if p.Settings == nil {
+			p.Settings = Settings{}
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, nil
+}
+
+// findProfile procura um perfil pelo UUID ou nome
+func (r *IPRoute) findProfile(id string) (Profile, error) {
+	profiles, err := r.loadProfiles()
+	if err != nil {
+		return Profile{}, err
+	}
+	for _, p := range profiles {
+		if p.UUID == id || p.Name == id {
+			return p, nil
+		}
+	}
+	return Profile{}, fmt.Errorf("perfil %s: %w", id, ErrNotFound)
+}
+
+// saveProfile grava o perfil em disco
+func (r *IPRoute) saveProfile(p Profile) error {
+	if err := os.MkdirAll(r.dir, 0700); err != nil {
+		return fmt.Errorf("erro ao criar diretório de perfis: %w", err)
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao serializar perfil: %w", err)
+	}
+	if err := os.WriteFile(r.profilePath(p), data, 0600); err != nil {
+		return fmt.Errorf("erro ao gravar perfil: %w", err)
+	}
+	return nil
+}
+
+// profilePath retorna o caminho do arquivo do perfil
+func (r *IPRoute) profilePath(p Profile) string {
+	return filepath.Join(r.dir, p.UUID+".json")
+}
+
+// parseAddrLines extrai os endereços de uma interface da saída "ip -o addr"
+func parseAddrLines(out, iface string) (ipv4, ipv6 []string) {
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[1] != iface {
+			continue
+		}
+		switch fields[2] {
+		case "inet":
+			ipv4 = append(ipv4, fields[3])
+		case "inet6":
+			ipv6 = append(ipv6, fields[3])
+		}
+	}
+	return ipv4, ipv6
+}
+
+// defaultGateway extrai o gateway padrão de uma interface da saída "ip route"
+func defaultGateway(out, iface string) string {
+	re := regexp.MustCompile(`default via (\S+) dev ` + regexp.QuoteMeta(iface) + `\b`)
+	if m := re.FindStringSubmatch(out); len(m) == 2 {
+		return m[1]
+	}
+	return ""
+}
+
+// guessDeviceType deduz o tipo da interface pelo sysfs (Wi-Fi) ou pelo nome
+func guessDeviceType(name string) string {
+	if _, err := os.Stat(filepath.Join("/sys/class/net", name, "wireless")); err == nil {
+		return "wifi"
+	}
+	switch {
+	case strings.HasPrefix(name, "wl"):
+		return "wifi"
+	case strings.HasPrefix(name, "en"), strings.HasPrefix(name, "eth"):
+		return "ethernet"
+	case strings.HasPrefix(name, "tun"), strings.HasPrefix(name, "tap"), strings.HasPrefix(name, "wg"):
+		return "tun"
+	case strings.HasPrefix(name, "br"), strings.HasPrefix(name, "docker"):
+		return "bridge"
+	}
+	return "unknown"
+}
+
+// readResolvConf lê os servidores DNS de /etc/resolv.conf
+func readResolvConf() []string {
+	data, err := os.ReadFile("/etc/resolv.conf")
+	if err != nil {
+		return nil
+	}
+
+	var servers []string
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			servers = append(servers, fields[1])
+		}
+	}
+	return servers
+}
+
+// writeResolvConf grava os servidores DNS e os domínios de busca em
+// /etc/resolv.conf
+func writeResolvConf(servers, search []string) error {
+	var content strings.Builder
+	content.WriteString("# Generated by Network Manager TUI\n")
+	if len(search) > 0 {
+		fmt.Fprintf(&content, "search %s\n", strings.Join(search, " "))
+	}
+	for _, server := range servers {
+		if net.ParseIP(server) != nil {
+			fmt.Fprintf(&content, "nameserver %s\n", server)
+		}
+	}
+	if err := os.WriteFile("/etc/resolv.conf", []byte(content.String()), 0644); err != nil {
+		return fmt.Errorf("erro ao gravar resolv.conf: %w", err)
+	}
+	return nil
+}
+
+// splitList divide listas separadas por vírgula no formato do nmcli
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// newUUID gera um UUID aleatório (versão 4)
+func newUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,623 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// NetworkManager implementa Backend usando o nmcli. Os segredos (senhas e
+// chaves privadas) não são passados como argumentos, que ficam visíveis na
+// lista de processos: AddProfile e ModifyProfile os gravam no perfil pelo
+// editor do nmcli, que os lê da entrada padrão, e os guardam também até a
+// ativação, que os entrega por um "passwd-file" com permissão 0600 (para
+// segredos que o NetworkManager não guarda, como os de um agente).
+type NetworkManager struct {
+	mu      sync.Mutex
+	secrets map[string]Settings // Segredos a entregar na ativação, por perfil
+}
+
+// NewNetworkManager cria um backend baseado no nmcli
+func NewNetworkManager() *NetworkManager {
+	return &NetworkManager{secrets: map[string]Settings{}}
+}
+
+// Name retorna o nome do backend
+func (n *NetworkManager) Name() string {
+	return "networkmanager"
+}
+
+// Devices lista os dispositivos conhecidos pelo NetworkManager
+func (n *NetworkManager) Devices() ([]Device, error) {
+	out, err := run("nmcli", "-t", "-e", "yes", "-f", "DEVICE,TYPE,STATE,CONNECTION", "device", "status")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter status dos dispositivos: %w", err)
+	}
+
+	var devices []Device
+	for _, line := range strings.Split(out, "\n") {
+		fields := splitTerse(line)
+		if len(fields) < 4 {
+			continue
+		}
+
+		dev := Device{
+			Name:       fields[0],
+			Type:       fields[1],
+			State:      fields[2],
+			Connection: emptyIfDashes(fields[3]),
+		}
+
+		// Detalhes de endereçamento só existem para dispositivos conectados
+		if dev.State == "connected" {
+			n.fillDeviceDetails(&dev)
+		}
+		devices = append(devices, dev)
+	}
+	return devices, nil
+}
+
+// fillDeviceDetails preenche MAC, endereços, gateways e DNS do dispositivo
+func (n *NetworkManager) fillDeviceDetails(dev *Device) {
+	out, err := run("nmcli", "-t", "-e", "yes",
+		"-f", "GENERAL.HWADDR,IP4.ADDRESS,IP4.GATEWAY,IP4.DNS,IP6.ADDRESS,IP6.GATEWAY,IP6.DNS",
+		"device", "show", dev.Name)
+	if err != nil {
+		return
+	}
+
+	for key, value := range parseTerseRecords(out) {
+		switch {
+		case key == "GENERAL.HWADDR":
+			dev.MAC = first(value)
+		case strings.HasPrefix(key, "IP4.ADDRESS"):
+			dev.IPv4 = append(dev.IPv4, value...)
+		case strings.HasPrefix(key, "IP6.ADDRESS"):
+			dev.IPv6 = append(dev.IPv6, value...)
+		case key == "IP4.GATEWAY":
+			dev.Gateway = first(value)
+		case key == "IP6.GATEWAY":
+			dev.Gateway6 = first(value)
+		case strings.HasPrefix(key, "IP4.DNS"), strings.HasPrefix(key, "IP6.DNS"):
+			dev.DNS = append(dev.DNS, value...)
+		}
+	}
+}
+
+// Profiles lista todos os perfis de conexão
+func (n *NetworkManager) Profiles() ([]Profile, error) {
+	out, err := run("nmcli", "-t", "-e", "yes", "-f", "NAME,UUID,TYPE,DEVICE,ACTIVE", "connection", "show")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar perfis: %w", err)
+	}
+
+	var profiles []Profile
+	for _, line := range strings.Split(out, "\n") {
+		fields := splitTerse(line)
+		if len(fields) < 5 {
+			continue
+		}
+		profiles = append(profiles, Profile{
+			Name:   fields[0],
+			UUID:   fields[1],
+			Type:   fields[2],
+			Device: emptyIfDashes(fields[3]),
+			Active: fields[4] == "yes",
+		})
+	}
+	return profiles, nil
+}
+
+// Profile obtém um perfil com todas as suas propriedades
+func (n *NetworkManager) Profile(id string) (Profile, error) {
+	return n.profile(id, false)
+}
+
+// ProfileWithSecrets obtém o perfil com os segredos ("nmcli --show-secrets")
+func (n *NetworkManager) ProfileWithSecrets(id string) (Profile, error) {
+	return n.profile(id, true)
+}
+
+// profile lê as propriedades do perfil com "nmcli connection show"
+func (n *NetworkManager) profile(id string, secrets bool) (Profile, error) {
+	args := []string{"-t", "-e", "yes", "connection", "show", id}
+	if secrets {
+		args = append([]string{"--show-secrets"}, args...)
+	}
+	out, err := run("nmcli", args...)
+	if err != nil {
+		return Profile{}, nmcliError("ler perfil", id, err)
+	}
+
+	p := Profile{Settings: Settings{}}
+	for key, values := range parseTerseRecords(out) {
+		value := strings.Join(values, ",")
+		switch key {
+		case "GENERAL.STATE":
+			p.Active = value == "activated"
+		case "GENERAL.DEVICES":
+			p.Device = value
+		}
+
+		// Propriedades do perfil são minúsculas; as maiúsculas são estado
+		if key == strings.ToLower(key) && strings.Contains(key, ".") {
+			p.Settings[key] = value
+		}
+	}
+
+	p.Name = p.Settings["connection.id"]
+	p.UUID = p.Settings["connection.uuid"]
+	p.Type = p.Settings["connection.type"]
+	if p.Device == "" {
+		p.Device = p.Settings["connection.interface-name"]
+	}
+	return p, nil
+}
+
+// UUID do perfil criado na saída de "nmcli connection add" e "nmcli
+// connection import": "Connection 'X' (5b1f7a56-...) successfully added."
+var addedUUIDRegex = regexp.MustCompile(`\(([0-9a-fA-F-]{36})\)`)
+
+// AddProfile cria um novo perfil com "nmcli connection add" e grava os
+// segredos com saveSecrets. O perfil criado é lido pelo UUID da saída do
+// nmcli, já que pode haver outros perfis com o mesmo nome.
+func (n *NetworkManager) AddProfile(p Profile) (Profile, error) {
+	plain, secrets := splitSecrets(p.Settings)
+	p.Settings = plain
+	out, err := run("nmcli", addArgs(p)...)
+	if err != nil {
+		return Profile{}, fmt.Errorf("erro ao criar perfil %s: %w", p.Name, err)
+	}
+	m := addedUUIDRegex.FindStringSubmatch(out)
+	if m == nil {
+		return Profile{}, fmt.Errorf("erro ao criar perfil %s: saída inesperada do nmcli: %s", p.Name, strings.TrimSpace(out))
+	}
+
+	created, err := n.Profile(m[1])
+	if err != nil {
+		return Profile{}, err
+	}
+	n.keepSecrets(created.ID(), secrets)
+	if err := saveSecrets(created.ID(), secrets); err != nil {
+		return created, err
+	}
+	return created, nil
+}
+
+// addArgs monta os argumentos de "nmcli connection add" para o perfil
+func addArgs(p Profile) []string {
+	args := []string{"connection", "add", "type", p.Type, "con-name", p.Name}
+	if p.Device != "" {
+		args = append(args, "ifname", p.Device)
+	}
+	for _, key := range p.Settings.Keys() {
+		args = append(args, key, p.Settings[key])
+	}
+	return args
+}
+
+// ModifyProfile altera propriedades com "nmcli connection modify" e grava
+// os segredos alterados com saveSecrets
+func (n *NetworkManager) ModifyProfile(id string, settings Settings) error {
+	if len(settings) == 0 {
+		return nil
+	}
+
+	plain, secrets := splitSecrets(settings)
+	if len(plain) > 0 {
+		args := []string{"connection", "modify", id}
+		for _, key := range plain.Keys() {
+			args = append(args, key, plain[key])
+		}
+		if _, err := run("nmcli", args...); err != nil {
+			return nmcliError("modificar perfil", id, err)
+		}
+	}
+	n.keepSecrets(id, secrets)
+	return saveSecrets(id, secrets)
+}
+
+// saveSecrets grava os segredos no perfil com o editor do nmcli ("nmcli
+// connection edit"), que recebe os comandos pela entrada padrão, fora da
+// lista de processos
+func saveSecrets(id string, secrets Settings) error {
+	if len(secrets) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	for _, key := range secrets.Keys() {
+		if strings.ContainsAny(secrets[key], "\r\n") {
+			return fmt.Errorf("o valor de %s não pode conter quebras de linha", key)
+		}
+		fmt.Fprintf(&b, "set %s %s\n", key, secrets[key])
+	}
+	b.WriteString("save persistent\nquit\n")
+	if _, err := runInput(b.String(), "nmcli", "connection", "edit", id); err != nil {
+		return nmcliError("gravar segredos do perfil", id, err)
+	}
+	return nil
+}
+
+// planSaveSecrets descreve o comando de saveSecrets, com os valores
+// mascarados
+func planSaveSecrets(id string, secrets Settings) string {
+	masked := secrets.Masked()
+	commands := make([]string, 0, len(secrets)+1)
+	for _, key := range secrets.Keys() {
+		commands = append(commands, "set "+key+" "+masked[key])
+	}
+	commands = append(commands, "save persistent")
+	return shellJoin([]string{"nmcli", "connection", "edit", id}) + "  # entrada padrão: " + strings.Join(commands, "; ")
+}
+
+// keepSecrets guarda os segredos do perfil até a próxima ativação
+func (n *NetworkManager) keepSecrets(id string, secrets Settings) {
+	if len(secrets) == 0 {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.secrets[id] == nil {
+		n.secrets[id] = Settings{}
+	}
+	for key, value := range secrets {
+		n.secrets[id][key] = value
+	}
+}
+
+// pendingSecrets retorna os segredos do perfil ainda não entregues na ativação
+func (n *NetworkManager) pendingSecrets(id string) Settings {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.secrets[id].Clone()
+}
+
+// splitSecrets separa os segredos das demais propriedades, que vão na linha
+// de comando; segredos vazios (apagados) continuam nela. wireguard.peers com
+// chaves compartilhadas é gravado inteiro como segredo.
+func splitSecrets(settings Settings) (plain, secrets Settings) {
+	plain = settings.Clone()
+	secrets = Settings{}
+	for key, value := range settings {
+		if value != "" && (IsSecret(key) || key == peersKey && hasPeerKeys(value)) {
+			secrets[key] = value
+			delete(plain, key)
+		}
+	}
+	return plain, secrets
+}
+
+// writePasswdFile grava os segredos no formato do "passwd-file" do nmcli,
+// uma propriedade por linha ("propriedade:valor"), com permissão 0600
+func writePasswdFile(secrets Settings) (string, error) {
+	var b strings.Builder
+	for _, key := range secrets.Keys() {
+		if strings.ContainsAny(secrets[key], "\r\n") {
+			return "", fmt.Errorf("o valor de %s não pode conter quebras de linha", key)
+		}
+		lines, err := passwdLines(key, secrets[key])
+		if err != nil {
+			return "", err
+		}
+		for _, line := range lines {
+			b.WriteString(line + "\n")
+		}
+	}
+
+	if err := os.MkdirAll(runtimeDir, 0700); err != nil {
+		return "", fmt.Errorf("erro ao criar %s: %w", runtimeDir, err)
+	}
+	// CreateTemp cria o arquivo com permissão 0600
+	file, err := os.CreateTemp(runtimeDir, "secrets-*")
+	if err != nil {
+		return "", fmt.Errorf("erro ao gravar os segredos: %w", err)
+	}
+	if _, err := file.WriteString(b.String()); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", fmt.Errorf("erro ao gravar os segredos: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("erro ao gravar os segredos: %w", err)
+	}
+	return file.Name(), nil
+}
+
+// passwdLines converte um segredo nas linhas do "passwd-file". Os pares de
+// vpn.secrets ("chave=valor, ...") vão um por linha, como
+// vpn.secrets.<chave>, e as chaves compartilhadas dos peers, como
+// wireguard-peer.<chave-pública>.preshared-key.
+func passwdLines(key, value string) ([]string, error) {
+	switch key {
+	case "vpn.secrets":
+		var lines []string
+		for _, pair := range strings.Split(value, ",") {
+			name, secret, ok := strings.Cut(pair, "=")
+			if !ok || strings.TrimSpace(name) == "" {
+				return nil, fmt.Errorf("vpn.secrets inválido: use chave=valor")
+			}
+			lines = append(lines, "vpn.secrets."+strings.TrimSpace(name)+":"+strings.TrimSpace(secret))
+		}
+		return lines, nil
+	case peersKey:
+	default:
+		return []string{key + ":" + value}, nil
+	}
+	peers, err := ParseWireGuardPeers(value)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, peer := range peers {
+		if peer.PresharedKey != "" {
+			lines = append(lines, "wireguard-peer."+peer.PublicKey+".preshared-key:"+peer.PresharedKey)
+		}
+	}
+	return lines, nil
+}
+
+// Saída de "nmcli connection clone": "'X' (uuid) cloned as 'Y' (uuid)."
+var clonedUUIDRegex = regexp.MustCompile(`cloned as .*\(([0-9a-fA-F-]{36})\)`)
+
+// CloneProfile copia o perfil, com os segredos, usando "nmcli connection clone"
+func (n *NetworkManager) CloneProfile(id, name string) (Profile, error) {
+	out, err := run("nmcli", "connection", "clone", id, name)
+	if err != nil {
+		return Profile{}, nmcliError("copiar perfil", id, err)
+	}
+	if m := clonedUUIDRegex.FindStringSubmatch(out); m != nil {
+		return n.Profile(m[1])
+	}
+	return n.Profile(name)
+}
+
+// DeleteProfile remove um perfil
+func (n *NetworkManager) DeleteProfile(id string) error {
+	if _, err := run("nmcli", "connection", "delete", id); err != nil {
+		return nmcliError("remover perfil", id, err)
+	}
+	return nil
+}
+
+// Activate ativa um perfil, entregando os segredos pendentes pelo
+// passwd-file. O NetworkManager salva no perfil os segredos recebidos.
+func (n *NetworkManager) Activate(id string) error {
+	args := []string{"connection", "up", id}
+	if secrets := n.pendingSecrets(id); len(secrets) > 0 {
+		path, err := writePasswdFile(secrets)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(path)
+		args = append(args, "passwd-file", path)
+	}
+
+	if _, err := run("nmcli", args...); err != nil {
+		return nmcliError("ativar perfil", id, err)
+	}
+
+	n.mu.Lock()
+	delete(n.secrets, id)
+	n.mu.Unlock()
+	return nil
+}
+
+// Deactivate desativa um perfil
+func (n *NetworkManager) Deactivate(id string) error {
+	if _, err := run("nmcli", "connection", "down", id); err != nil {
+		err = nmcliError("desativar perfil", id, err)
+		// O nmcli usa o mesmo código de saída para perfil inexistente e para
+		// perfil que não está ativo
+		if errors.Is(err, ErrNotFound) {
+			if _, profileErr := n.Profile(id); profileErr == nil {
+				return fmt.Errorf("perfil %s: %w", id, ErrNotActive)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// Scan lista as redes Wi-Fi visíveis
+func (n *NetworkManager) Scan() ([]AccessPoint, error) {
+	out, err := run("nmcli", "-t", "-e", "yes", "-f", "SSID,BSSID,SIGNAL,SECURITY,FREQ,CHAN", "device", "wifi", "list")
+	if err != nil {
+		return nil, fmt.Errorf("erro na varredura Wi-Fi: %w", err)
+	}
+
+	var aps []AccessPoint
+	for _, line := range strings.Split(out, "\n") {
+		fields := splitTerse(line)
+		if len(fields) < 6 {
+			continue
+		}
+		signal, _ := strconv.Atoi(fields[2])
+		security := nmcliSecurity(fields[3])
+		freq, _ := strconv.Atoi(strings.TrimSuffix(fields[4], " MHz"))
+		channel, _ := strconv.Atoi(fields[5])
+		aps = append(aps, AccessPoint{
+			SSID:      fields[0],
+			BSSID:     fields[1],
+			Signal:    signal,
+			DBm:       qualityToDBm(signal),
+			Frequency: freq,
+			Channel:   channel,
+			Secured:   security != SecurityOpen,
+			Security:  security,
+		})
+	}
+	return aps, nil
+}
+
+// Rescan pede uma nova varredura com "nmcli device wifi rescan"; o resultado
+// aparece nas varreduras seguintes
+func (n *NetworkManager) Rescan(device string) error {
+	args := []string{"device", "wifi", "rescan"}
+	if device != "" {
+		args = append(args, "ifname", device)
+	}
+	if _, err := run("nmcli", args...); err != nil {
+		return fmt.Errorf("erro ao pedir varredura Wi-Fi: %w", err)
+	}
+	return nil
+}
+
+// ConnectWiFi conecta com "nmcli device wifi connect", que cria o perfil da
+// rede com conexão automática
+func (n *NetworkManager) ConnectWiFi(device, ssid string, hidden bool) (Profile, error) {
+	args := []string{"device", "wifi", "connect", ssid}
+	if hidden {
+		args = append(args, "hidden", "yes")
+	}
+	if device != "" {
+		args = append(args, "ifname", device)
+	}
+	out, err := run("nmcli", args...)
+	if err != nil {
+		return Profile{}, fmt.Errorf("erro ao conectar em %s: %w", ssid, err)
+	}
+
+	// Saída: "Device 'wlan0' successfully activated with '5b1f7a56-...'."
+	if m := activatedUUIDRegex.FindStringSubmatch(out); m != nil {
+		return n.Profile(m[1])
+	}
+	return n.Profile(ssid)
+}
+
+// Plan descreve os comandos que ModifyProfile e Activate executarão
+func (n *NetworkManager) Plan(id string, settings Settings) []string {
+	plain, secrets := splitSecrets(settings)
+	var commands []string
+	if len(plain) > 0 {
+		args := []string{"nmcli", "connection", "modify", id}
+		for _, key := range plain.Keys() {
+			args = append(args, key, plain[key])
+		}
+		commands = append(commands, shellJoin(args))
+	}
+	up := []string{"nmcli", "connection", "up", id}
+	if len(secrets) > 0 {
+		commands = append(commands, planSaveSecrets(id, secrets))
+		up = append(up, "passwd-file", filepath.Join(runtimeDir, "secrets-*"))
+	}
+	return append(commands, shellJoin(up))
+}
+
+// PlanAdd descreve os comandos que AddProfile executará
+func (n *NetworkManager) PlanAdd(p Profile) []string {
+	plain, secrets := splitSecrets(p.Settings)
+	p.Settings = plain
+	commands := []string{shellJoin(append([]string{"nmcli"}, addArgs(p)...))}
+	if len(secrets) > 0 {
+		commands = append(commands, planSaveSecrets(p.Name, secrets))
+	}
+	return commands
+}
+
+// ImportVPN importa o arquivo com "nmcli connection import", que usa o plugin
+// do NetworkManager para o tipo de VPN
+func (n *NetworkManager) ImportVPN(kind, path string) (Profile, error) {
+	uuid, err := nmcliImport(kind, path)
+	if err != nil {
+		return Profile{}, err
+	}
+	return n.Profile(uuid)
+}
+
+// WireGuardStatus lê o estado dos peers com "wg show"
+func (n *NetworkManager) WireGuardStatus(device string) ([]PeerStatus, error) {
+	return wireGuardStatus(device)
+}
+
+// Watch acompanha as mudanças de estado com "nmcli monitor"
+func (n *NetworkManager) Watch(stop <-chan struct{}) (<-chan struct{}, error) {
+	return watchCommand(stop, "nmcli", "monitor")
+}
+
+// nmcli sai com código 10 quando o perfil ou dispositivo não existe
+const nmcliNotFound = 10
+
+// nmcliError monta o erro de uma operação com o nmcli, usando ErrNotFound
+// quando o perfil não existe
+func nmcliError(action, id string, err error) error {
+	var cmdErr *commandError
+	if errors.As(err, &cmdErr) && cmdErr.code == nmcliNotFound {
+		return fmt.Errorf("erro ao %s %s: %w", action, id, ErrNotFound)
+	}
+	return fmt.Errorf("erro ao %s %s: %w", action, id, err)
+}
+
+// splitTerse divide uma linha da saída "nmcli -t -e yes" nos ":" não
+// escapados, desfazendo os escapes "\:" e "\\"
+func splitTerse(line string) []string {
+	if line == "" {
+		return nil
+	}
+
+	var fields []string
+	var cur strings.Builder
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ':':
+			fields = append(fields, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	return append(fields, cur.String())
+}
+
+// parseTerseRecords interpreta saídas "chave:valor" do nmcli, agrupando
+// chaves indexadas (IP4.ADDRESS[1], IP4.ADDRESS[2]) sob o mesmo nome
+func parseTerseRecords(out string) map[string][]string {
+	records := map[string][]string{}
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		key := parts[0]
+		if i := strings.Index(key, "["); i >= 0 {
+			key = key[:i]
+		}
+
+		if _, ok := records[key]; !ok {
+			records[key] = nil
+		}
+		value := emptyIfDashes(strings.Join(splitTerse(parts[1]), ":"))
+		if value != "" {
+			records[key] = append(records[key], value)
+		}
+	}
+	return records
+}
+
+// emptyIfDashes converte o marcador "--" do nmcli em string vazia
+func emptyIfDashes(s string) string {
+	if s == "--" {
+		return ""
+	}
+	return s
+}
+
+// first retorna o primeiro valor da lista ou string vazia
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -0,0 +1,182 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+
+	"networkmanager-tui/logger"
+)
+
+// Chaves WireGuard válidas para os testes
+const (
+	testPublicKey    = "yAnz5TF+lXXJte14tji3zlMNq+hd2rYUIgJBgB3fBmk="
+	testPresharedKey = "FpCyhws9cxwWoV4xELtfJvjJN+zQVRPISllRWgeopVE="
+)
+
+// fakeNmcli coloca no PATH um nmcli que grava os argumentos de cada chamada
+// em args e a entrada padrão em stdin, e ativa o log de comandos em nível
+// debug. responses é um trecho de shell que imprime a saída de cada chamada
+// (por exemplo, um case "$*"). Retorna o diretório com args, stdin e o log.
+func fakeNmcli(t *testing.T, responses string) string {
+	t.Helper()
+	dir := t.TempDir()
+	script := "#!/bin/sh\n" +
+		"printf '%s\\n' \"$*\" >> " + filepath.Join(dir, "args") + "\n" +
+		"cat >> " + filepath.Join(dir, "stdin") + "\n" +
+		responses + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "nmcli"), []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cfg := logger.DefaultConfig()
+	cfg.Level, cfg.Dir = logger.LevelDebug, filepath.Join(dir, "log")
+	if err := logger.Init(cfg); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(logger.Close)
+	return dir
+}
+
+// readAll lê os arquivos do diretório de fakeNmcli, inclusive os logs
+func readAll(t *testing.T, dir string, names ...string) string {
+	t.Helper()
+	var b strings.Builder
+	for _, name := range names {
+		matches, _ := filepath.Glob(filepath.Join(dir, name))
+		for _, match := range matches {
+			data, err := os.ReadFile(match)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b.Write(data)
+		}
+	}
+	return b.String()
+}
+
+func TestModifyProfileKeepsPresharedKeysOffCommandLine(t *testing.T) {
+	dir := fakeNmcli(t, "")
+	peers := testPublicKey + " allowed-ips=10.0.0.0/24 endpoint=vpn.example.com:51820 preshared-key=" + testPresharedKey
+
+	n := NewNetworkManager()
+	if err := n.ModifyProfile("wg0", Settings{peersKey: peers, "wireguard.listen-port": "51820"}); err != nil {
+		t.Fatal(err)
+	}
+
+	args := readAll(t, dir, "args")
+	if strings.Contains(args, testPresharedKey) {
+		t.Errorf("chave compartilhada na linha de comando do nmcli:\n%s", args)
+	}
+	if !strings.Contains(args, "connection modify wg0 wireguard.listen-port 51820") {
+		t.Errorf("propriedades comuns fora da linha de comando:\n%s", args)
+	}
+	if stdin := readAll(t, dir, "stdin"); !strings.Contains(stdin, "set wireguard.peers "+peers+"\n") {
+		t.Errorf("peers não gravados pelo editor do nmcli:\n%s", stdin)
+	}
+	logger.Close()
+	if logged := readAll(t, dir, "log/*.log"); logged == "" || strings.Contains(logged, testPresharedKey) {
+		t.Errorf("chave compartilhada no log de comandos (ou log vazio):\n%s", logged)
+	}
+	for _, command := range n.Plan("wg0", Settings{peersKey: peers}) {
+		if strings.Contains(command, testPresharedKey) {
+			t.Errorf("chave compartilhada no plano: %s", command)
+		}
+	}
+}
+
+func TestRedactArgsMasksPresharedKeys(t *testing.T) {
+	args := []string{"connection", "modify", "wg0", peersKey,
+		testPublicKey + " preshared-key=" + testPresharedKey + ", " + testPublicKey + " preshared-key=" + testPresharedKey}
+	masked := strings.Join(logger.RedactArgs(args), " ")
+	if strings.Contains(masked, testPresharedKey) || !strings.Contains(masked, testPublicKey) {
+		t.Errorf("RedactArgs = %s", masked)
+	}
+}
+
+func TestPasswdLinesSplitsPeerKeys(t *testing.T) {
+	lines, err := passwdLines(peersKey, testPublicKey+" allowed-ips=10.0.0.0/24 preshared-key="+testPresharedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "wireguard-peer." + testPublicKey + ".preshared-key:" + testPresharedKey
+	if len(lines) != 1 || lines[0] != want {
+		t.Errorf("passwdLines = %q, esperado %q", lines, want)
+	}
+}
+
+func TestModifyProfileKeepsVPNSecretsOffCommandLine(t *testing.T) {
+	dir := fakeNmcli(t, "")
+	n := NewNetworkManager()
+	if err := n.ModifyProfile("vpn0", Settings{"vpn.secrets": "password=segredo-vpn", "vpn.user-name": "ana"}); err != nil {
+		t.Fatal(err)
+	}
+	if args := readAll(t, dir, "args"); strings.Contains(args, "segredo-vpn") || strings.Contains(args, "vpn.secrets") {
+		t.Errorf("vpn.secrets na linha de comando do nmcli:\n%s", args)
+	}
+	if stdin := readAll(t, dir, "stdin"); !strings.Contains(stdin, "set vpn.secrets password=segredo-vpn\n") {
+		t.Errorf("vpn.secrets não gravado pelo editor do nmcli:\n%s", stdin)
+	}
+	lines, err := passwdLines("vpn.secrets", "password = segredo-vpn, cert-pass=outro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(lines, "|") != "vpn.secrets.password:segredo-vpn|vpn.secrets.cert-pass:outro" {
+		t.Errorf("passwdLines = %q", lines)
+	}
+}
+
+// Rota com atributos além de table, aceita pelos dois backends
+const testRoute = "10.20.0.0/16 192.168.1.254 50 table=100 mtu=1400 onlink=true src=192.168.1.100"
+
+func TestRouteAttributesOnBothBackends(t *testing.T) {
+	// D-Bus: route-data com os atributos, e de volta ao formato do nmcli
+	route, err := parseRoute(testRoute)
+	if err != nil {
+		t.Fatalf("parseRoute: %v", err)
+	}
+	if route["mtu"].Value() != uint32(1400) || route["onlink"].Value() != true || route["src"].Value() != "192.168.1.100" {
+		t.Errorf("route-data = %v", route)
+	}
+	_, text, _ := flattenProperty("ipv4", "route-data", dbus.MakeVariant([]map[string]dbus.Variant{route}))
+	if text != testRoute {
+		t.Errorf("rota lida do D-Bus = %q, esperada %q", text, testRoute)
+	}
+
+	// nmcli: a rota segue sem alteração
+	dir := fakeNmcli(t, "")
+	if err := NewNetworkManager().ModifyProfile("eth0", Settings{"ipv4.routes": testRoute}); err != nil {
+		t.Fatal(err)
+	}
+	if args := readAll(t, dir, "args"); !strings.Contains(args, "ipv4.routes "+testRoute) {
+		t.Errorf("argumentos do nmcli:\n%s", args)
+	}
+
+	for _, invalid := range []string{"10.0.0.0/8 foo=1", "10.0.0.0/8 mtu=x", "10.0.0.0/8 onlink=talvez", "10.0.0.0/8 src=abc"} {
+		if _, err := parseRoute(invalid); err == nil {
+			t.Errorf("parseRoute(%q) sem erro", invalid)
+		}
+	}
+}
+
+func TestAddProfileReadsCreatedUUID(t *testing.T) {
+	// Dois perfis "Escritorio": o existente e o criado agora
+	const existing, created = "11111111-1111-4111-8111-111111111111", "22222222-2222-4222-8222-222222222222"
+	fakeNmcli(t, `case "$*" in
+"connection add "*) echo "Connection 'Escritorio' (`+created+`) successfully added." ;;
+*"connection show Escritorio") printf 'connection.id:Escritorio\nconnection.uuid:`+existing+`\nconnection.type:802-11-wireless\n' ;;
+*"connection show `+created+`") printf 'connection.id:Escritorio\nconnection.uuid:`+created+`\nconnection.type:802-11-wireless\n' ;;
+esac`)
+
+	p, err := NewNetworkManager().AddProfile(Profile{Name: "Escritorio", Type: WiFiType, Settings: Settings{"802-11-wireless.ssid": "Escritorio"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.UUID != created {
+		t.Errorf("perfil criado %s, esperado %s", p.UUID, created)
+	}
+}
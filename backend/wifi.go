@@ -0,0 +1,435 @@
+package backend
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// WiFiType é o tipo de perfil das redes Wi-Fi
+const WiFiType = "802-11-wireless"
+
+// Tipos de segurança das redes Wi-Fi; exceto SecurityOpen, são os valores de
+// 802-11-wireless-security.key-mgmt
+const (
+	SecurityOpen = "open"
+	SecurityWEP  = "wep"
+	SecurityPSK  = "wpa-psk"
+	SecuritySAE  = "sae"
+	SecurityEAP  = "wpa-eap"
+)
+
+// SecurityLabel retorna o nome exibido do tipo de segurança
+func SecurityLabel(security string) string {
+	switch security {
+	case SecurityWEP:
+		return "WEP"
+	case SecurityPSK:
+		return "WPA2-PSK"
+	case SecuritySAE:
+		return "WPA3-SAE"
+	case SecurityEAP:
+		return "802.1X"
+	}
+	return "Open"
+}
+
+// UUID do perfil na saída de "nmcli device wifi connect"
+var activatedUUIDRegex = regexp.MustCompile(`'([0-9a-fA-F-]{36})'`)
+
+// WiFiDevice retorna o primeiro dispositivo Wi-Fi do backend
+func WiFiDevice(b Backend) (string, error) {
+	devices, err := b.Devices()
+	if err != nil {
+		return "", fmt.Errorf("erro ao listar dispositivos: %w", err)
+	}
+	for _, dev := range devices {
+		if dev.Type == "wifi" {
+			return dev.Name, nil
+		}
+	}
+	return "", fmt.Errorf("dispositivo Wi-Fi: %w", ErrNotFound)
+}
+
+// SavedWiFiProfile procura o perfil salvo da rede, comparando o SSID do perfil
+// ou, na falta dele, o nome
+func SavedWiFiProfile(b Backend, ssid string) (Profile, error) {
+	profiles, err := b.Profiles()
+	if err != nil {
+		return Profile{}, err
+	}
+
+	var byName *Profile
+	for i, p := range profiles {
+		if p.Type != WiFiType {
+			continue
+		}
+		full, err := b.Profile(p.ID())
+		if err != nil {
+			continue
+		}
+		if full.Settings["802-11-wireless.ssid"] == ssid {
+			return full, nil
+		}
+		if byName == nil && p.Name == ssid {
+			byName = &profiles[i]
+		}
+	}
+	if byName != nil {
+		return b.Profile(byName.ID())
+	}
+	return Profile{}, fmt.Errorf("perfil Wi-Fi %s: %w", ssid, ErrNotFound)
+}
+
+// ConnectWiFi conecta o dispositivo à rede Wi-Fi com as propriedades de
+// segurança informadas (WiFiSecurity, mais as 802-1x.* no WPA-Enterprise e
+// 802-11-wireless.hidden nas redes ocultas). O perfil salvo da rede é
+// reaproveitado, com a segurança atualizada; sem perfil, um novo é criado com
+// conexão automática, pelo próprio NetworkManager quando o backend implementa
+// WiFi e a rede usa senha. Se device estiver vazio, usa o primeiro
+// dispositivo Wi-Fi.
+func ConnectWiFi(b Backend, device, ssid string, security Settings) (Profile, error) {
+	if device == "" {
+		var err error
+		if device, err = WiFiDevice(b); err != nil {
+			return Profile{}, err
+		}
+	}
+
+	profile, err := SavedWiFiProfile(b, ssid)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		if w, ok := b.(WiFi); ok && security["802-11-wireless-security.key-mgmt"] == "" {
+			return w.ConnectWiFi(device, ssid, security["802-11-wireless.hidden"] == "yes")
+		}
+		settings := Settings{}
+		for key, value := range security {
+			// Propriedades vazias só fazem sentido ao alterar um perfil
+			if value != "" {
+				settings[key] = value
+			}
+		}
+		settings["802-11-wireless.ssid"] = ssid
+		settings["connection.autoconnect"] = "yes"
+		profile, err = b.AddProfile(Profile{Name: ssid, Type: WiFiType, Device: device, Settings: settings})
+		if err != nil {
+			return Profile{}, fmt.Errorf("erro ao criar perfil Wi-Fi: %w", err)
+		}
+	case err != nil:
+		return Profile{}, err
+	case len(security) > 0:
+		if err := b.ModifyProfile(profile.ID(), security); err != nil {
+			return Profile{}, fmt.Errorf("erro ao atualizar a segurança Wi-Fi: %w", err)
+		}
+	}
+
+	if err := b.Activate(profile.ID()); err != nil {
+		return Profile{}, fmt.Errorf("erro ao conectar em %s: %w", ssid, err)
+	}
+	return profile, nil
+}
+
+// WiFiSecurity retorna as propriedades de segurança da rede para o tipo
+// informado. Sem senha, WEP e WPA pessoal não alteram a segurança (o perfil
+// salvo mantém a atual); no WPA-Enterprise a senha fica nas 802-1x.*.
+func WiFiSecurity(security, password string) Settings {
+	switch security {
+	case SecurityOpen:
+		return Settings{}
+	case SecurityEAP:
+		return Settings{"802-11-wireless-security.key-mgmt": SecurityEAP}
+	}
+	if password == "" {
+		return Settings{}
+	}
+	switch security {
+	case SecurityWEP:
+		return Settings{
+			"802-11-wireless-security.key-mgmt":     "none",
+			"802-11-wireless-security.wep-key-type": "1",
+			"802-11-wireless-security.wep-key0":     password,
+		}
+	case SecuritySAE:
+		return Settings{
+			"802-11-wireless-security.key-mgmt": SecuritySAE,
+			"802-11-wireless-security.psk":      password,
+		}
+	}
+	return Settings{
+		"802-11-wireless-security.key-mgmt": SecurityPSK,
+		"802-11-wireless-security.psk":      password,
+	}
+}
+
+// nmcliSecurity deduz o tipo de segurança da coluna SECURITY do nmcli
+// ("WPA1 WPA2", "WPA2 802.1X", "WPA3", "WEP", vazio...). Redes em modo de
+// transição WPA2/WPA3 são tratadas como WPA2-PSK.
+func nmcliSecurity(field string) string {
+	switch {
+	case strings.Contains(field, "802.1X"):
+		return SecurityEAP
+	case strings.Contains(field, "WPA1"), strings.Contains(field, "WPA2"):
+		return SecurityPSK
+	case strings.Contains(field, "WPA3"):
+		return SecuritySAE
+	case strings.Contains(field, "WEP"):
+		return SecurityWEP
+	}
+	return SecurityOpen
+}
+
+// Bits de NM80211ApSecurityFlags e NM80211ApFlags usados por apSecurity
+const (
+	apFlagPrivacy     = 0x1
+	apSecKeyMgmtPSK   = 0x100
+	apSecKeyMgmt8021X = 0x200
+	apSecKeyMgmtSAE   = 0x400
+)
+
+// apSecurity deduz o tipo de segurança das propriedades Flags, WpaFlags e
+// RsnFlags do ponto de acesso
+func apSecurity(flags, wpaFlags, rsnFlags uint32) string {
+	sec := wpaFlags | rsnFlags
+	switch {
+	case sec&apSecKeyMgmt8021X != 0:
+		return SecurityEAP
+	case sec&apSecKeyMgmtPSK != 0:
+		return SecurityPSK
+	case sec&apSecKeyMgmtSAE != 0:
+		return SecuritySAE
+	case sec == 0 && flags&apFlagPrivacy != 0:
+		return SecurityWEP
+	case sec != 0:
+		return SecurityPSK
+	}
+	return SecurityOpen
+}
+
+// Expressões usadas na leitura da saída de "iwlist scan"
+var (
+	iwlistSSIDRegex    = regexp.MustCompile(`ESSID:"([^"]*)"`)
+	iwlistBSSIDRegex   = regexp.MustCompile(`Address: ([0-9A-Fa-f:]{17})`)
+	iwlistDBmRegex     = regexp.MustCompile(`Signal level=(-\d+) dBm`)
+	iwlistQualityRegex = regexp.MustCompile(`Quality=(\d+)/(\d+)`)
+	iwlistKeyRegex     = regexp.MustCompile(`Encryption key:(\w+)`)
+	iwlistAuthRegex    = regexp.MustCompile(`Authentication Suites \(\d+\) : (.+)`)
+	iwlistFreqRegex    = regexp.MustCompile(`Frequency:([\d.]+) GHz`)
+	iwlistChannelRegex = regexp.MustCompile(`Channel[: ](\d+)`)
+)
+
+// parseIwlistScan converte a saída de "iwlist scan" em pontos de acesso,
+// ignorando redes ocultas
+func parseIwlistScan(out string) []AccessPoint {
+	var aps []AccessPoint
+	cells := strings.Split(out, "Cell ")
+	for _, cell := range cells[1:] {
+		ssid := iwlistSSIDRegex.FindStringSubmatch(cell)
+		bssid := iwlistBSSIDRegex.FindStringSubmatch(cell)
+		if ssid == nil || ssid[1] == "" || bssid == nil {
+			continue
+		}
+
+		ap := AccessPoint{SSID: ssid[1], BSSID: strings.ToUpper(bssid[1]), Signal: 60}
+		if m := iwlistQualityRegex.FindStringSubmatch(cell); m != nil {
+			quality, _ := strconv.Atoi(m[1])
+			max, _ := strconv.Atoi(m[2])
+			if max > 0 {
+				ap.Signal = quality * 100 / max
+			}
+		} else if m := iwlistDBmRegex.FindStringSubmatch(cell); m != nil {
+			dbm, _ := strconv.Atoi(m[1])
+			ap.Signal = dBmToQuality(dbm)
+		}
+		ap.DBm = qualityToDBm(ap.Signal)
+		if m := iwlistDBmRegex.FindStringSubmatch(cell); m != nil {
+			ap.DBm, _ = strconv.Atoi(m[1])
+		}
+		if m := iwlistFreqRegex.FindStringSubmatch(cell); m != nil {
+			ghz, _ := strconv.ParseFloat(m[1], 64)
+			ap.Frequency = int(ghz*1000 + 0.5)
+		}
+		ap.Channel = FrequencyChannel(ap.Frequency)
+		if m := iwlistChannelRegex.FindStringSubmatch(cell); m != nil && ap.Channel == 0 {
+			ap.Channel, _ = strconv.Atoi(m[1])
+		}
+		ap.Security = iwlistSecurity(cell)
+		ap.Secured = ap.Security != SecurityOpen
+		aps = append(aps, ap)
+	}
+	return aps
+}
+
+// iwlistSecurity deduz o tipo de segurança de uma célula do "iwlist scan"
+// pelos elementos WPA/RSN anunciados; sem eles, a chave ligada indica WEP
+func iwlistSecurity(cell string) string {
+	var auth string
+	for _, m := range iwlistAuthRegex.FindAllStringSubmatch(cell, -1) {
+		auth += " " + m[1]
+	}
+	switch {
+	case strings.Contains(auth, "802.1x"):
+		return SecurityEAP
+	case strings.Contains(auth, "PSK"):
+		return SecurityPSK
+	case strings.Contains(auth, "SAE"):
+		return SecuritySAE
+	case strings.Contains(cell, "WPA"):
+		return SecurityPSK
+	}
+	if m := iwlistKeyRegex.FindStringSubmatch(cell); m != nil && m[1] == "on" {
+		return SecurityWEP
+	}
+	return SecurityOpen
+}
+
+// qualityToDBm estima o nível do sinal pela qualidade de 0 a 100, invertendo
+// a escala de dBmToQuality (a mesma usada pelo NetworkManager)
+func qualityToDBm(quality int) int {
+	return quality/2 - 100
+}
+
+// FrequencyChannel retorna o canal Wi-Fi da frequência em MHz, ou 0 se a
+// frequência não for de uma faixa conhecida
+func FrequencyChannel(freq int) int {
+	switch {
+	case freq == 2484:
+		return 14
+	case freq >= 2412 && freq < 2484:
+		return (freq - 2407) / 5
+	case freq >= 5955 && freq <= 7115:
+		return (freq - 5950) / 5
+	case freq >= 5160 && freq <= 5885:
+		return (freq - 5000) / 5
+	}
+	return 0
+}
+
+// FrequencyBand retorna a faixa da frequência em MHz ("2.4 GHz", "5 GHz" ou
+// "6 GHz"), ou vazio se desconhecida
+func FrequencyBand(freq int) string {
+	switch {
+	case freq >= 2400 && freq < 2500:
+		return "2.4 GHz"
+	case freq >= 5150 && freq < 5925:
+		return "5 GHz"
+	case freq >= 5925 && freq <= 7125:
+		return "6 GHz"
+	}
+	return ""
+}
+
+// dBmToQuality converte o nível de sinal em dBm para a escala de 0 a 100,
+// considerando -100 dBm como 0 e -50 dBm como 100
+func dBmToQuality(dbm int) int {
+	switch {
+	case dbm <= -100:
+		return 0
+	case dbm >= -50:
+		return 100
+	}
+	return 2 * (dbm + 100)
+}
+
+// wpaSupplicantSteps descreve a conexão do perfil com o wpa_supplicant,
+// encerrando a instância anterior do dispositivo: redes Wi-Fi ou portas
+// cabeadas com autenticação 802.1X. A configuração é lida do arquivo gravado
+// por writeWPAConfig.
+func wpaSupplicantSteps(p Profile) []step {
+	dev := p.Device
+	args := []string{"wpa_supplicant", "-B", "-i", dev, "-c", wpaConfigPath(dev)}
+	if p.Type != WiFiType {
+		args = append(args, "-D", "wired")
+	}
+	return []step{
+		{args: []string{"wpa_cli", "-i", dev, "terminate"}, optional: true},
+		{args: args},
+	}
+}
+
+// usesWPASupplicant informa se a ativação do perfil no backend iproute2
+// depende do wpa_supplicant
+func usesWPASupplicant(p Profile) bool {
+	return p.Type == WiFiType || p.Settings["802-1x.eap"] != ""
+}
+
+// Propriedades 802-1x.* e os campos correspondentes do wpa_supplicant
+var wpaEAPFields = []struct{ key, field string }{
+	{"802-1x.identity", "identity"},
+	{"802-1x.anonymous-identity", "anonymous_identity"},
+	{"802-1x.password", "password"},
+	{"802-1x.ca-cert", "ca_cert"},
+	{"802-1x.client-cert", "client_cert"},
+	{"802-1x.private-key", "private_key"},
+	{"802-1x.private-key-password", "private_key_passwd"},
+}
+
+// writeWPAConfig grava a configuração do wpa_supplicant do perfil, legível
+// apenas pelo root. O SSID é gravado em hexadecimal para dispensar escape.
+func writeWPAConfig(p Profile) error {
+	for key, value := range p.Settings {
+		if strings.Contains(value, "\n") && (strings.HasPrefix(key, "802-11-wireless") || strings.HasPrefix(key, "802-1x.")) {
+			return fmt.Errorf("a propriedade %s não pode conter quebras de linha", key)
+		}
+	}
+	if err := os.MkdirAll(runtimeDir, 0700); err != nil {
+		return fmt.Errorf("erro ao criar %s: %w", runtimeDir, err)
+	}
+
+	s := p.Settings
+	var b strings.Builder
+	fmt.Fprintf(&b, "ctrl_interface=/run/wpa_supplicant\n")
+	if p.Type != WiFiType {
+		fmt.Fprintf(&b, "ap_scan=0\n")
+	}
+	fmt.Fprintf(&b, "\nnetwork={\n")
+	if p.Type == WiFiType {
+		fmt.Fprintf(&b, "\tssid=%s\n", hex.EncodeToString([]byte(s["802-11-wireless.ssid"])))
+		if s["802-11-wireless.hidden"] == "yes" {
+			// Redes ocultas só respondem a varreduras pelo SSID
+			fmt.Fprintf(&b, "\tscan_ssid=1\n")
+		}
+	}
+
+	switch keyMgmt := s["802-11-wireless-security.key-mgmt"]; {
+	case keyMgmt == SecurityPSK:
+		fmt.Fprintf(&b, "\tpsk=\"%s\"\n", s["802-11-wireless-security.psk"])
+	case keyMgmt == SecuritySAE:
+		fmt.Fprintf(&b, "\tkey_mgmt=SAE\n\tieee80211w=2\n\tsae_password=\"%s\"\n", s["802-11-wireless-security.psk"])
+	case keyMgmt == SecurityEAP || (p.Type != WiFiType && s["802-1x.eap"] != ""):
+		if keyMgmt == SecurityEAP {
+			fmt.Fprintf(&b, "\tkey_mgmt=WPA-EAP\n")
+		} else {
+			fmt.Fprintf(&b, "\tkey_mgmt=IEEE8021X\n")
+		}
+		fmt.Fprintf(&b, "\teap=%s\n", strings.ToUpper(strings.ReplaceAll(s["802-1x.eap"], ",", " ")))
+		for _, f := range wpaEAPFields {
+			if value := s[f.key]; value != "" {
+				fmt.Fprintf(&b, "\t%s=\"%s\"\n", f.field, value)
+			}
+		}
+		if phase2 := s["802-1x.phase2-auth"]; phase2 != "" {
+			fmt.Fprintf(&b, "\tphase2=\"auth=%s\"\n", strings.ToUpper(phase2))
+		}
+	case s["802-11-wireless-security.wep-key0"] != "":
+		fmt.Fprintf(&b, "\tkey_mgmt=NONE\n\twep_key0=\"%s\"\n\twep_tx_keyidx=0\n", s["802-11-wireless-security.wep-key0"])
+	default:
+		fmt.Fprintf(&b, "\tkey_mgmt=NONE\n")
+	}
+	fmt.Fprintf(&b, "}\n")
+
+	if err := os.WriteFile(wpaConfigPath(p.Device), []byte(b.String()), 0600); err != nil {
+		return fmt.Errorf("erro ao gravar configuração do wpa_supplicant: %w", err)
+	}
+	return nil
+}
+
+// wpaConfigPath retorna o arquivo de configuração do wpa_supplicant do
+// dispositivo
+func wpaConfigPath(dev string) string {
+	return filepath.Join(runtimeDir, "wpa_supplicant-"+dev+".conf")
+}
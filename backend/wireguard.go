@@ -0,0 +1,418 @@
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tipos de VPN aceitos na importação
+const (
+	VPNWireGuard = "wireguard"
+	VPNOpenVPN   = "openvpn"
+)
+
+// Serviço do plugin OpenVPN do NetworkManager (propriedade vpn.service-type)
+const openVPNService = "org.freedesktop.NetworkManager.openvpn"
+
+// WireGuardPeer é um peer de um perfil WireGuard
+type WireGuardPeer struct {
+	PublicKey           string   `json:"public_key" yaml:"public_key"`
+	PresharedKey        string   `json:"-" yaml:"-"`
+	Endpoint            string   `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	AllowedIPs          []string `json:"allowed_ips" yaml:"allowed_ips"`
+	PersistentKeepalive int      `json:"persistent_keepalive,omitempty" yaml:"persistent_keepalive,omitempty"`
+}
+
+// PeerStatus é o estado de um peer WireGuard no kernel
+type PeerStatus struct {
+	PublicKey       string    `json:"public_key" yaml:"public_key"`
+	Endpoint        string    `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	AllowedIPs      []string  `json:"allowed_ips" yaml:"allowed_ips"`
+	LatestHandshake time.Time `json:"latest_handshake" yaml:"latest_handshake"` // Zero se nunca houve
+	RxBytes         int64     `json:"rx_bytes" yaml:"rx_bytes"`
+	TxBytes         int64     `json:"tx_bytes" yaml:"tx_bytes"`
+}
+
+// Chave WireGuard: 32 bytes em base64
+var wireGuardKeyRegex = regexp.MustCompile(`^[A-Za-z0-9+/]{42}[AEIMQUYcgkosw480]=$`)
+
+// ValidWireGuardKey informa se a chave tem o formato de uma chave WireGuard
+func ValidWireGuardKey(key string) bool {
+	return wireGuardKeyRegex.MatchString(key)
+}
+
+// ParseWireGuardPeers interpreta a propriedade wireguard.peers no formato
+// "<chave-pública> allowed-ips=A;B endpoint=host:porta persistent-keepalive=N
+// preshared-key=K", com os peers separados por vírgula
+func ParseWireGuardPeers(value string) ([]WireGuardPeer, error) {
+	var peers []WireGuardPeer
+	for _, item := range strings.Split(value, ",") {
+		fields := strings.Fields(item)
+		if len(fields) == 0 {
+			continue
+		}
+		if !ValidWireGuardKey(fields[0]) {
+			return nil, fmt.Errorf("chave pública inválida: %s", fields[0])
+		}
+
+		peer := WireGuardPeer{PublicKey: fields[0]}
+		for _, field := range fields[1:] {
+			key, v, ok := strings.Cut(field, "=")
+			if !ok {
+				return nil, fmt.Errorf("atributo de peer inválido: %s", field)
+			}
+			switch key {
+			case "allowed-ips":
+				for _, ip := range strings.Split(v, ";") {
+					if ip = strings.TrimSpace(ip); ip != "" {
+						peer.AllowedIPs = append(peer.AllowedIPs, ip)
+					}
+				}
+			case "endpoint":
+				peer.Endpoint = v
+			case "persistent-keepalive":
+				n, err := strconv.Atoi(v)
+				if err != nil || n < 0 || n > 65535 {
+					return nil, fmt.Errorf("persistent-keepalive inválido: %s", v)
+				}
+				peer.PersistentKeepalive = n
+			case "preshared-key":
+				peer.PresharedKey = v
+			default:
+				return nil, fmt.Errorf("atributo de peer desconhecido: %s", key)
+			}
+		}
+		peers = append(peers, peer)
+	}
+	return peers, nil
+}
+
+// FormatWireGuardPeers converte os peers na propriedade wireguard.peers
+func FormatWireGuardPeers(peers []WireGuardPeer) string {
+	items := make([]string, len(peers))
+	for i, peer := range peers {
+		items[i] = formatPeer(peer)
+	}
+	return strings.Join(items, ", ")
+}
+
+// formatPeer converte um peer no formato de ParseWireGuardPeers
+func formatPeer(peer WireGuardPeer) string {
+	fields := []string{peer.PublicKey}
+	if len(peer.AllowedIPs) > 0 {
+		fields = append(fields, "allowed-ips="+strings.Join(peer.AllowedIPs, ";"))
+	}
+	if peer.Endpoint != "" {
+		fields = append(fields, "endpoint="+peer.Endpoint)
+	}
+	if peer.PersistentKeepalive > 0 {
+		fields = append(fields, "persistent-keepalive="+strconv.Itoa(peer.PersistentKeepalive))
+	}
+	if peer.PresharedKey != "" {
+		fields = append(fields, "preshared-key="+peer.PresharedKey)
+	}
+	return strings.Join(fields, " ")
+}
+
+// ParseWireGuardConf converte um arquivo no formato do wg-quick em perfil. O
+// nome do perfil e da interface é o nome do arquivo sem a extensão.
+func ParseWireGuardConf(path string, data []byte) (Profile, error) {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	p := Profile{
+		Name:   name,
+		Type:   VPNWireGuard,
+		Device: name,
+		Settings: Settings{
+			"ipv4.method": "disabled",
+			"ipv6.method": "disabled",
+		},
+	}
+
+	var peers []WireGuardPeer
+	var addr4, addr6, dns4, dns6, search []string
+	section := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = strings.TrimSpace(text[:i])
+		}
+		if text == "" {
+			continue
+		}
+		if strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]") {
+			section = strings.ToLower(strings.Trim(text, "[]"))
+			if section == "peer" {
+				peers = append(peers, WireGuardPeer{})
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return Profile{}, fmt.Errorf("%s:%d: linha inválida", path, line)
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		switch section {
+		case "interface":
+			switch key {
+			case "privatekey":
+				p.Settings["wireguard.private-key"] = value
+			case "listenport":
+				p.Settings["wireguard.listen-port"] = value
+			case "mtu":
+				p.Settings["wireguard.mtu"] = value
+			case "address":
+				for _, addr := range splitList(value) {
+					if ip, _, err := net.ParseCIDR(addr); err == nil && ip.To4() == nil {
+						addr6 = append(addr6, addr)
+					} else {
+						addr4 = append(addr4, addr)
+					}
+				}
+			case "dns":
+				for _, server := range splitList(value) {
+					ip := net.ParseIP(server)
+					switch {
+					case ip == nil:
+						search = append(search, server)
+					case ip.To4() == nil:
+						dns6 = append(dns6, server)
+					default:
+						dns4 = append(dns4, server)
+					}
+				}
+			default:
+				// PostUp, Table, SaveConfig... são específicos do wg-quick
+			}
+
+		case "peer":
+			peer := &peers[len(peers)-1]
+			switch key {
+			case "publickey":
+				peer.PublicKey = value
+			case "presharedkey":
+				peer.PresharedKey = value
+			case "endpoint":
+				peer.Endpoint = value
+			case "allowedips":
+				peer.AllowedIPs = splitList(value)
+			case "persistentkeepalive":
+				n, err := strconv.Atoi(value)
+				if err != nil {
+					return Profile{}, fmt.Errorf("%s:%d: PersistentKeepalive inválido", path, line)
+				}
+				peer.PersistentKeepalive = n
+			}
+
+		default:
+			return Profile{}, fmt.Errorf("%s:%d: propriedade fora de seção", path, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Profile{}, err
+	}
+
+	if !ValidWireGuardKey(p.Settings["wireguard.private-key"]) {
+		return Profile{}, fmt.Errorf("%s: PrivateKey ausente ou inválida", path)
+	}
+	for _, peer := range peers {
+		if !ValidWireGuardKey(peer.PublicKey) {
+			return Profile{}, fmt.Errorf("%s: PublicKey de peer ausente ou inválida", path)
+		}
+	}
+	p.Settings["wireguard.peers"] = FormatWireGuardPeers(peers)
+
+	if len(addr4) > 0 {
+		p.Settings["ipv4.method"] = "manual"
+		p.Settings["ipv4.addresses"] = strings.Join(addr4, ",")
+	}
+	if len(addr6) > 0 {
+		p.Settings["ipv6.method"] = "manual"
+		p.Settings["ipv6.addresses"] = strings.Join(addr6, ",")
+	}
+	for key, values := range map[string][]string{"ipv4.dns": dns4, "ipv6.dns": dns6, "ipv4.dns-search": search} {
+		if len(values) > 0 {
+			p.Settings[key] = strings.Join(values, ",")
+		}
+	}
+	return p, nil
+}
+
+// parseOpenVPNConf lê as opções principais de um arquivo .ovpn, para os
+// backends que não contam com o plugin de importação do NetworkManager
+func parseOpenVPNConf(path string, data []byte) (Profile, error) {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	options := []string{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "remote":
+			remote := fields[1]
+			if len(fields) > 2 {
+				remote += ":" + fields[2]
+			}
+			options = append(options, "remote = "+remote)
+		case "dev":
+			options = append(options, "dev = "+fields[1])
+		case "proto":
+			options = append(options, "proto-tcp = "+strconv.FormatBool(strings.HasPrefix(fields[1], "tcp")))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Profile{}, err
+	}
+	if !strings.Contains(strings.Join(options, ","), "remote = ") {
+		return Profile{}, fmt.Errorf("%s: diretiva remote ausente", path)
+	}
+
+	return Profile{
+		Name: name,
+		Type: "vpn",
+		Settings: Settings{
+			"vpn.service-type": openVPNService,
+			"vpn.data":         strings.Join(options, ", "),
+		},
+	}, nil
+}
+
+// VPNKind retorna o tipo de VPN do perfil (wireguard ou openvpn), ou vazio
+// se o perfil não for de VPN
+func VPNKind(p Profile) string {
+	switch {
+	case p.Type == VPNWireGuard:
+		return VPNWireGuard
+	case p.Type == "vpn" && strings.HasSuffix(p.Settings["vpn.service-type"], "openvpn"):
+		return VPNOpenVPN
+	case p.Type == "vpn":
+		return p.Type
+	}
+	return ""
+}
+
+// nmcliImport importa o arquivo com "nmcli connection import" e retorna o
+// UUID do perfil criado
+func nmcliImport(kind, path string) (string, error) {
+	out, err := run("nmcli", "connection", "import", "type", kind, "file", path)
+	if err != nil {
+		return "", fmt.Errorf("erro ao importar %s: %w", path, err)
+	}
+
+	// Saída: "Connection 'wg0' (5b1f7a56-...) successfully added."
+	if m := addedUUIDRegex.FindStringSubmatch(out); m != nil {
+		return m[1], nil
+	}
+	return "", fmt.Errorf("erro ao importar %s: saída inesperada do nmcli: %s", path, strings.TrimSpace(out))
+}
+
+// wireGuardStatus lê o estado dos peers do dispositivo com "wg show dump"
+func wireGuardStatus(device string) ([]PeerStatus, error) {
+	out, err := run("wg", "show", device, "dump")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar o túnel %s: %w", device, err)
+	}
+
+	var peers []PeerStatus
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	// A primeira linha descreve a interface; as demais, um peer cada:
+	// chave-pública chave-compartilhada endpoint allowed-ips handshake rx tx keepalive
+	for _, line := range lines[1:] {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 7 {
+			continue
+		}
+		peer := PeerStatus{PublicKey: fields[0]}
+		if fields[2] != "(none)" {
+			peer.Endpoint = fields[2]
+		}
+		if fields[3] != "(none)" {
+			peer.AllowedIPs = strings.Split(fields[3], ",")
+		}
+		if ts, _ := strconv.ParseInt(fields[4], 10, 64); ts > 0 {
+			peer.LatestHandshake = time.Unix(ts, 0)
+		}
+		peer.RxBytes, _ = strconv.ParseInt(fields[5], 10, 64)
+		peer.TxBytes, _ = strconv.ParseInt(fields[6], 10, 64)
+		peers = append(peers, peer)
+	}
+	return peers, nil
+}
+
+// wireGuardSteps configura chave e peers do dispositivo WireGuard com o
+// comando wg. As chaves são lidas dos arquivos gravados por writeWireGuardKeys.
+func wireGuardSteps(p Profile) []step {
+	dev := p.Device
+	steps := []step{{args: []string{"ip", "link", "add", "dev", dev, "type", "wireguard"}, optional: true}}
+
+	args := []string{"wg", "set", dev, "private-key", wireGuardKeyPath(dev, "")}
+	if port := p.Settings["wireguard.listen-port"]; port != "" && port != "0" {
+		args = append(args, "listen-port", port)
+	}
+	steps = append(steps, step{args: args})
+	if mtu := p.Settings["wireguard.mtu"]; mtu != "" && mtu != "0" {
+		steps = append(steps, step{args: []string{"ip", "link", "set", "dev", dev, "mtu", mtu}})
+	}
+
+	peers, _ := ParseWireGuardPeers(p.Settings["wireguard.peers"])
+	for _, peer := range peers {
+		args := []string{"wg", "set", dev, "peer", peer.PublicKey,
+			"allowed-ips", strings.Join(peer.AllowedIPs, ",")}
+		if peer.Endpoint != "" {
+			args = append(args, "endpoint", peer.Endpoint)
+		}
+		if peer.PersistentKeepalive > 0 {
+			args = append(args, "persistent-keepalive", strconv.Itoa(peer.PersistentKeepalive))
+		}
+		if peer.PresharedKey != "" {
+			args = append(args, "preshared-key", wireGuardKeyPath(dev, peer.PublicKey))
+		}
+		steps = append(steps, step{args: args})
+	}
+	return steps
+}
+
+// writeWireGuardKeys grava a chave privada e as chaves compartilhadas do
+// perfil em arquivos 0600, já que o wg só as lê de arquivos
+func writeWireGuardKeys(p Profile) error {
+	if err := os.MkdirAll(runtimeDir, 0700); err != nil {
+		return fmt.Errorf("erro ao criar %s: %w", runtimeDir, err)
+	}
+	keys := map[string]string{wireGuardKeyPath(p.Device, ""): p.Settings["wireguard.private-key"]}
+	peers, _ := ParseWireGuardPeers(p.Settings["wireguard.peers"])
+	for _, peer := range peers {
+		if peer.PresharedKey != "" {
+			keys[wireGuardKeyPath(p.Device, peer.PublicKey)] = peer.PresharedKey
+		}
+	}
+	for path, key := range keys {
+		if err := os.WriteFile(path, []byte(key+"\n"), 0600); err != nil {
+			return fmt.Errorf("erro ao gravar chave WireGuard: %w", err)
+		}
+	}
+	return nil
+}
+
+// wireGuardKeyPath retorna o arquivo da chave privada do dispositivo ou, com
+// peer informado, da chave compartilhada com o peer
+func wireGuardKeyPath(dev, peer string) string {
+	if peer == "" {
+		return filepath.Join(runtimeDir, dev+".key")
+	}
+	// A chave pública em base64 pode conter "/"
+	return filepath.Join(runtimeDir, dev+"-"+strings.NewReplacer("/", "_", "+", "-").Replace(peer)+".psk")
+}
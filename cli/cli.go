@@ -0,0 +1,211 @@
+// Package cli implementa os subcomandos não interativos, para que scripts de
+// provisionamento e comandos via SSH usem a ferramenta sem a interface de
+// terminal.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"networkmanager-tui/backend"
+	"networkmanager-tui/i18n"
+	"networkmanager-tui/network"
+	"networkmanager-tui/safeapply"
+)
+
+// Códigos de saída dos subcomandos
+const (
+	ExitOK            = 0 // Sucesso
+	ExitError         = 1 // Erro genérico de execução
+	ExitUsage         = 2 // Parâmetros inválidos
+	ExitPermission    = 3 // Permissões insuficientes
+	ExitNotFound      = 4 // Interface ou perfil não encontrado
+	ExitInvalidConfig = 5 // Configuração inválida
+	ExitDrift         = 6 // "reconcile --check" encontrou diferenças
+)
+
+// Entrada e saídas usadas pelos subcomandos
+var (
+	stdin  io.Reader = os.Stdin
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+)
+
+// command descreve um subcomando
+type command struct {
+	needsRoot bool
+	run       func(args []string) error
+}
+
+// commands lista os subcomandos disponíveis
+var commands = map[string]command{
+	"status":    {run: runStatus},
+	"configure": {run: runConfigure, needsRoot: true},
+	"virtual":   {run: runVirtual, needsRoot: true},
+	"confirm":   {run: runConfirm, needsRoot: true},
+	"rollback":  {run: runRollback, needsRoot: true},
+	"ping":      {run: runPing},
+	"wifi":      {run: runWiFi},
+	"vpn":       {run: runVPN},
+	"profile":   {run: runProfile},
+	"reconcile": {run: runReconcile},
+	"history":   {run: runHistory},
+	"forward":   {run: runForward},
+	"sysinfo":   {run: runSysinfo},
+
+	// Interno: guarda de restauração iniciada pela aplicação segura
+	safeapply.GuardCommand: {run: runRollbackGuard, needsRoot: true},
+}
+
+// usageError indica parâmetros inválidos na linha de comando
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+// driftError indica que o host diverge do estado desejado
+type driftError struct {
+	count int
+}
+
+func (e driftError) Error() string {
+	return fmt.Sprintf(i18n.T("cli_drift_found"), e.count)
+}
+
+// permissionError indica que o comando exige privilégios de root
+type permissionError struct{}
+
+func (permissionError) Error() string {
+	return i18n.T("error_root_required")
+}
+
+// devMode desativa a verificação de privilégios, como na interface
+var devMode bool
+
+// Run executa o subcomando em args e retorna o código de saída do processo
+func Run(args []string, dev bool) int {
+	devMode = dev
+
+	if len(args) == 0 || args[0] == "help" {
+		fmt.Fprint(stdout, i18n.T("cli_usage"))
+		return ExitOK
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "%s: %s\n\n%s", i18n.T("cli_unknown_command"), args[0], i18n.T("cli_usage"))
+		return ExitUsage
+	}
+
+	var err error
+	if cmd.needsRoot {
+		err = requireRoot()
+	}
+	if err == nil {
+		err = cmd.run(args[1:])
+	}
+	if err == flag.ErrHelp {
+		return ExitOK
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", i18n.T("error_title"), err)
+	}
+	return exitCode(err)
+}
+
+// exitCode converte o erro de um subcomando no código de saída documentado
+func exitCode(err error) int {
+	var usage usageError
+	var perm permissionError
+	var drift driftError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &usage):
+		return ExitUsage
+	case errors.As(err, &perm), errors.Is(err, os.ErrPermission):
+		return ExitPermission
+	case errors.Is(err, backend.ErrNotFound), errors.Is(err, safeapply.ErrNoPending):
+		return ExitNotFound
+	case errors.As(err, &drift):
+		return ExitDrift
+	case errors.Is(err, network.ErrInvalidConfig):
+		return ExitInvalidConfig
+	default:
+		return ExitError
+	}
+}
+
+// requireRoot falha se o processo não tiver privilégios de root
+func requireRoot() error {
+	if devMode || os.Geteuid() == 0 {
+		return nil
+	}
+	return permissionError{}
+}
+
+// newFlagSet cria o conjunto de opções de um subcomando
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Uso: networkmanager-tui %s\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags interpreta as opções aceitando argumentos posicionais antes ou
+// depois delas (ex.: "configure eth0 --ipv4 manual") e retorna os posicionais
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if err == flag.ErrHelp {
+				return nil, err
+			}
+			return nil, usageError{err.Error()}
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// listFlag é uma opção que pode ser repetida. Continua nula se não for
+// informada; com valor vazio (--opção=) resulta em uma lista vazia, que
+// remove os itens.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	if *l == nil {
+		*l = []string{}
+	}
+	if value != "" {
+		*l = append(*l, value)
+	}
+	return nil
+}
+
+// expectArgs verifica a quantidade de argumentos posicionais
+func expectArgs(fs *flag.FlagSet, args []string, n int) error {
+	if len(args) != n {
+		fs.Usage()
+		return usageError{fmt.Sprintf("esperado %d argumento(s), recebido %d", n, len(args))}
+	}
+	return nil
+}
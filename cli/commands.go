@@ -0,0 +1,1616 @@
+package cli
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"networkmanager-tui/backend"
+	"networkmanager-tui/forward"
+	"networkmanager-tui/history"
+	"networkmanager-tui/i18n"
+	"networkmanager-tui/network"
+	"networkmanager-tui/safeapply"
+	"networkmanager-tui/sysinfo"
+)
+
+// runStatus mostra as conexões de rede, como a tela de status
+func runStatus(args []string) error {
+	fs := newFlagSet("status", "status [--output text|json|yaml]")
+	output := addOutputFlag(fs)
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 0); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+
+	connections, err := network.GetNetworkConnectionsInfo()
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(*output, connections, func() error {
+		w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join([]string{
+			header("network_device"),
+			header("network_type"),
+			header("network_state"),
+			header("network_name"),
+			header("network_ipv4"),
+			header("network_ipv6"),
+			header("network_gateway"),
+			header("network_dns"),
+		}, "\t"))
+		for _, conn := range connections {
+			fmt.Fprintln(w, strings.Join([]string{
+				conn.Device,
+				conn.Type,
+				conn.State,
+				orDash(conn.Name),
+				orDash(strings.Join(conn.IPv4, ", ")),
+				orDash(strings.Join(conn.IPv6, ", ")),
+				orDash(conn.Gateway),
+				orDash(strings.Join(conn.DNS, ", ")),
+			}, "\t"))
+		}
+		return w.Flush()
+	})
+}
+
+// runConfigure aplica configurações IPv4/IPv6 a uma interface, com a mesma
+// validação do formulário de configuração
+func runConfigure(args []string) error {
+	fs := newFlagSet("configure", "configure <interface> [opções]")
+	profile := fs.String("profile", "", "UUID ou nome do perfil a alterar (padrão: o perfil do dispositivo; criado se não existir)")
+	ip := addIPFlags(fs)
+	eap := addEAPFlags(fs)
+	password := addPasswordFlags(fs, "eap-password", "senha 802.1X do usuário (PEAP/TTLS)")
+	confirmTimeout := fs.Int("confirm-timeout", 0, "segundos para confirmar com \"confirm\" antes da restauração automática (0 aplica direto)")
+	checkHost := fs.String("check-host", "", "host testado com ping após aplicar (padrão: o gateway informado)")
+	dryRun := fs.Bool("dry-run", false, "mostra as propriedades alteradas e os comandos, sem aplicar")
+	output := addOutputFlag(fs)
+
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 1); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+
+	eapPassword, err := password.read()
+	if err != nil {
+		return err
+	}
+
+	cfg := ip.config(rest[0])
+	cfg.Profile = *profile
+	if cfg.EAP, err = eap.config(eapPassword, password.fromStdin()); err != nil {
+		return err
+	}
+
+	if err := checkInterface(cfg.Interface); err != nil {
+		return err
+	}
+	// A pré-visualização valida a configuração e guarda os valores anteriores
+	// para o histórico
+	preview, err := network.PreviewNetworkConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		return writeOutput(*output, preview, func() error {
+			return renderPreview(preview)
+		})
+	}
+
+	if *confirmTimeout > 0 {
+		_, err = network.ApplyNetworkConfigConfirmed(cfg, safeapply.Options{
+			Timeout:   time.Duration(*confirmTimeout) * time.Second,
+			CheckHost: *checkHost,
+		})
+	} else {
+		err = network.ApplyNetworkConfig(cfg)
+	}
+	network.RecordNetworkConfig(cfg, preview, "cli", *confirmTimeout > 0, err)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "%s %s\n", i18n.T("cli_configured"), cfg.Interface)
+	if *confirmTimeout > 0 {
+		fmt.Fprintf(stdout, i18n.T("cli_pending_confirm")+"\n", *confirmTimeout)
+	}
+	return nil
+}
+
+// runVirtual cria uma interface VLAN, bond, bridge ou team com as portas
+// escolhidas; as opções de endereçamento IP valem para a interface criada
+func runVirtual(args []string) error {
+	fs := newFlagSet("virtual", "virtual <vlan|bond|bridge|team> <nome> [opções]")
+	parent := fs.String("parent", "", "VLAN: dispositivo pai")
+	id := fs.Int("id", 0, "VLAN: ID 802.1Q (1 a 4094)")
+	ports := fs.String("ports", "", "bond, bridge e team: dispositivos incluídos como portas, separados por vírgula")
+	mode := fs.String("mode", "active-backup", "bond: modo ("+strings.Join(network.BondModes, ", ")+")")
+	miimon := fs.Int("miimon", 100, "bond: intervalo de monitoramento do link em ms (0 desativa)")
+	primary := fs.String("primary", "", "bond: porta preferida no modo active-backup")
+	vlans := fs.String("vlans", "", "bond: IDs de VLANs criadas sobre o bond na mesma operação, separados por vírgula (<nome>.<id>, com DHCP)")
+	stp := fs.Bool("stp", false, "bridge: habilita o Spanning Tree Protocol")
+	runner := fs.String("runner", "activebackup", "team: runner ("+strings.Join(network.TeamRunners, ", ")+")")
+	ip := addIPFlags(fs)
+
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 2); err != nil {
+		return err
+	}
+
+	cfg := network.VirtualInterfaceConfig{
+		Kind:        rest[0],
+		Name:        rest[1],
+		Parent:      *parent,
+		VLANID:      *id,
+		BondMode:    *mode,
+		BondMiimon:  *miimon,
+		BondPrimary: *primary,
+		BridgeSTP:   *stp,
+		TeamRunner:  *runner,
+		IP:          ip.config(rest[1]),
+	}
+	for _, port := range strings.Split(*ports, ",") {
+		if port = strings.TrimSpace(port); port != "" {
+			cfg.Ports = append(cfg.Ports, port)
+		}
+	}
+
+	cfgs := []network.VirtualInterfaceConfig{cfg}
+	for _, value := range strings.Split(*vlans, ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		if cfg.Kind != network.VirtualBond {
+			return usageError{"--vlans só vale para bond"}
+		}
+		vlanID, err := strconv.Atoi(value)
+		if err != nil {
+			return usageError{fmt.Sprintf("ID de VLAN inválido: %q", value)}
+		}
+		cfgs = append(cfgs, network.VirtualInterfaceConfig{
+			Kind:   network.VirtualVLAN,
+			Name:   fmt.Sprintf("%s.%d", cfg.Name, vlanID),
+			Parent: cfg.Name,
+			VLANID: vlanID,
+		})
+	}
+
+	profiles, err := network.CreateVirtualInterfaces(cfgs)
+	network.RecordVirtualInterfaces(cfgs, profiles, "cli", err)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "%s %s: %s\n", i18n.T("cli_virtual_created"), cfg.Name, strings.Join(profiles, ", "))
+	return nil
+}
+
+// ipFlags guarda as opções de endereçamento IP comuns a configure e virtual
+type ipFlags struct {
+	ipv4, address, netmask, gateway, dns    *string
+	ipv6, address6, prefix6, gateway6, dns6 *string
+	routing4, routing6                      network.RoutingConfig
+}
+
+// addIPFlags registra as opções de endereçamento IP
+func addIPFlags(fs *flag.FlagSet) *ipFlags {
+	f := &ipFlags{
+		ipv4:     fs.String("ipv4", "", "modo IPv4: auto ou manual"),
+		address:  fs.String("address", "", "endereço IPv4, opcionalmente com prefixo (192.168.1.10/24)"),
+		netmask:  fs.String("netmask", "", "máscara IPv4, em prefixo (24) ou completa (255.255.255.0)"),
+		gateway:  fs.String("gateway", "", "gateway IPv4"),
+		dns:      fs.String("dns", "", "servidores DNS IPv4 separados por vírgula"),
+		ipv6:     fs.String("ipv6", "", "modo IPv6: auto, manual ou disabled"),
+		address6: fs.String("address6", "", "endereço IPv6, opcionalmente com prefixo (2001:db8::10/64)"),
+		prefix6:  fs.String("prefix6", "", "prefixo IPv6"),
+		gateway6: fs.String("gateway6", "", "gateway IPv6"),
+		dns6:     fs.String("dns6", "", "servidores DNS IPv6 separados por vírgula"),
+	}
+	addRoutingFlags(fs, &f.routing4, "")
+	addRoutingFlags(fs, &f.routing6, "6")
+	return f
+}
+
+// config monta a configuração IP da interface a partir das opções
+func (f *ipFlags) config(iface string) network.NetworkConfig {
+	cfg := network.NetworkConfig{
+		Interface:   iface,
+		IPv4Mode:    *f.ipv4,
+		IPv4Gateway: *f.gateway,
+		IPv4DNS:     strings.Split(*f.dns, ","),
+		IPv6Mode:    *f.ipv6,
+		IPv6Gateway: *f.gateway6,
+		IPv6DNS:     strings.Split(*f.dns6, ","),
+		IPv4Routing: f.routing4,
+		IPv6Routing: f.routing6,
+	}
+	cfg.IPv4Address, cfg.IPv4Netmask = splitPrefix(*f.address, *f.netmask)
+	cfg.IPv6Address, cfg.IPv6Prefix = splitPrefix(*f.address6, *f.prefix6)
+
+	// Informar um endereço implica o modo manual
+	if cfg.IPv4Mode == "" && cfg.IPv4Address != "" {
+		cfg.IPv4Mode = "manual"
+	}
+	if cfg.IPv6Mode == "" && cfg.IPv6Address != "" {
+		cfg.IPv6Mode = "manual"
+	}
+	return cfg
+}
+
+// eapFlags guarda as opções da autenticação 802.1X comuns a configure e
+// wifi connect
+type eapFlags struct {
+	method, identity, anonymous, caCert, clientCert, privateKey, keyPassword, keyPasswordFile, phase2 *string
+}
+
+// addEAPFlags registra as opções da autenticação 802.1X
+func addEAPFlags(fs *flag.FlagSet) *eapFlags {
+	return &eapFlags{
+		method:          fs.String("eap", "", "autenticação 802.1X: peap, ttls, tls (none remove, em configure)"),
+		identity:        fs.String("identity", "", "identidade 802.1X"),
+		anonymous:       fs.String("anonymous-identity", "", "identidade anônima (fase externa do PEAP/TTLS)"),
+		caCert:          fs.String("ca-cert", "", "certificado da CA (PEM)"),
+		clientCert:      fs.String("client-cert", "", "certificado do cliente (EAP-TLS)"),
+		privateKey:      fs.String("private-key", "", "chave privada do cliente (EAP-TLS)"),
+		keyPassword:     fs.String("private-key-password", "", "não aceita: use --private-key-password-file"),
+		keyPasswordFile: fs.String("private-key-password-file", "", "arquivo com a senha da chave privada na primeira linha (- lê da entrada padrão)"),
+		phase2:          fs.String("phase2", "", "segunda fase do PEAP/TTLS: mschapv2, gtc, pap, chap ou md5 (padrão mschapv2)"),
+	}
+}
+
+// config monta a autenticação 802.1X; password é a senha do usuário e
+// stdinUsed informa se ela já foi lida da entrada padrão
+func (f *eapFlags) config(password string, stdinUsed bool) (network.EAPConfig, error) {
+	if *f.keyPassword != "" {
+		return network.EAPConfig{}, secretOnCommandLine("private-key-password", "--private-key-password-file")
+	}
+	var keyPassword string
+	if *f.keyPasswordFile != "" {
+		if *f.keyPasswordFile == "-" && stdinUsed {
+			return network.EAPConfig{}, usageError{"a entrada padrão já fornece a senha do usuário; use um arquivo em --private-key-password-file"}
+		}
+		var err error
+		if keyPassword, err = readSecretLine(*f.keyPasswordFile); err != nil {
+			return network.EAPConfig{}, err
+		}
+	}
+	return network.EAPConfig{
+		Method:             *f.method,
+		Identity:           *f.identity,
+		AnonymousIdentity:  *f.anonymous,
+		CACert:             *f.caCert,
+		ClientCert:         *f.clientCert,
+		PrivateKey:         *f.privateKey,
+		PrivateKeyPassword: keyPassword,
+		Phase2:             *f.phase2,
+		Password:           password,
+	}, nil
+}
+
+// passwordFlags guarda as formas de informar uma senha: --password-stdin e
+// --password-file. A opção com a senha no valor só existe para recusá-la com
+// uma explicação: na linha de comando, a senha ficaria visível na lista de
+// processos e no histórico do shell.
+type passwordFlags struct {
+	name  string
+	value *string
+	stdin *bool
+	file  *string
+}
+
+// addPasswordFlags registra --<name>, --password-stdin e --password-file
+func addPasswordFlags(fs *flag.FlagSet, name, usage string) *passwordFlags {
+	return &passwordFlags{
+		name:  name,
+		value: fs.String(name, "", usage+" (não aceita: use --password-stdin ou --password-file)"),
+		stdin: fs.Bool("password-stdin", false, "lê a senha da primeira linha da entrada padrão"),
+		file:  fs.String("password-file", "", "lê a senha da primeira linha do arquivo"),
+	}
+}
+
+// read retorna a senha informada, ou vazio se nenhuma foi
+func (f *passwordFlags) read() (string, error) {
+	if *f.value != "" {
+		return "", secretOnCommandLine(f.name, "--password-stdin ou --password-file")
+	}
+	switch {
+	case *f.stdin && *f.file != "":
+		return "", usageError{"use --password-stdin ou --password-file, não os dois"}
+	case *f.stdin:
+		return readSecretLine("-")
+	case *f.file != "":
+		return readSecretLine(*f.file)
+	}
+	return "", nil
+}
+
+// fromStdin informa se a senha é lida da entrada padrão
+func (f *passwordFlags) fromStdin() bool {
+	return *f.stdin || *f.file == "-"
+}
+
+// secretOnCommandLine recusa um segredo passado no valor de uma opção
+func secretOnCommandLine(name, alternative string) error {
+	return usageError{fmt.Sprintf("--%s não é aceita: a senha ficaria visível na lista de processos e no histórico do shell; use %s", name, alternative)}
+}
+
+// readSecretLine retorna a primeira linha do arquivo ou, com "-", da entrada
+// padrão
+func readSecretLine(path string) (string, error) {
+	var r io.Reader = stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return "", fmt.Errorf("erro ao ler a senha: %w", err)
+		}
+		defer file.Close()
+		r = file
+	}
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("erro ao ler a senha: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// addRoutingFlags registra as opções avançadas de uma família; suffix é "6"
+// para as opções IPv6
+func addRoutingFlags(fs *flag.FlagSet, rc *network.RoutingConfig, suffix string) {
+	family := "IPv4"
+	if suffix != "" {
+		family = "IPv6"
+	}
+	fs.Var((*listFlag)(&rc.Addresses), "extra-address"+suffix, "endereço "+family+" adicional em CIDR, no modo manual (repetível)")
+	fs.Var((*listFlag)(&rc.Routes), "route"+suffix, "rota "+family+" \"destino/prefixo [próximo-salto] [métrica] [table=N] [src=IP] [mtu=N]...\" (repetível; '' remove todas)")
+	fs.Var((*listFlag)(&rc.RoutingRules), "rule"+suffix, "regra "+family+" \"priority N [from X] [to Y] table T\" (repetível; '' remove todas)")
+	fs.Var((*listFlag)(&rc.DNSSearch), "dns-search"+suffix, "domínio de busca DNS "+family+" (repetível; '' remove todos)")
+	fs.StringVar(&rc.RouteMetric, "route-metric"+suffix, "", "métrica das rotas "+family+" (-1 usa o padrão)")
+	fs.StringVar(&rc.NeverDefault, "never-default"+suffix, "", "yes para nunca usar o perfil na rota padrão "+family+", no para usar")
+}
+
+// renderPreview mostra, em texto, o valor atual e o novo de cada propriedade
+// e os comandos que seriam executados
+func renderPreview(preview network.Preview) error {
+	fmt.Fprintf(stdout, "%s %s", i18n.T("network_preview_profile"), preview.ProfileID)
+	if preview.Create {
+		fmt.Fprintf(stdout, " %s", i18n.T("network_profile_created"))
+	}
+	fmt.Fprint(stdout, "\n\n")
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\t%s\t%s\t\n", header("network_preview_property"),
+		header("network_preview_current"), header("network_preview_new"))
+	for _, change := range preview.Changes {
+		mark := ""
+		if change.Changed() {
+			mark = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", change.Key, orDash(change.Before), orDash(change.After), mark)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "\n%s\n", i18n.T("network_preview_commands"))
+	if len(preview.Commands) == 0 {
+		fmt.Fprintf(stdout, "  %s\n", i18n.T("network_preview_no_commands"))
+	}
+	for _, command := range preview.Commands {
+		fmt.Fprintf(stdout, "  $ %s\n", command)
+	}
+	return nil
+}
+
+// runConfirm confirma as alterações aplicadas com prazo de confirmação
+func runConfirm(args []string) error {
+	return forEachPending("confirm", args, func(p *safeapply.Pending) error {
+		if err := p.Confirm(); err != nil {
+			return err
+		}
+		network.RecordConfirm(p, "cli")
+		fmt.Fprintf(stdout, "%s %s\n", i18n.T("cli_confirmed"), p.Interface)
+		return nil
+	})
+}
+
+// runRollback restaura na hora as configurações anteriores às alterações
+// pendentes
+func runRollback(args []string) error {
+	return forEachPending("rollback", args, func(p *safeapply.Pending) error {
+		if err := p.Rollback(); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "%s %s\n", i18n.T("cli_rolled_back"), p.Interface)
+		return nil
+	})
+}
+
+// forEachPending executa fn para cada alteração pendente, opcionalmente
+// filtrando pela interface informada
+func forEachPending(name string, args []string, fn func(p *safeapply.Pending) error) error {
+	fs := newFlagSet(name, name+" [interface]")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 1 {
+		return expectArgs(fs, rest, 1)
+	}
+
+	pending, err := safeapply.List(backend.Default())
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, p := range pending {
+		if len(rest) == 1 && p.Interface != rest[0] {
+			continue
+		}
+		found = true
+		if err := fn(p); err != nil {
+			return fmt.Errorf("%s: %w", p.Interface, err)
+		}
+	}
+	if !found {
+		return safeapply.ErrNoPending
+	}
+	return nil
+}
+
+// runRollbackGuard é executado em segundo plano pela aplicação segura e
+// restaura as configurações se a alteração não for confirmada a tempo
+func runRollbackGuard(args []string) error {
+	if len(args) != 1 {
+		return usageError{"uso: networkmanager-tui " + safeapply.GuardCommand + " <arquivo>"}
+	}
+
+	// A guarda deve sobreviver ao fechamento do terminal que a iniciou
+	signal.Ignore(syscall.SIGHUP, syscall.SIGINT)
+	return safeapply.Guard(backend.Default(), args[0])
+}
+
+// runPing testa a conectividade com um host
+func runPing(args []string) error {
+	fs := newFlagSet("ping", "ping <host> [-c quantidade]")
+	count := fs.Int("c", 4, "quantidade de pacotes")
+
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 1); err != nil {
+		return err
+	}
+	if *count <= 0 {
+		return usageError{"a quantidade de pacotes deve ser maior que zero"}
+	}
+
+	output, err := network.Ping(rest[0], *count)
+	fmt.Fprint(stdout, output)
+	return err
+}
+
+// runWiFi despacha os subcomandos "wifi scan" e "wifi connect"
+func runWiFi(args []string) error {
+	if len(args) == 0 {
+		return usageError{"uso: networkmanager-tui wifi <scan|connect> [opções]"}
+	}
+
+	switch args[0] {
+	case "scan":
+		return runWiFiScan(args[1:])
+	case "connect":
+		if err := requireRoot(); err != nil {
+			return err
+		}
+		return runWiFiConnect(args[1:])
+	default:
+		return usageError{fmt.Sprintf("%s: wifi %s", i18n.T("cli_unknown_command"), args[0])}
+	}
+}
+
+// runWiFiScan lista as redes Wi-Fi visíveis, agrupando os pontos de acesso
+// de cada SSID
+func runWiFiScan(args []string) error {
+	fs := newFlagSet("wifi scan", "wifi scan [--rescan] [--sort signal|channel|ssid] [--output text|json|yaml]")
+	rescan := fs.Bool("rescan", false, "pede uma nova varredura antes de listar")
+	sortBy := fs.String("sort", network.WiFiSortSignal, "ordem das redes: signal, channel ou ssid")
+	output := addOutputFlag(fs)
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 0); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+	switch *sortBy {
+	case network.WiFiSortSignal, network.WiFiSortChannel, network.WiFiSortSSID:
+	default:
+		return usageError{fmt.Sprintf("ordem desconhecida: %s (use signal, channel ou ssid)", *sortBy)}
+	}
+
+	networks, err := network.ScanWiFi(*rescan)
+	if err != nil {
+		return err
+	}
+	network.SortWiFiNetworks(networks, *sortBy)
+
+	return writeOutput(*output, networks, func() error {
+		w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join([]string{
+			header("wifi_ssid"),
+			header("wifi_bssid"),
+			header("wifi_signal"),
+			"dBm",
+			header("wifi_channel"),
+			header("wifi_band"),
+			header("wifi_security"),
+		}, "\t"))
+		var aps []backend.AccessPoint
+		for _, n := range networks {
+			aps = append(aps, n.AccessPoints...)
+		}
+		// Na ordem por canal, cada ponto de acesso aparece no seu canal
+		if *sortBy == network.WiFiSortChannel {
+			sort.SliceStable(aps, func(i, j int) bool {
+				return aps[i].Frequency < aps[j].Frequency
+			})
+		}
+		for _, ap := range aps {
+			channel := "-"
+			if ap.Channel > 0 {
+				channel = strconv.Itoa(ap.Channel)
+			}
+			fmt.Fprintln(w, strings.Join([]string{
+				ap.SSID,
+				ap.BSSID,
+				fmt.Sprintf("%d%%", ap.Signal),
+				strconv.Itoa(ap.DBm),
+				channel,
+				orDash(ap.Band()),
+				securityText(ap),
+			}, "\t"))
+		}
+		return w.Flush()
+	})
+}
+
+// securityText descreve a segurança do ponto de acesso; backends que não
+// informam o tipo indicam apenas se a rede é protegida
+func securityText(ap backend.AccessPoint) string {
+	switch {
+	case ap.Security == backend.SecurityOpen || (ap.Security == "" && !ap.Secured):
+		return i18n.T("wifi_open")
+	case ap.Security == "":
+		return i18n.T("wifi_secured")
+	}
+	return backend.SecurityLabel(ap.Security)
+}
+
+// runWiFiConnect conecta a uma rede Wi-Fi
+func runWiFiConnect(args []string) error {
+	fs := newFlagSet("wifi connect", "wifi connect <ssid> [--password-stdin | --password-file arquivo] [--security tipo] [--hidden] [--eap método ...] [--ifname dispositivo]")
+	passwordFlags := addPasswordFlags(fs, "password", "senha da rede ou, no 802.1X, do usuário")
+	security := fs.String("security", "", "segurança: open, wep, wpa-psk, sae ou wpa-eap (padrão: deduzida das opções)")
+	ifname := fs.String("ifname", "", "dispositivo Wi-Fi (padrão: o primeiro encontrado)")
+	hidden := fs.Bool("hidden", false, "rede oculta, que não anuncia o SSID")
+	eap := addEAPFlags(fs)
+
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 1); err != nil {
+		return err
+	}
+	ssid := rest[0]
+	password, err := passwordFlags.read()
+	if err != nil {
+		return err
+	}
+
+	cred := network.WiFiCredentials{Security: *security, Password: password}
+	if *eap.method != "" || *security == backend.SecurityEAP {
+		cred.Password = ""
+		if cred.EAP, err = eap.config(password, passwordFlags.fromStdin()); err != nil {
+			return err
+		}
+	}
+	connect, details := network.ConnectWiFi, "SSID "+ssid
+	if *hidden {
+		connect, details = network.ConnectHiddenWiFi, details+", rede oculta"
+	}
+	device, before := network.WiFiConnection(*ifname)
+	err = connect(ssid, *ifname, cred)
+	network.RecordWiFiConnect(ssid, device, before, details, "cli", err)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "%s %s\n", i18n.T("cli_wifi_connected"), ssid)
+	return nil
+}
+
+// runVPN despacha os subcomandos "vpn list", "vpn import", "vpn up",
+// "vpn down" e "vpn peers"
+func runVPN(args []string) error {
+	if len(args) == 0 {
+		return usageError{"uso: networkmanager-tui vpn <list|import|up|down|peers> [opções]"}
+	}
+
+	switch args[0] {
+	case "list":
+		return runVPNList(args[1:])
+	case "import", "up", "down", "peers":
+		if err := requireRoot(); err != nil {
+			return err
+		}
+	default:
+		return usageError{fmt.Sprintf("%s: vpn %s", i18n.T("cli_unknown_command"), args[0])}
+	}
+
+	switch args[0] {
+	case "import":
+		return runVPNImport(args[1:])
+	case "peers":
+		return runVPNPeers(args[1:])
+	default:
+		return runVPNActivate(args[0], args[1:])
+	}
+}
+
+// runVPNList lista os túneis VPN com estado e último handshake
+func runVPNList(args []string) error {
+	fs := newFlagSet("vpn list", "vpn list [--output text|json|yaml]")
+	output := addOutputFlag(fs)
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 0); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+
+	tunnels, err := network.VPNTunnels()
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(*output, tunnels, func() error {
+		w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join([]string{
+			header("network_name"),
+			header("network_type"),
+			header("network_device"),
+			header("network_state"),
+			header("vpn_remote"),
+			header("vpn_handshake"),
+		}, "\t"))
+		for _, t := range tunnels {
+			state, handshake := i18n.T("vpn_inactive"), "-"
+			if t.Active {
+				state = i18n.T("vpn_active")
+				if t.Kind == backend.VPNWireGuard {
+					handshake = network.HandshakeAge(t.LatestHandshake)
+				}
+			}
+			fmt.Fprintln(w, strings.Join([]string{
+				t.Name, t.Kind, orDash(t.Device), state, orDash(t.Remote), handshake,
+			}, "\t"))
+		}
+		return w.Flush()
+	})
+}
+
+// runVPNImport importa um arquivo WireGuard ou OpenVPN
+func runVPNImport(args []string) error {
+	fs := newFlagSet("vpn import", "vpn import <arquivo.conf|arquivo.ovpn>")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 1); err != nil {
+		return err
+	}
+
+	p, err := network.ImportVPN(rest[0])
+	network.RecordVPNImport(rest[0], p, "cli", err)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "%s %s (%s)\n", i18n.T("cli_vpn_imported"), p.Name, p.UUID)
+	return nil
+}
+
+// runVPNActivate conecta ("up") ou desconecta ("down") um túnel
+func runVPNActivate(name string, args []string) error {
+	fs := newFlagSet("vpn "+name, "vpn "+name+" <perfil>")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 1); err != nil {
+		return err
+	}
+
+	up := name == "up"
+	err = network.SetVPNActive(rest[0], up)
+	network.RecordVPNActive(rest[0], rest[0], up, "cli", err)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "%s %s\n", i18n.T("cli_vpn_"+name), rest[0])
+	return nil
+}
+
+// runVPNPeers lista os peers de um túnel WireGuard ou, com --peer, os
+// substitui
+func runVPNPeers(args []string) error {
+	fs := newFlagSet("vpn peers", "vpn peers <perfil> [--peer \"<chave> allowed-ips=A;B endpoint=host:porta\"]... [--preshared-keys-file arquivo]")
+	var peers listFlag
+	fs.Var(&peers, "peer", "peer no formato \"<chave-pública> allowed-ips=A;B [endpoint=host:porta] [persistent-keepalive=N]\" (repetível; substitui todos)")
+	keysFile := fs.String("preshared-keys-file", "", "arquivo com as chaves compartilhadas dos peers, uma por linha: \"<chave-pública> <chave-compartilhada>\" (- lê da entrada padrão)")
+	output := addOutputFlag(fs)
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 1); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+	id := rest[0]
+
+	if peers == nil {
+		current, err := network.WireGuardPeers(id)
+		if err != nil {
+			return err
+		}
+		return writeOutput(*output, current, func() error {
+			for _, peer := range current {
+				peer.PresharedKey = ""
+				fmt.Fprintln(stdout, backend.FormatWireGuardPeers([]backend.WireGuardPeer{peer}))
+			}
+			return nil
+		})
+	}
+
+	if *keysFile != "" && peers == nil {
+		return usageError{"--preshared-keys-file exige --peer"}
+	}
+
+	var edited []backend.WireGuardPeer
+	for _, value := range peers {
+		parsed, err := backend.ParseWireGuardPeers(value)
+		if err != nil {
+			return fmt.Errorf("%w: %v", network.ErrInvalidConfig, err)
+		}
+		for _, peer := range parsed {
+			// Na linha de comando, a chave ficaria visível na lista de processos
+			if peer.PresharedKey != "" {
+				return usageError{"a chave compartilhada não é aceita em --peer; use --preshared-keys-file"}
+			}
+		}
+		edited = append(edited, parsed...)
+	}
+	if *keysFile != "" {
+		keys, err := readPresharedKeys(*keysFile)
+		if err != nil {
+			return err
+		}
+		for i := range edited {
+			edited[i].PresharedKey = keys[edited[i].PublicKey]
+			delete(keys, edited[i].PublicKey)
+		}
+		if len(keys) > 0 {
+			unknown := make([]string, 0, len(keys))
+			for publicKey := range keys {
+				unknown = append(unknown, publicKey)
+			}
+			sort.Strings(unknown)
+			return fmt.Errorf("%w: chaves compartilhadas de peers não informados em --peer: %s", network.ErrInvalidConfig, strings.Join(unknown, ", "))
+		}
+	}
+
+	before, err := backend.Default().Profile(id)
+	if err != nil {
+		return fmt.Errorf("perfil %s: %w", id, err)
+	}
+	err = network.SetWireGuardPeers(id, edited)
+	network.RecordVPNPeers(before, len(edited), "cli", err)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "%s %s\n", i18n.T("cli_vpn_peers_saved"), id)
+	return nil
+}
+
+// readPresharedKeys lê as chaves compartilhadas dos peers do arquivo, ou da
+// entrada padrão com "-": uma linha "<chave-pública> <chave-compartilhada>"
+// por peer, ignorando linhas vazias e comentários
+func readPresharedKeys(path string) (map[string]string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler as chaves compartilhadas: %w", err)
+	}
+
+	keys := map[string]string{}
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%w: linha %d das chaves compartilhadas: use \"<chave-pública> <chave-compartilhada>\"", network.ErrInvalidConfig, n+1)
+		}
+		keys[fields[0]] = fields[1]
+	}
+	return keys, nil
+}
+
+// runProfile despacha os subcomandos "profile list", "profile clone",
+// "profile rename", "profile delete", "profile autoconnect",
+// "profile priority", "profile export" e "profile import"
+func runProfile(args []string) error {
+	if len(args) == 0 {
+		return usageError{"uso: networkmanager-tui profile <list|clone|rename|delete|autoconnect|priority|export|import> [opções]"}
+	}
+
+	switch args[0] {
+	case "list":
+		return runProfileList(args[1:])
+	case "export":
+		return runProfileExport(args[1:])
+	case "import":
+		// A importação exige root só quando aplica; --dry-run é verificado nela
+		return runProfileImport(args[1:])
+	case "clone", "rename", "delete", "autoconnect", "priority":
+		if err := requireRoot(); err != nil {
+			return err
+		}
+	default:
+		return usageError{fmt.Sprintf("%s: profile %s", i18n.T("cli_unknown_command"), args[0])}
+	}
+
+	switch args[0] {
+	case "clone", "rename":
+		return runProfileName(args[0], args[1:])
+	case "delete":
+		return runProfileDelete(args[1:])
+	default:
+		return runProfileAutoconnect(args[0], args[1:])
+	}
+}
+
+// runProfileList lista todos os perfis salvos
+func runProfileList(args []string) error {
+	fs := newFlagSet("profile list", "profile list [--output text|json|yaml]")
+	output := addOutputFlag(fs)
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 0); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+
+	profiles, err := network.SavedProfiles()
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(*output, profiles, func() error {
+		w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join([]string{
+			header("network_name"),
+			"UUID",
+			header("network_type"),
+			header("network_device"),
+			header("network_state"),
+			header("profiles_autoconnect"),
+			header("profiles_priority"),
+			header("profiles_last_used"),
+		}, "\t"))
+		for _, p := range profiles {
+			state, autoconnect := i18n.T("vpn_inactive"), i18n.T("profiles_no")
+			if p.Active {
+				state = i18n.T("vpn_active")
+			}
+			if p.Autoconnect {
+				autoconnect = i18n.T("profiles_yes")
+			}
+			fmt.Fprintln(w, strings.Join([]string{
+				p.Name, p.UUID, p.Type, orDash(p.Device), state, autoconnect,
+				strconv.Itoa(p.Priority), network.LastUsedText(p.LastUsed),
+			}, "\t"))
+		}
+		return w.Flush()
+	})
+}
+
+// runProfileName copia ("clone") ou renomeia ("rename") um perfil
+func runProfileName(name string, args []string) error {
+	fs := newFlagSet("profile "+name, "profile "+name+" <perfil> <novo-nome>")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 2); err != nil {
+		return err
+	}
+	id, newName := rest[0], rest[1]
+
+	before, err := backend.Default().Profile(id)
+	if err != nil {
+		return fmt.Errorf("perfil %s: %w", id, err)
+	}
+
+	if name == "clone" {
+		var p backend.Profile
+		p, err = network.CloneProfile(before.ID(), newName)
+		network.RecordProfileClone(before, p, newName, "cli", err)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "%s %s (%s)\n", i18n.T("cli_profile_cloned"), p.Name, p.UUID)
+		return nil
+	}
+
+	err = network.RenameProfile(before.ID(), newName)
+	network.RecordProfileAction("profile_rename", before,
+		fmt.Sprintf("Perfil %s renomeado para %s", before.Name, newName), "cli", err)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s %s\n", i18n.T("cli_profile_renamed"), newName)
+	return nil
+}
+
+// runProfileDelete remove um perfil depois da confirmação, que --yes dispensa
+func runProfileDelete(args []string) error {
+	fs := newFlagSet("profile delete", "profile delete <perfil> [--yes]")
+	yes := fs.Bool("yes", false, "remove sem pedir confirmação")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 1); err != nil {
+		return err
+	}
+	id := rest[0]
+
+	p, err := backend.Default().Profile(id)
+	if err != nil {
+		return fmt.Errorf("perfil %s: %w", id, err)
+	}
+	if !*yes {
+		fmt.Fprintf(stdout, i18n.T("profiles_delete_confirm")+" [y/N] ", p.Name)
+		answer, _ := bufio.NewReader(stdin).ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes", "s", "sim":
+		default:
+			return usageError{i18n.T("cli_profile_not_deleted")}
+		}
+	}
+
+	err = network.DeleteProfile(p.ID())
+	network.RecordProfileAction("profile_delete", p, fmt.Sprintf("Perfil %s (%s, %s) removido", p.Name, p.Type, p.UUID), "cli", err)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s %s\n", i18n.T("cli_profile_deleted"), p.Name)
+	return nil
+}
+
+// runProfileAutoconnect liga ou desliga a conexão automática ("autoconnect
+// <perfil> on|off") ou altera a prioridade ("priority <perfil> <n>")
+func runProfileAutoconnect(name string, args []string) error {
+	usage := "profile autoconnect <perfil> on|off"
+	if name == "priority" {
+		usage = "profile priority <perfil> <prioridade>"
+	}
+	fs := newFlagSet("profile "+name, usage)
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 2); err != nil {
+		return err
+	}
+	id, value := rest[0], rest[1]
+
+	p, err := backend.Default().Profile(id)
+	if err != nil {
+		return fmt.Errorf("perfil %s: %w", id, err)
+	}
+
+	if name == "priority" {
+		priority, perr := strconv.Atoi(value)
+		if perr != nil {
+			return usageError{fmt.Sprintf("prioridade inválida: %s", value)}
+		}
+		err = network.SetProfilePriority(p.ID(), priority)
+	} else {
+		var on bool
+		switch value {
+		case "on", "yes":
+			on = true
+		case "off", "no":
+		default:
+			return usageError{fmt.Sprintf("use on ou off: %s", value)}
+		}
+		err = network.SetProfileAutoconnect(p.ID(), on)
+	}
+	network.RecordProfileAction("profile_"+name, p, fmt.Sprintf("Perfil %s", p.Name), "cli", err)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s %s\n", i18n.T("cli_profile_updated"), p.Name)
+	return nil
+}
+
+// runProfileExport grava os perfis indicados (todos, se nenhum) em um pacote
+// JSON ou YAML, no arquivo de --file ou na saída padrão
+func runProfileExport(args []string) error {
+	fs := newFlagSet("profile export", "profile export [perfil...] [--file <arquivo>] [--format json|yaml] [--secrets]")
+	file := fs.String("file", "", "arquivo do pacote, criado com permissão 0600 (padrão: saída padrão)")
+	format := fs.String("format", "", "formato do pacote: json ou yaml (padrão: pela extensão do arquivo, ou json)")
+	secrets := fs.Bool("secrets", false, "inclui senhas e chaves privadas no pacote")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *format == "" {
+		*format = network.BundleFormat(*file)
+	}
+	switch *format {
+	case network.BundleJSON, network.BundleYAML:
+	default:
+		return usageError{fmt.Sprintf("formato de pacote desconhecido: %s (use json ou yaml)", *format)}
+	}
+
+	bundle, err := network.ExportBundle(rest, *secrets)
+	if err != nil {
+		return err
+	}
+	if *file == "" {
+		return network.WriteBundle(stdout, bundle, *format)
+	}
+
+	err = network.SaveBundle(*file, bundle, *format)
+	network.RecordExport(*file, bundle, "cli", err)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, i18n.T("cli_bundle_exported")+"\n", len(bundle.Profiles), *file)
+	return nil
+}
+
+// runProfileImport cria ou altera os perfis de um pacote, com a troca de
+// dispositivos de --map; --dry-run só mostra as alterações
+func runProfileImport(args []string) error {
+	fs := newFlagSet("profile import", "profile import <arquivo|-> [--map origem=destino,...] [--activate] [--dry-run]")
+	deviceMap := fs.String("map", "", "troca de dispositivos do host de origem, ex.: eth0=ens3,eth1=ens4")
+	activate := fs.Bool("activate", false, "ativa os perfis importados")
+	dryRun := fs.Bool("dry-run", false, "mostra os perfis criados e alterados, sem aplicar")
+	output := addOutputFlag(fs)
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 1); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+	if !*dryRun {
+		if err := requireRoot(); err != nil {
+			return err
+		}
+	}
+
+	devices, err := network.ParseDeviceMap(*deviceMap)
+	if err != nil {
+		return err
+	}
+	var bundle network.Bundle
+	if rest[0] == "-" {
+		bundle, err = network.ReadBundle(stdin)
+	} else {
+		bundle, err = network.LoadBundle(rest[0])
+	}
+	if err != nil {
+		return err
+	}
+	steps, err := network.PlanImport(bundle, devices)
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		return writeOutput(*output, steps, func() error {
+			return renderImportSteps(steps)
+		})
+	}
+
+	imported, err := network.ApplyImport(steps, *activate)
+	network.RecordImport(rest[0], steps, imported, "cli", err)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, i18n.T("cli_bundle_imported")+"\n", imported)
+	return nil
+}
+
+// renderImportSteps mostra, para cada perfil do pacote, se ele será criado ou
+// alterado e as propriedades gravadas
+func renderImportSteps(steps []network.ImportStep) error {
+	if len(steps) == 0 {
+		fmt.Fprintln(stdout, i18n.T("bundle_empty"))
+		return nil
+	}
+	for i, step := range steps {
+		if i > 0 {
+			fmt.Fprintln(stdout)
+		}
+		status := i18n.T("bundle_updated")
+		if step.Create {
+			status = i18n.T("network_profile_created")
+		}
+		fmt.Fprintf(stdout, "%s %s (%s, %s) %s\n", i18n.T("network_preview_profile"),
+			step.ProfileID, step.Type, orDash(step.Device), status)
+		for _, warning := range step.Warnings {
+			fmt.Fprintf(stdout, "  ! %s\n", warning)
+		}
+
+		w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "  %s\t%s\t%s\t\n", header("network_preview_property"),
+			header("network_preview_current"), header("network_preview_new"))
+		for _, change := range step.Changes {
+			mark := ""
+			if change.Changed() {
+				mark = "*"
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", change.Key, orDash(change.Before), orDash(change.After), mark)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		for _, command := range step.Commands {
+			fmt.Fprintf(stdout, "  $ %s\n", command)
+		}
+	}
+	return nil
+}
+
+// runReconcile compara o arquivo de estado desejado com o host e aplica só as
+// diferenças; com --check apenas as mostra
+func runReconcile(args []string) error {
+	fs := newFlagSet("reconcile", "reconcile [--file <arquivo>] [--check]")
+	file := fs.String("file", network.DefaultDesiredStateFile, "arquivo de estado desejado (YAML ou JSON)")
+	check := fs.Bool("check", false, "mostra as diferenças sem aplicar; sai com o código 6 se houver alguma")
+	output := addOutputFlag(fs)
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 0); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+	if !*check {
+		if err := requireRoot(); err != nil {
+			return err
+		}
+	}
+
+	state, err := network.LoadDesiredState(*file)
+	if err != nil {
+		return err
+	}
+	drifts, err := network.CheckDesiredState(state)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, drift := range drifts {
+		if !drift.InSync() {
+			pending = append(pending, drift.Kind+" "+drift.Name)
+		}
+	}
+	if *check || len(pending) == 0 {
+		if err := writeOutput(*output, drifts, func() error {
+			return renderDrifts(drifts)
+		}); err != nil {
+			return err
+		}
+		if *check && len(pending) > 0 {
+			return driftError{len(pending)}
+		}
+		return nil
+	}
+
+	applied, err := network.Reconcile(drifts)
+	network.RecordReconcile(*file, drifts, applied, "cli", err)
+	if err != nil {
+		return err
+	}
+	if err := writeOutput(*output, drifts, func() error {
+		return renderDrifts(drifts)
+	}); err != nil {
+		return err
+	}
+	if *output == outputText {
+		fmt.Fprintf(stdout, "\n"+i18n.T("cli_reconciled")+"\n", applied)
+	}
+	return nil
+}
+
+// renderDrifts mostra cada item do estado desejado e as propriedades que
+// diferem do host
+func renderDrifts(drifts []network.Drift) error {
+	if len(drifts) == 0 {
+		fmt.Fprintln(stdout, i18n.T("desired_empty"))
+		return nil
+	}
+	for _, drift := range drifts {
+		status := i18n.T("desired_in_sync")
+		if !drift.InSync() {
+			status = i18n.T("desired_drift")
+		}
+		fmt.Fprintf(stdout, "%s %s (%s): %s\n", drift.Kind, drift.Name, drift.Profile, status)
+		if drift.Create {
+			fmt.Fprintf(stdout, "  %s\n", i18n.T("network_profile_created"))
+		}
+		if len(drift.Changes) > 0 {
+			w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+			for _, change := range drift.Changes {
+				fmt.Fprintf(w, "  %s\t%s\t→ %s\n", change.Key, orDash(change.Before), orDash(change.After))
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+		if drift.Activate {
+			fmt.Fprintf(stdout, "  %s\n", i18n.T("desired_activate"))
+		}
+	}
+	return nil
+}
+
+// runHistory mostra as ações registradas, inclusive de execuções anteriores,
+// filtradas por período, usuário, tipo de ação e interface
+func runHistory(args []string) error {
+	if len(args) > 0 && args[0] == "undo" {
+		return runHistoryUndo(args[1:])
+	}
+	fs := newFlagSet("history", "history [--since quando] [--until quando] [--user usuário] [--action tipo] [--interface nome] [--limit n] [--output text|json|yaml]")
+	since := fs.String("since", "", "ações a partir de uma data (2006-01-02), data e hora (\"2006-01-02 15:04\") ou tempo decorrido (24h, 7d)")
+	until := fs.String("until", "", "ações até uma data, nos mesmos formatos de --since")
+	user := fs.String("user", "", "só as ações deste usuário")
+	action := fs.String("action", "", "tipo de ação, exato ou prefixo (vpn inclui vpn_up e vpn_down)")
+	iface := fs.String("interface", "", "só as ações nesta interface ou túnel")
+	limit := fs.Int("limit", 0, "mostra só as últimas N ações (0 mostra todas)")
+	output := addOutputFlag(fs)
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 0); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+	if *limit < 0 {
+		return usageError{"--limit não pode ser negativo"}
+	}
+
+	filter := history.Filter{User: *user, Action: *action, Interface: *iface, Limit: *limit}
+	now := time.Now()
+	if *since != "" {
+		if filter.Since, err = history.ParseSince(*since, now); err != nil {
+			return usageError{"--since: " + err.Error()}
+		}
+	}
+	if *until != "" {
+		if filter.Until, err = history.ParseUntil(*until, now); err != nil {
+			return usageError{"--until: " + err.Error()}
+		}
+	}
+
+	actions, err := history.Query(filter)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(*output, actions, func() error {
+		if len(actions) == 0 {
+			fmt.Fprintln(stdout, i18n.T("history_empty"))
+			return nil
+		}
+		w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join([]string{
+			"ID",
+			header("history_time"),
+			header("history_user"),
+			header("history_origin"),
+			header("history_action"),
+			header("history_interface"),
+			header("history_via"),
+			header("history_details"),
+		}, "\t"))
+		for _, a := range actions {
+			fmt.Fprintln(w, strings.Join([]string{
+				orDash(a.ID), a.Timestamp.Format("02/01/2006 15:04:05"), a.UserID, orDash(a.Origin()), a.Action,
+				orDash(a.Interface), a.ModifiedBy, orDash(a.Details),
+			}, "\t"))
+		}
+		return w.Flush()
+	})
+}
+
+// runHistoryUndo restaura o perfil ao estado anterior a uma alteração do
+// histórico (sem identificador, a última que pode ser desfeita)
+func runHistoryUndo(args []string) error {
+	fs := newFlagSet("history undo", "history undo [id] [--dry-run] [--output text|json|yaml]")
+	dryRun := fs.Bool("dry-run", false, "mostra as propriedades restauradas e os comandos, sem aplicar")
+	output := addOutputFlag(fs)
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 1 {
+		return expectArgs(fs, rest, 1)
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+	if !*dryRun {
+		if err := requireRoot(); err != nil {
+			return err
+		}
+	}
+
+	var action history.Action
+	if len(rest) == 1 {
+		action, err = history.Find(rest[0])
+	} else {
+		action, err = history.LastUndoable()
+	}
+	if err != nil {
+		return err
+	}
+	plan, err := network.PlanUndo(action)
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		return writeOutput(*output, plan, func() error {
+			return renderUndo(plan)
+		})
+	}
+
+	err = network.Undo(plan)
+	network.RecordUndo(plan, "cli", err)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s %s (%s, %s)\n", i18n.T("cli_undone"), action.ID, action.Action,
+		action.Timestamp.Format("02/01/2006 15:04:05"))
+	if len(plan.Skipped) > 0 {
+		fmt.Fprintf(stderr, i18n.T("history_undo_skipped")+"\n", strings.Join(plan.Skipped, ", "))
+	}
+	return nil
+}
+
+// renderUndo mostra a ação desfeita e as propriedades restauradas
+func renderUndo(plan network.UndoPlan) error {
+	a := plan.Action
+	fmt.Fprintf(stdout, "%s (%s, %s, %s)\n", a.ID, a.Action, a.Timestamp.Format("02/01/2006 15:04:05"), a.UserID)
+	if len(plan.Skipped) > 0 {
+		fmt.Fprintf(stdout, i18n.T("history_undo_skipped")+"\n", strings.Join(plan.Skipped, ", "))
+	}
+	fmt.Fprintln(stdout)
+	return renderPreview(plan.Preview)
+}
+
+// runForward despacha os subcomandos "forward status", "forward flush" e
+// "forward test"
+func runForward(args []string) error {
+	if len(args) == 0 {
+		return usageError{"uso: networkmanager-tui forward <status|flush|test> [opções]"}
+	}
+
+	switch args[0] {
+	case "status":
+		return runForwardStatus(args[1:])
+	case "flush", "test":
+	default:
+		return usageError{fmt.Sprintf("%s: forward %s", i18n.T("cli_unknown_command"), args[0])}
+	}
+
+	fs := newFlagSet("forward "+args[0], "forward "+args[0])
+	rest, err := parseFlags(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 0); err != nil {
+		return err
+	}
+	if err := requireRoot(); err != nil {
+		return err
+	}
+	if !forward.Enabled() {
+		return fmt.Errorf("%w: %s", network.ErrInvalidConfig, i18n.T("forward_disabled"))
+	}
+
+	if args[0] == "test" {
+		// A ação de teste fica no histórico, como as demais
+		history.Record(history.Action{
+			Action:     "forward_test",
+			Details:    "Teste de encaminhamento do histórico ao coletor",
+			ModifiedBy: "cli",
+		}.WithResult(nil))
+	}
+	count, err := forward.Flush()
+	if err != nil {
+		return err
+	}
+	if args[0] == "test" {
+		fmt.Fprintln(stdout, i18n.T("cli_forward_test"))
+		return nil
+	}
+	fmt.Fprintf(stdout, i18n.T("cli_forward_flushed")+"\n", count)
+	return nil
+}
+
+// runForwardStatus mostra o coletor e as ações que aguardam entrega
+func runForwardStatus(args []string) error {
+	fs := newFlagSet("forward status", "forward status [--output text|json|yaml]")
+	output := addOutputFlag(fs)
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 0); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+	if !forward.Enabled() {
+		return fmt.Errorf("%w: %s", network.ErrInvalidConfig, i18n.T("forward_disabled"))
+	}
+
+	status, err := forward.QueueStatus()
+	if err != nil {
+		return err
+	}
+	return writeOutput(*output, status, func() error {
+		w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "%s\t%s\n", i18n.T("forward_collector"), status.URL)
+		fmt.Fprintf(w, "%s\t%s\n", i18n.T("forward_queue"), status.QueueDir)
+		fmt.Fprintf(w, "%s\t%d\n", i18n.T("forward_queued"), status.Queued)
+		if !status.Oldest.IsZero() {
+			fmt.Fprintf(w, "%s\t%s\n", i18n.T("forward_oldest"), status.Oldest.Format("02/01/2006 15:04:05"))
+		}
+		return w.Flush()
+	})
+}
+
+// runSysinfo mostra as informações do sistema
+func runSysinfo(args []string) error {
+	fs := newFlagSet("sysinfo", "sysinfo [--output text|json|yaml]")
+	output := addOutputFlag(fs)
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(fs, rest, 0); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+
+	info := sysinfo.CollectSystemInfo()
+	return writeOutput(*output, info, func() error {
+		_, err := fmt.Fprint(stdout, sysinfo.RenderSystemInfoText(info))
+		return err
+	})
+}
+
+// checkInterface verifica se a interface existe no sistema
+func checkInterface(name string) error {
+	interfaces, err := network.GetNetworkConnections()
+	if err != nil {
+		return err
+	}
+	for _, iface := range interfaces {
+		if iface == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("interface %s: %w", name, backend.ErrNotFound)
+}
+
+// splitPrefix separa "endereço/prefixo"; um prefixo informado à parte tem
+// precedência
+func splitPrefix(address, prefix string) (string, string) {
+	if i := strings.Index(address, "/"); i >= 0 {
+		if prefix == "" {
+			prefix = address[i+1:]
+		}
+		address = address[:i]
+	}
+	return address, prefix
+}
+
+// header retorna o título traduzido de uma coluna, sem os dois-pontos usados
+// nos rótulos da interface
+func header(key string) string {
+	return strings.TrimSuffix(i18n.T(key), ":")
+}
+
+// orDash substitui valores vazios por "-" para manter as colunas alinhadas
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
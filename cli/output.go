@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formatos aceitos pela opção --output
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+// addOutputFlag registra a opção --output e o atalho -o
+func addOutputFlag(fs *flag.FlagSet) *string {
+	output := fs.String("output", outputText, "formato da saída: text, json ou yaml")
+	fs.StringVar(output, "o", outputText, "atalho para --output")
+	return output
+}
+
+// checkOutput valida o formato antes de coletar os dados
+func checkOutput(format string) error {
+	switch format {
+	case outputText, outputJSON, outputYAML:
+		return nil
+	default:
+		return usageError{fmt.Sprintf("formato de saída desconhecido: %s (use text, json ou yaml)", format)}
+	}
+}
+
+// writeOutput escreve v no formato pedido; no formato texto usa render
+func writeOutput(format string, v interface{}, render func() error) error {
+	switch format {
+	case outputJSON:
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case outputYAML:
+		enc := yaml.NewEncoder(stdout)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return render()
+	}
+}
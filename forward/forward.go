@@ -0,0 +1,377 @@
+// Package forward encaminha as ações do histórico de auditoria a um coletor
+// central: syslog RFC 5424 por UDP, TCP ou TLS, ou um webhook HTTP com a ação
+// em JSON. Cada ação é gravada antes em uma fila em disco e só sai dela depois
+// de entregue, então nada se perde enquanto o coletor estiver inacessível; a
+// entrega é repetida em segundo plano e na próxima execução.
+//
+// Por UDP, "entregue" quer dizer apenas enviado: o envio de um datagrama não
+// falha quando ninguém o recebe, então a fila não protege contra um coletor
+// fora do ar. Para auditoria confiável, use TCP, TLS ou o webhook; URLs sem
+// esquema usam TCP.
+package forward
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"networkmanager-tui/history"
+	"networkmanager-tui/logger"
+)
+
+// Diretório padrão da fila de ações ainda não entregues
+const DefaultQueueDir = "/var/lib/networkmanager-tui/forward-queue"
+
+// Variáveis de ambiente da configuração. Também são passadas aos processos
+// filhos da aplicação (ver Environ).
+const (
+	EnvURL   = "NMTUI_FORWARD_URL"
+	EnvCA    = "NMTUI_FORWARD_CA"
+	EnvToken = "NMTUI_FORWARD_TOKEN" // Enviado no webhook como "Authorization: Bearer"
+	EnvQueue = "NMTUI_FORWARD_QUEUE"
+)
+
+// Limites da entrega
+const (
+	DefaultTimeout = 5 * time.Second // Conexão e envio de cada ação
+	MaxQueued      = 10000           // Ações na fila; as mais antigas são descartadas
+	minRetry       = 5 * time.Second
+	maxRetry       = 5 * time.Minute
+	closeTimeout   = time.Second // Última entrega ao encerrar; o que sobrar fica na fila
+)
+
+// Esquemas aceitos na URL do coletor e suas portas padrão
+var defaultPorts = map[string]string{
+	"udp":   "514",
+	"tcp":   "514",
+	"tls":   "6514",
+	"http":  "",
+	"https": "",
+}
+
+// Config define o coletor e a fila local
+type Config struct {
+	URL      string        // tcp://, tls:// ou udp://host[:porta] (syslog; sem esquema, tcp); http:// ou https:// (webhook)
+	CAFile   string        // Autoridades que assinam o certificado do coletor (tls e https); vazio usa as do sistema
+	Token    string        // Token do webhook
+	QueueDir string        // Diretório da fila em disco
+	Timeout  time.Duration // Prazo da conexão e do envio de cada ação
+}
+
+// ConfigFromEnv retorna a configuração definida nas variáveis de ambiente.
+// Sem NMTUI_FORWARD_URL, o encaminhamento fica desativado.
+func ConfigFromEnv() Config {
+	cfg := Config{
+		URL:      os.Getenv(EnvURL),
+		CAFile:   os.Getenv(EnvCA),
+		Token:    os.Getenv(EnvToken),
+		QueueDir: os.Getenv(EnvQueue),
+		Timeout:  DefaultTimeout,
+	}
+	if cfg.QueueDir == "" {
+		cfg.QueueDir = DefaultQueueDir
+	}
+	return cfg
+}
+
+// normalize completa a URL sem esquema ("host[:porta]") com tcp://, o
+// transporte syslog que confirma a entrega
+func (c Config) normalize() Config {
+	if c.URL != "" && !strings.Contains(c.URL, "://") {
+		c.URL = "tcp://" + c.URL
+	}
+	return c
+}
+
+// Validate verifica a URL do coletor e a fila
+func (c Config) Validate() error {
+	c = c.normalize()
+	if c.URL == "" {
+		return nil
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return fmt.Errorf("URL de encaminhamento inválida: %w", err)
+	}
+	if _, ok := defaultPorts[u.Scheme]; !ok {
+		return fmt.Errorf("URL de encaminhamento inválida: %s (use tcp://, tls://, udp://, http:// ou https://)", redact(c.URL))
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("URL de encaminhamento sem host: %s", redact(c.URL))
+	}
+	if c.QueueDir == "" {
+		return fmt.Errorf("diretório da fila de encaminhamento não informado")
+	}
+	return nil
+}
+
+// address retorna host:porta do coletor syslog
+func (c Config) address(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = defaultPorts[u.Scheme]
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// tlsConfig carrega as autoridades de CAFile, se informado
+func (c Config) tlsConfig(host string) (*tls.Config, error) {
+	conf := &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+	if c.CAFile == "" {
+		return conf, nil
+	}
+	data, err := os.ReadFile(c.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler autoridades do coletor: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("nenhum certificado PEM em %s", c.CAFile)
+	}
+	conf.RootCAs = pool
+	return conf, nil
+}
+
+// Environ descreve a configuração em uso como variáveis de ambiente, para que
+// os processos filhos (como a guarda da aplicação segura) encaminhem as suas
+// ações ao mesmo coletor. O token já vem do ambiente e não é repetido.
+func Environ() []string {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if !enabled {
+		return nil
+	}
+	return []string{
+		EnvURL + "=" + config.URL,
+		EnvCA + "=" + config.CAFile,
+		EnvQueue + "=" + config.QueueDir,
+	}
+}
+
+// redact esconde usuário e senha da URL nas mensagens e nos logs
+func redact(raw string) string {
+	if u, err := url.Parse(raw); err == nil {
+		return u.Redacted()
+	}
+	return raw
+}
+
+// errBusy indica que outro processo está entregando a fila
+var errBusy = errors.New("fila de encaminhamento em uso por outro processo")
+
+var (
+	mutex   sync.Mutex
+	config  Config
+	enabled bool
+	wake    chan struct{}
+	stop    chan struct{}
+	stopped chan struct{}
+)
+
+// Init ativa o encaminhamento, se houver coletor configurado, e inicia a
+// entrega em segundo plano, começando pelas ações que ficaram na fila
+func Init(cfg Config) error {
+	cfg = cfg.normalize()
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if cfg.URL == "" {
+		return nil
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	u, _ := url.Parse(cfg.URL)
+	if u.Scheme == "tls" || u.Scheme == "https" {
+		if _, err := cfg.tlsConfig(u.Hostname()); err != nil {
+			return err
+		}
+	}
+	if u.Scheme == "udp" {
+		logger.Warn("Encaminhamento por UDP não confirma a entrega; ações enviadas com o coletor fora do ar são perdidas",
+			"url", redact(cfg.URL))
+	}
+
+	Close()
+	mutex.Lock()
+	defer mutex.Unlock()
+	config, enabled = cfg, true
+	wake, stop, stopped = make(chan struct{}, 1), make(chan struct{}), make(chan struct{})
+	go run(wake, stop, stopped)
+	return nil
+}
+
+// Enabled informa se há um coletor configurado
+func Enabled() bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return enabled
+}
+
+// Send coloca a ação na fila e pede a entrega em segundo plano. Falhas vão
+// para o log e não interrompem a operação registrada.
+func Send(action history.Action) {
+	mutex.Lock()
+	cfg, on, w := config, enabled, wake
+	mutex.Unlock()
+	if !on {
+		return
+	}
+	if err := enqueue(cfg.QueueDir, action); err != nil {
+		logger.Error("Erro ao enfileirar ação para encaminhamento", "id", action.ID, "action", action.Action, "error", err)
+		return
+	}
+	select {
+	case w <- struct{}{}:
+	default:
+	}
+}
+
+// Flush entrega as ações da fila em ordem, parando na primeira falha, e
+// retorna quantas foram entregues. Se outro processo estiver entregando a
+// fila, espera que ele termine.
+func Flush() (int, error) {
+	return flush(true, 0)
+}
+
+// flush entrega a fila; sem wait, desiste com errBusy se outro processo
+// estiver entregando. Um timeout menor que o da configuração encurta o prazo
+// da conexão e do envio de cada ação.
+func flush(wait bool, timeout time.Duration) (int, error) {
+	mutex.Lock()
+	cfg, on := config, enabled
+	mutex.Unlock()
+	if !on {
+		return 0, nil
+	}
+	if timeout > 0 && timeout < cfg.Timeout {
+		cfg.Timeout = timeout
+	}
+
+	unlock, err := lockQueue(cfg.QueueDir, wait)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	count, err := deliver(cfg)
+	if count > 0 {
+		logger.Info("Ações de auditoria encaminhadas", "url", redact(cfg.URL), "count", count)
+	}
+	if err != nil {
+		pending, _ := queued(cfg.QueueDir)
+		logger.Warn("Falha ao encaminhar ações de auditoria", "url", redact(cfg.URL), "pending", len(pending), "error", err)
+	}
+	return count, err
+}
+
+// run entrega a fila ao ser acordado por Send e repete as entregas que
+// falharam, com espera crescente até maxRetry
+func run(wake <-chan struct{}, stop <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
+	retry := time.After(0)
+	delay := minRetry
+	for {
+		select {
+		case <-stop:
+			return
+		case <-wake:
+		case <-retry:
+		}
+		retry = nil
+		// Outro processo entregando a fila é tratado como falha, para que a
+		// ação enfileirada agora seja conferida na próxima tentativa
+		if _, err := flush(false, 0); err != nil {
+			retry = time.After(delay)
+			if delay *= 2; delay > maxRetry {
+				delay = maxRetry
+			}
+			continue
+		}
+		delay = minRetry
+	}
+}
+
+// Close encerra a entrega em segundo plano e tenta entregar uma última vez as
+// ações que ainda estão na fila, por no máximo closeTimeout, para que um
+// coletor inacessível não atrase a saída. As ações não entregues ficam na
+// fila para a próxima execução.
+func Close() {
+	mutex.Lock()
+	on, s, done := enabled, stop, stopped
+	mutex.Unlock()
+	if !on {
+		return
+	}
+	close(s)
+
+	deadline := time.NewTimer(closeTimeout)
+	defer deadline.Stop()
+	expired := false
+	select {
+	case <-done:
+		finished := make(chan struct{})
+		go func() {
+			defer close(finished)
+			flush(false, closeTimeout)
+		}()
+		select {
+		case <-finished:
+		case <-deadline.C:
+			expired = true
+		}
+	case <-deadline.C:
+		expired = true
+	}
+	if expired {
+		logger.Warn("Prazo de encaminhamento esgotado ao encerrar; ações pendentes ficam na fila", "timeout", closeTimeout)
+	}
+
+	mutex.Lock()
+	enabled = false
+	mutex.Unlock()
+}
+
+// Status descreve o coletor e a fila local
+type Status struct {
+	URL      string    `json:"url" yaml:"url"`
+	QueueDir string    `json:"queue_dir" yaml:"queue_dir"`
+	Queued   int       `json:"queued" yaml:"queued"`                     // Ações aguardando entrega
+	Oldest   time.Time `json:"oldest,omitempty" yaml:"oldest,omitempty"` // Ação mais antiga na fila
+}
+
+// QueueStatus retorna o coletor configurado e as ações aguardando entrega
+func QueueStatus() (Status, error) {
+	mutex.Lock()
+	cfg := config
+	mutex.Unlock()
+
+	status := Status{URL: redact(cfg.URL), QueueDir: cfg.QueueDir}
+	files, err := queued(cfg.QueueDir)
+	if err != nil {
+		return status, err
+	}
+	status.Queued = len(files)
+	if len(files) > 0 {
+		if e, err := readEvent(files[0]); err == nil {
+			status.Oldest = e.Timestamp
+		}
+	}
+	return status, nil
+}
+
+// hostname identifica este host nas mensagens encaminhadas
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		return ""
+	}
+	return strings.TrimSuffix(name, ".")
+}
@@ -0,0 +1,395 @@
+package forward
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"networkmanager-tui/history"
+)
+
+// testAction monta uma ação com identificador conhecido
+func testAction(id string) history.Action {
+	return history.Action{
+		ID:         id,
+		Timestamp:  time.Now(),
+		UserID:     "root",
+		Action:     "test",
+		Profile:    "perfil",
+		Outcome:    history.OutcomeSuccess,
+		ModifiedBy: "cli",
+	}
+}
+
+// useConfig ativa o encaminhamento para url, sem a entrega em segundo plano,
+// com uma fila vazia
+func useConfig(t *testing.T, url string) Config {
+	t.Helper()
+	cfg := Config{URL: url, Token: "segredo", QueueDir: t.TempDir(), Timeout: time.Second}
+	mutex.Lock()
+	config, enabled = cfg, true
+	mutex.Unlock()
+	t.Cleanup(func() {
+		mutex.Lock()
+		enabled = false
+		mutex.Unlock()
+	})
+	return cfg
+}
+
+// enqueueAll grava as ações na fila, na ordem
+func enqueueAll(t *testing.T, cfg Config, ids ...string) {
+	t.Helper()
+	for _, id := range ids {
+		if err := enqueue(cfg.QueueDir, testAction(id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// queuedIDs lista os identificadores das ações na fila
+func queuedIDs(t *testing.T, cfg Config) []string {
+	t.Helper()
+	files, err := queued(cfg.QueueDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := []string{}
+	for _, name := range files {
+		e, err := readEvent(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+// syslogID extrai o identificador da ação de uma mensagem RFC 5424
+func syslogID(t *testing.T, msg string) string {
+	t.Helper()
+	if !strings.HasPrefix(msg, fmt.Sprintf("<%d>1 ", syslogFacility*8+severityNotice)) {
+		t.Fatalf("cabeçalho inesperado: %.60q", msg)
+	}
+	_, body, ok := strings.Cut(msg, "\ufeff")
+	if !ok {
+		t.Fatalf("mensagem sem BOM: %.60q", msg)
+	}
+	var e event
+	if err := json.Unmarshal([]byte(body), &e); err != nil {
+		t.Fatalf("JSON inválido: %v", err)
+	}
+	return e.ID
+}
+
+// webhookCollector é um coletor HTTP que recusa as entregas enquanto down
+// for verdadeiro
+type webhookCollector struct {
+	mu       sync.Mutex
+	down     bool
+	refused  int
+	received []string
+	server   *httptest.Server
+}
+
+func newWebhookCollector(t *testing.T) *webhookCollector {
+	c := &webhookCollector{}
+	c.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer segredo" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var e event
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			t.Errorf("JSON inválido: %v", err)
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.down {
+			c.refused++
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		c.received = append(c.received, e.ID)
+	}))
+	t.Cleanup(c.server.Close)
+	return c
+}
+
+func (c *webhookCollector) setDown(down bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.down = down
+}
+
+func (c *webhookCollector) refusals() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.refused
+}
+
+func (c *webhookCollector) ids() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.received...)
+}
+
+func TestFlushUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	cfg := useConfig(t, "udp://"+conn.LocalAddr().String())
+	enqueueAll(t, cfg, "a1", "a2")
+
+	count, err := Flush()
+	if err != nil || count != 2 {
+		t.Fatalf("Flush() = %d, %v; esperado 2, nil", count, err)
+	}
+	buf := make([]byte, 1<<16)
+	for _, want := range []string{"a1", "a2"} {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := syslogID(t, string(buf[:n])); got != want {
+			t.Errorf("ação recebida %s, esperada %s", got, want)
+		}
+	}
+	if ids := queuedIDs(t, cfg); len(ids) != 0 {
+		t.Errorf("fila depois da entrega: %v", ids)
+	}
+}
+
+func TestFlushTCPOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		r := bufio.NewReader(conn)
+		var msgs []string
+		for {
+			size, err := r.ReadString(' ')
+			if err != nil {
+				break
+			}
+			n, err := strconv.Atoi(strings.TrimSuffix(size, " "))
+			if err != nil {
+				break
+			}
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(r, msg); err != nil {
+				break
+			}
+			msgs = append(msgs, string(msg))
+		}
+		received <- msgs
+	}()
+
+	cfg := useConfig(t, "tcp://"+ln.Addr().String())
+	enqueueAll(t, cfg, "b1", "b2", "b3")
+	count, err := Flush()
+	if err != nil || count != 3 {
+		t.Fatalf("Flush() = %d, %v; esperado 3, nil", count, err)
+	}
+
+	msgs := <-received
+	if len(msgs) != 3 {
+		t.Fatalf("%d mensagens recebidas, esperadas 3", len(msgs))
+	}
+	for i, want := range []string{"b1", "b2", "b3"} {
+		if got := syslogID(t, msgs[i]); got != want {
+			t.Errorf("mensagem %d: ação %s, esperada %s", i, got, want)
+		}
+	}
+}
+
+func TestFlushKeepsQueueWhileCollectorIsDown(t *testing.T) {
+	// Porta sem ninguém escutando
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := ln.Addr().String()
+	ln.Close()
+
+	cfg := useConfig(t, "tcp://"+address)
+	enqueueAll(t, cfg, "c1", "c2")
+	if count, err := Flush(); err == nil || count != 0 {
+		t.Fatalf("Flush() = %d, %v; esperado 0 e erro", count, err)
+	}
+	if ids := queuedIDs(t, cfg); strings.Join(ids, ",") != "c1,c2" {
+		t.Errorf("fila depois da falha: %v", ids)
+	}
+}
+
+func TestFlushWebhookRetry(t *testing.T) {
+	collector := newWebhookCollector(t)
+	collector.setDown(true)
+	cfg := useConfig(t, collector.server.URL)
+	enqueueAll(t, cfg, "d1", "d2")
+
+	if _, err := Flush(); err == nil {
+		t.Fatal("Flush() sem erro com o coletor recusando as ações")
+	}
+	if ids := queuedIDs(t, cfg); strings.Join(ids, ",") != "d1,d2" {
+		t.Fatalf("fila depois da recusa: %v", ids)
+	}
+
+	collector.setDown(false)
+	count, err := Flush()
+	if err != nil || count != 2 {
+		t.Fatalf("Flush() = %d, %v; esperado 2, nil", count, err)
+	}
+	if ids := collector.ids(); strings.Join(ids, ",") != "d1,d2" {
+		t.Errorf("ações entregues: %v", ids)
+	}
+	if ids := queuedIDs(t, cfg); len(ids) != 0 {
+		t.Errorf("fila depois da entrega: %v", ids)
+	}
+}
+
+func TestSendDeliversInBackground(t *testing.T) {
+	collector := newWebhookCollector(t)
+	collector.setDown(true)
+	cfg := Config{URL: collector.server.URL, Token: "segredo", QueueDir: t.TempDir(), Timeout: time.Second}
+	if err := Init(cfg); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(Close)
+
+	Send(testAction("e1"))
+	waitFor(t, func() bool { return collector.refusals() > 0 })
+	if ids := queuedIDs(t, cfg); strings.Join(ids, ",") != "e1" {
+		t.Fatalf("fila depois da recusa: %v", ids)
+	}
+
+	// A ação que falhou é entregue junto com a próxima, na ordem
+	collector.setDown(false)
+	Send(testAction("e2"))
+	waitFor(t, func() bool { return len(collector.ids()) == 2 })
+	if ids := collector.ids(); strings.Join(ids, ",") != "e1,e2" {
+		t.Errorf("ações entregues: %v", ids)
+	}
+	waitFor(t, func() bool { return len(queuedIDs(t, cfg)) == 0 })
+}
+
+func TestCloseDoesNotWaitForCollector(t *testing.T) {
+	// Coletor que não responde até o fim do teste
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := Config{URL: server.URL, QueueDir: t.TempDir(), Timeout: 10 * time.Second}
+	if err := Init(cfg); err != nil {
+		t.Fatal(err)
+	}
+	mutex.Lock()
+	done := stopped
+	mutex.Unlock()
+	Send(testAction("f1"))
+
+	start := time.Now()
+	Close()
+	if elapsed := time.Since(start); elapsed > closeTimeout+time.Second {
+		t.Errorf("Close levou %s", elapsed)
+	}
+	if ids := queuedIDs(t, cfg); strings.Join(ids, ",") != "f1" {
+		t.Errorf("fila depois de Close: %v", ids)
+	}
+	if Enabled() {
+		t.Error("encaminhamento ativo depois de Close")
+	}
+
+	// A entrega interrompida termina quando o coletor responde
+	close(release)
+	<-done
+}
+
+func TestTruncateKeepsRunes(t *testing.T) {
+	msg := strings.Repeat("ação ", 100)
+	for max := 0; max <= len(msg)+1; max++ {
+		got := truncate(msg, max)
+		if len(got) > max || !utf8.ValidString(got) || !strings.HasPrefix(msg, got) {
+			t.Fatalf("truncate(%d) = %q", max, got)
+		}
+		if max >= len(msg) && got != msg {
+			t.Fatalf("truncate(%d) alterou a mensagem", max)
+		}
+	}
+}
+
+func TestUDPTruncatesLongMessages(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	cfg := useConfig(t, "udp://"+conn.LocalAddr().String())
+	action := testAction("g1")
+	action.Details = strings.Repeat("ç", maxDatagram)
+	if err := enqueue(cfg.QueueDir, action); err != nil {
+		t.Fatal(err)
+	}
+
+	if count, err := Flush(); err != nil || count != 1 {
+		t.Fatalf("Flush() = %d, %v; esperado 1, nil", count, err)
+	}
+	buf := make([]byte, 1<<17)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n > maxDatagram || !utf8.Valid(buf[:n]) {
+		t.Errorf("datagrama de %d bytes, UTF-8 válido: %v", n, utf8.Valid(buf[:n]))
+	}
+}
+
+// waitFor espera a condição por até 5 segundos
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condição não atingida")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestURLWithoutSchemeUsesTCP(t *testing.T) {
+	cfg := Config{URL: "logs.example.com:6000", QueueDir: t.TempDir()}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.normalize().URL; got != "tcp://logs.example.com:6000" {
+		t.Errorf("URL = %s", got)
+	}
+	if got := (Config{URL: "udp://logs.example.com"}).normalize().URL; got != "udp://logs.example.com" {
+		t.Errorf("URL com esquema alterada: %s", got)
+	}
+}
@@ -0,0 +1,166 @@
+package forward
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	"networkmanager-tui/history"
+	"networkmanager-tui/logger"
+)
+
+// event é o conteúdo encaminhado: a ação do histórico e o host onde ela foi
+// feita
+type event struct {
+	Host string `json:"host,omitempty"`
+	history.Action
+}
+
+// Cada ação na fila é um arquivo <nanossegundos>-<id>.json; a ordem
+// alfabética dos nomes é a ordem das ações
+const lockFile = ".lock"
+
+// enqueue grava a ação na fila. O arquivo é escrito com outro nome e
+// renomeado, para que a entrega nunca leia uma ação pela metade.
+func enqueue(dir string, action history.Action) error {
+	// A fila guarda as mesmas informações do histórico e só é legível pelo dono
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(event{Host: hostname(), Action: action})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".new-")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	name := filepath.Join(dir, fmt.Sprintf("%020d-%s.json", time.Now().UnixNano(), action.ID))
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return trim(dir)
+}
+
+// trim descarta as ações mais antigas além de MaxQueued, para que um coletor
+// inacessível por muito tempo não encha o disco
+func trim(dir string) error {
+	files, err := queued(dir)
+	if err != nil || len(files) <= MaxQueued {
+		return err
+	}
+	dropped := files[:len(files)-MaxQueued]
+	for _, name := range dropped {
+		os.Remove(name)
+	}
+	logger.Warn("Fila de encaminhamento cheia; ações mais antigas descartadas", "dropped", len(dropped), "max", MaxQueued)
+	return nil
+}
+
+// queued lista as ações da fila da mais antiga à mais recente
+func queued(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// readEvent lê uma ação da fila
+func readEvent(name string) (event, error) {
+	var e event
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return e, err
+	}
+	if err := json.Unmarshal(data, &e); err != nil {
+		return e, fmt.Errorf("ação inválida na fila (%s): %w", filepath.Base(name), err)
+	}
+	return e, nil
+}
+
+// lockQueue impede que dois processos (a interface, a linha de comando e a
+// guarda da aplicação segura) entreguem a mesma ação duas vezes
+func lockQueue(dir string, wait bool) (func(), error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(filepath.Join(dir, lockFile), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	how := syscall.LOCK_EX
+	if !wait {
+		how |= syscall.LOCK_NB
+	}
+	if err := syscall.Flock(int(file.Fd()), how); err != nil {
+		file.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, errBusy
+		}
+		return nil, err
+	}
+	return func() {
+		syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		file.Close()
+	}, nil
+}
+
+// deliver envia as ações da fila em ordem, removendo cada uma depois de
+// entregue. Ações gravadas durante a entrega também são enviadas.
+func deliver(cfg Config) (int, error) {
+	files, err := queued(cfg.QueueDir)
+	if err != nil || len(files) == 0 {
+		return 0, err
+	}
+	s, err := dial(cfg)
+	if err != nil {
+		return 0, err
+	}
+	defer s.close()
+
+	count := 0
+	for len(files) > 0 {
+		for _, name := range files {
+			e, err := readEvent(name)
+			if os.IsNotExist(err) {
+				continue // Descartada por trim
+			}
+			if err != nil {
+				// Uma ação ilegível não pode bloquear as demais
+				logger.Error("Ação descartada da fila de encaminhamento", "error", err)
+				os.Remove(name)
+				continue
+			}
+			if err := s.send(e); err != nil {
+				return count, err
+			}
+			count++
+			// Se a ação não puder sair da fila, seria entregue de novo a cada
+			// tentativa
+			if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+				return count, err
+			}
+		}
+		if files, err = queued(cfg.QueueDir); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
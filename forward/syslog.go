@@ -0,0 +1,187 @@
+package forward
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"networkmanager-tui/history"
+	"networkmanager-tui/logger"
+)
+
+// sender entrega as ações a um coletor
+type sender interface {
+	send(e event) error
+	close() error
+}
+
+// dial conecta ao coletor da configuração
+func dial(cfg Config) (sender, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	var tlsConf *tls.Config
+	if u.Scheme == "tls" || u.Scheme == "https" {
+		if tlsConf, err = cfg.tlsConfig(u.Hostname()); err != nil {
+			return nil, err
+		}
+	}
+	if u.Scheme == "http" || u.Scheme == "https" {
+		return newWebhook(cfg, tlsConf), nil
+	}
+	return dialSyslog(cfg, u, tlsConf)
+}
+
+// Campos fixos das mensagens RFC 5424: facility 13 (log audit) e o SD-ID dos
+// parâmetros da ação, com o número de empresa reservado para exemplos na
+// RFC 5612
+const (
+	syslogFacility = 13
+	syslogSDID     = "nmtui@32473"
+)
+
+// Severidades: notice para ações concluídas ou pendentes, warning para falhas
+const (
+	severityWarning = 4
+	severityNotice  = 5
+)
+
+// Tamanho máximo de um datagrama; mensagens maiores são truncadas (RFC 5426),
+// senão a ação nunca sairia da fila
+const maxDatagram = 64000
+
+// syslogSender envia as ações como mensagens syslog RFC 5424. Por TCP e TLS,
+// cada mensagem é precedida do seu tamanho (octet counting, RFC 6587 e
+// RFC 5425); por UDP, cada mensagem é um datagrama.
+type syslogSender struct {
+	conn    net.Conn
+	stream  bool
+	timeout time.Duration
+}
+
+func dialSyslog(cfg Config, u *url.URL, tlsConf *tls.Config) (*syslogSender, error) {
+	address := cfg.address(u)
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	var conn net.Conn
+	var err error
+	switch u.Scheme {
+	case "tls":
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, tlsConf)
+	default:
+		conn, err = dialer.Dial(u.Scheme, address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar ao coletor syslog %s: %w", address, err)
+	}
+	logger.Debug("Conectado ao coletor syslog", "address", address, "protocol", u.Scheme)
+	return &syslogSender{conn: conn, stream: u.Scheme != "udp", timeout: cfg.Timeout}, nil
+}
+
+func (s *syslogSender) send(e event) error {
+	msg, err := formatSyslog(e)
+	if err != nil {
+		return err
+	}
+	if s.stream {
+		msg = fmt.Sprintf("%d %s", len(msg), msg)
+	} else {
+		msg = truncate(msg, maxDatagram)
+	}
+	s.conn.SetWriteDeadline(time.Now().Add(s.timeout))
+	if _, err := s.conn.Write([]byte(msg)); err != nil {
+		return fmt.Errorf("erro ao enviar ao coletor syslog: %w", err)
+	}
+	return nil
+}
+
+func (s *syslogSender) close() error {
+	return s.conn.Close()
+}
+
+// formatSyslog monta a mensagem RFC 5424:
+// <PRI>1 DATA HOST APP PROCID MSGID [SD] BOM JSON
+// Os campos principais vão nos parâmetros estruturados, para filtragem no
+// coletor; a mensagem é a ação completa em JSON, com as alterações.
+func formatSyslog(e event) (string, error) {
+	severity := severityNotice
+	if e.Outcome == history.OutcomeFailure {
+		severity = severityWarning
+	}
+	body, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("<%d>1 %s %s %s - %s %s \ufeff%s",
+		syslogFacility*8+severity,
+		e.Timestamp.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		headerField(e.Host, 255),
+		logger.Identifier,
+		headerField(e.Action.Action, 32),
+		structuredData(e),
+		body), nil
+}
+
+// truncate corta a mensagem em no máximo max bytes, sem dividir um caractere
+// UTF-8
+func truncate(msg string, max int) string {
+	if len(msg) <= max {
+		return msg
+	}
+	end := max
+	for end > 0 && !utf8.RuneStart(msg[end]) {
+		end--
+	}
+	return msg[:end]
+}
+
+// headerField adapta um campo do cabeçalho: só ASCII visível, com tamanho
+// máximo, e "-" quando vazio
+func headerField(value string, max int) string {
+	value = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, value)
+	if len(value) > max {
+		value = value[:max]
+	}
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// structuredData descreve os campos principais da ação como parâmetros do
+// elemento syslogSDID, omitindo os vazios
+func structuredData(e event) string {
+	var b strings.Builder
+	b.WriteString("[" + syslogSDID)
+	for _, param := range []struct{ name, value string }{
+		{"id", e.ID},
+		{"user", e.UserID},
+		{"via", e.ModifiedBy},
+		{"tty", e.TTY},
+		{"remote", e.Remote},
+		{"interface", e.Interface},
+		{"profile", e.Profile},
+		{"outcome", e.Outcome},
+	} {
+		if param.value != "" {
+			fmt.Fprintf(&b, " %s=\"%s\"", param.name, sdEscape(param.value))
+		}
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// sdEscape escapa '"', '\' e ']' nos valores dos parâmetros
+func sdEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
@@ -0,0 +1,71 @@
+package forward
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"networkmanager-tui/logger"
+)
+
+// webhookSender envia cada ação em um POST com o JSON da ação e o host. O
+// coletor confirma a entrega com uma resposta 2xx.
+type webhookSender struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func newWebhook(cfg Config, tlsConf *tls.Config) *webhookSender {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsConf != nil {
+		transport.TLSClientConfig = tlsConf
+	}
+	return &webhookSender{
+		url:    cfg.URL,
+		token:  cfg.Token,
+		client: &http.Client{Timeout: cfg.Timeout, Transport: transport},
+	}
+}
+
+func (s *webhookSender) send(e event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", logger.Identifier)
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// O url.Error repete a URL, que pode conter credenciais
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("erro ao enviar ao webhook %s: %w", redact(s.url), err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s respondeu %s", redact(s.url), resp.Status)
+	}
+	return nil
+}
+
+func (s *webhookSender) close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
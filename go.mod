@@ -4,7 +4,9 @@ go 1.19
 
 require (
         github.com/gdamore/tcell/v2 v2.8.1
+        github.com/godbus/dbus/v5 v5.1.0
         github.com/rivo/tview v0.0.0-20241227133733-17b7edb88c57
+        gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -1,71 +1,447 @@
-
+// Package history grava o histórico de auditoria das ações feitas pela
+// interface e pela linha de comando. Cada ação é uma linha JSON acrescentada
+// a um arquivo que só cresce; ao atingir MaxFileSize, o arquivo é rotacionado
+// e os mais antigos além de MaxRotatedFiles são removidos.
 package history
 
 import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
-	
+
+	"networkmanager-tui/backend"
 	"networkmanager-tui/logger"
 )
 
+// Diretório padrão do histórico
+const DefaultDir = "/var/lib/networkmanager-tui/history"
+
+// Limites da rotação: tamanho do arquivo atual e quantidade de arquivos
+// rotacionados mantidos
+const (
+	MaxFileSize     = 5 << 20
+	MaxRotatedFiles = 20
+)
+
+// Arquivo atual, prefixo dos rotacionados (history-<data>.jsonl) e arquivo
+// de bloqueio da gravação
+const (
+	currentFile   = "history.jsonl"
+	rotatedPrefix = "history-"
+	rotatedLayout = "20060102-150405.000000"
+	lockFile      = ".lock"
+)
+
+// Resultados de uma ação
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomePending = "pending" // Aguarda confirmação ou foi apenas solicitada
+)
+
+// Action é uma ação registrada no histórico
 type Action struct {
-	Timestamp   time.Time
-	UserID      string
-	Action      string
-	Details     string
-	Changes     string
-	ModifiedBy  string
+	ID         string           `json:"id,omitempty" yaml:"id,omitempty"` // Identificador curto, gerado ao registrar
+	Timestamp  time.Time        `json:"time" yaml:"time"`
+	UserID     string           `json:"user" yaml:"user"`                         // Usuário que fez login; vazio usa o da sessão
+	TTY        string           `json:"tty,omitempty" yaml:"tty,omitempty"`       // Terminal de origem
+	Remote     string           `json:"remote,omitempty" yaml:"remote,omitempty"` // Cliente SSH de origem
+	Action     string           `json:"action" yaml:"action"`
+	Interface  string           `json:"interface,omitempty" yaml:"interface,omitempty"` // Interface ou túnel afetado
+	Profile    string           `json:"profile,omitempty" yaml:"profile,omitempty"`     // Perfil afetado
+	Details    string           `json:"details,omitempty" yaml:"details,omitempty"`
+	Changes    string           `json:"changes,omitempty" yaml:"changes,omitempty"`
+	Diff       []backend.Change `json:"diff,omitempty" yaml:"diff,omitempty"`         // Propriedades antes e depois
+	Snapshot   backend.Settings `json:"snapshot,omitempty" yaml:"snapshot,omitempty"` // Valores anteriores do perfil, para desfazer
+	Outcome    string           `json:"outcome,omitempty" yaml:"outcome,omitempty"`
+	Error      string           `json:"error,omitempty" yaml:"error,omitempty"`
+	ModifiedBy string           `json:"modified_by" yaml:"modified_by"` // tui, cli, safeapply ou system
+}
+
+// WithResult retorna a ação com o resultado da operação
+func (a Action) WithResult(err error) Action {
+	a.Outcome, a.Error = OutcomeSuccess, ""
+	if err != nil {
+		a.Outcome, a.Error = OutcomeFailure, err.Error()
+	}
+	return a
+}
+
+// Undoable informa se a ação guardou o estado anterior do perfil e pode ser
+// desfeita. Na remoção de um perfil, o estado anterior é o perfil inteiro.
+func (a Action) Undoable() bool {
+	return a.Profile != "" && len(a.Snapshot) > 0
+}
+
+// Origin descreve de onde a ação foi feita: o terminal e, se remota, o
+// cliente SSH
+func (a Action) Origin() string {
+	switch {
+	case a.Remote != "" && a.TTY != "":
+		return a.TTY + " (ssh " + a.Remote + ")"
+	case a.Remote != "":
+		return "ssh " + a.Remote
+	}
+	return a.TTY
+}
+
+// Diff lista as propriedades que mudam de before para after, inclusive as
+// que deixam de existir (como na remoção do perfil), com os segredos
+// mascarados
+func Diff(before, after backend.Settings) []backend.Change {
+	all := after.Clone()
+	for key := range before {
+		if _, ok := all[key]; !ok {
+			all[key] = ""
+		}
+	}
+	var changes []backend.Change
+	for _, change := range backend.DiffSettings(before.Masked(), all.Masked()) {
+		if change.Changed() {
+			changes = append(changes, change)
+		}
+	}
+	return changes
 }
 
 var (
-	actions []Action
-	mutex   sync.RWMutex
+	mutex     sync.Mutex
+	dir       = DefaultDir
+	forwarder func(Action)
 )
 
-func AddAction(userID, actionType, details string, changes string, modifiedBy string) {
+// SetDir define o diretório do histórico (usado no modo -dev)
+func SetDir(d string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	dir = d
+}
+
+// SetForwarder define a função que recebe cada ação registrada, para
+// encaminhá-la a um coletor central (ver o pacote forward)
+func SetForwarder(f func(Action)) {
 	mutex.Lock()
 	defer mutex.Unlock()
+	forwarder = f
+}
+
+// AddAction registra uma ação que não altera perfis, como o início da
+// aplicação e o acesso aos menus. Alterações usam Record, com Diff e o
+// resultado (WithResult).
+func AddAction(actionType, details string, changes string, modifiedBy string) {
+	Record(Action{
+		Action:     actionType,
+		Details:    details,
+		Changes:    changes,
+		ModifiedBy: modifiedBy,
+	})
+}
+
+// Snapshot guarda os valores anteriores (before) das propriedades alteradas
+// em diff, para que a ação possa ser desfeita. Senhas e chaves nunca são
+// guardadas no histórico.
+func Snapshot(before backend.Settings, diff []backend.Change) backend.Settings {
+	before = before.WithoutSecrets()
+	snapshot := backend.Settings{}
+	for _, change := range diff {
+		if !backend.IsSecret(change.Key) {
+			snapshot[change.Key] = before[change.Key]
+		}
+	}
+	if len(snapshot) == 0 {
+		return nil
+	}
+	return snapshot
+}
+
+// Record acrescenta a ação ao histórico, com a hora atual se Timestamp for
+// zero e o usuário e a origem da sessão se UserID for vazio, e a encaminha
+// ao coletor, se houver. Falhas de gravação vão para o log e não interrompem
+// a operação.
+func Record(action Action) {
+	if action.Timestamp.IsZero() {
+		action.Timestamp = time.Now()
+	}
+	if action.ID == "" {
+		action.ID = newID()
+	}
+	if action.UserID == "" {
+		s := CurrentSession()
+		action.UserID, action.TTY, action.Remote = s.User, s.TTY, s.Remote
+	}
+
+	mutex.Lock()
+	err := appendAction(action)
+	send := forwarder
+	mutex.Unlock()
+	if err != nil {
+		logger.LogError("Erro ao gravar o histórico: %v", err)
+	}
+	logger.Info("Ação registrada no histórico", "id", action.ID, "action", action.Action, "user", action.UserID,
+		"via", action.ModifiedBy, "interface", action.Interface, "outcome", action.Outcome, "details", action.Details)
+	if send != nil {
+		send(action)
+	}
+}
+
+// newID gera o identificador de uma ação
+func newID() string {
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(id)
+}
+
+// appendAction grava a ação como uma linha JSON, rotacionando o arquivo antes
+// se ele já atingiu o tamanho máximo
+func appendAction(action Action) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	unlock, err := lockHistory()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	path := filepath.Join(dir, currentFile)
+	if info, err := os.Stat(path); err == nil && info.Size() >= MaxFileSize {
+		if err := rotate(path); err != nil {
+			return err
+		}
+	}
+
+	line, err := json.Marshal(action)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	// Uma só escrita por linha, para que processos simultâneos não misturem
+	// as linhas
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// lockHistory impede que outro processo (a interface, a linha de comando ou
+// a guarda da aplicação segura) rotacione o arquivo enquanto este grava ou
+// rotaciona. O bloqueio fica em um arquivo à parte, que não é renomeado.
+func lockHistory() (func(), error) {
+	file, err := os.OpenFile(filepath.Join(dir, lockFile), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return func() {
+		syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		file.Close()
+	}, nil
+}
+
+// rotate renomeia o arquivo atual e remove os rotacionados mais antigos
+func rotate(path string) error {
+	rotated := filepath.Join(dir, rotatedPrefix+time.Now().Format(rotatedLayout)+".jsonl")
+	if err := os.Rename(path, rotated); err != nil {
+		return err
+	}
+
+	files, err := rotatedFiles()
+	if err != nil {
+		return err
+	}
+	for len(files) > MaxRotatedFiles {
+		if err := os.Remove(files[0]); err != nil {
+			return err
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+// rotatedFiles lista os arquivos rotacionados do mais antigo ao mais novo
+func rotatedFiles() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, rotatedPrefix+"*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Filter seleciona as ações na consulta ao histórico. Campos vazios não
+// filtram.
+type Filter struct {
+	Since     time.Time // Ações a partir deste instante
+	Until     time.Time // Ações até este instante
+	User      string
+	Action    string // Tipo exato ou prefixo ("vpn" inclui vpn_up e vpn_import)
+	Interface string
+	Limit     int // Só as últimas N ações
+}
+
+// Match informa se a ação atende ao filtro
+func (f Filter) Match(a Action) bool {
+	switch {
+	case !f.Since.IsZero() && a.Timestamp.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && a.Timestamp.After(f.Until):
+		return false
+	case f.User != "" && a.UserID != f.User:
+		return false
+	case f.Interface != "" && a.Interface != f.Interface:
+		return false
+	case f.Action != "" && a.Action != f.Action && !strings.HasPrefix(a.Action, f.Action+"_"):
+		return false
+	}
+	return true
+}
+
+// Query lê o histórico, incluindo os arquivos rotacionados, e retorna as
+// ações que atendem ao filtro em ordem cronológica
+func Query(f Filter) ([]Action, error) {
+	mutex.Lock()
+	files, err := rotatedFiles()
+	current := filepath.Join(dir, currentFile)
+	mutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	actions := []Action{}
+	for _, name := range append(files, current) {
+		loaded, err := readActions(name, f)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, loaded...)
+	}
+
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actions[i].Timestamp.Before(actions[j].Timestamp)
+	})
+	if f.Limit > 0 && len(actions) > f.Limit {
+		actions = actions[len(actions)-f.Limit:]
+	}
+	return actions, nil
+}
+
+// Find retorna a ação com o identificador informado
+func Find(id string) (Action, error) {
+	actions, err := Query(Filter{})
+	if err != nil {
+		return Action{}, err
+	}
+	for i := len(actions) - 1; i >= 0; i-- {
+		if actions[i].ID == id {
+			return actions[i], nil
+		}
+	}
+	return Action{}, fmt.Errorf("%w: ação %s no histórico", backend.ErrNotFound, id)
+}
+
+// LastUndoable retorna a ação mais recente que pode ser desfeita
+func LastUndoable() (Action, error) {
+	actions, err := Query(Filter{})
+	if err != nil {
+		return Action{}, err
+	}
+	for i := len(actions) - 1; i >= 0; i-- {
+		if actions[i].Undoable() {
+			return actions[i], nil
+		}
+	}
+	return Action{}, fmt.Errorf("%w: nenhuma alteração que possa ser desfeita no histórico", backend.ErrNotFound)
+}
+
+// readActions lê as ações de um arquivo que atendem ao filtro. Linhas
+// ilegíveis (como a última, se a gravação foi interrompida) são ignoradas.
+func readActions(name string, f Filter) ([]Action, error) {
+	file, err := os.Open(name)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir o histórico: %w", err)
+	}
+	defer file.Close()
 
-	action := Action{
-		Timestamp:   time.Now(),
-		UserID:      userID,
-		Action:      actionType,
-		Details:     details,
-		Changes:     changes,
-		ModifiedBy:  modifiedBy,
-	}
-	actions = append(actions, action)
-
-	// Log a ação com detalhes extras
-	logAction(action)
-}
-
-func GetHistory() []Action {
-	mutex.RLock()
-	defer mutex.RUnlock()
-
-	history := make([]Action, len(actions))
-	copy(history, actions)
-	return history
-}
-
-func logAction(action Action) {
-	logEntry := fmt.Sprintf(`
-=== Log de Ação ===
-Data/Hora: %s
-Usuário: %s
-Ação: %s
-Detalhes: %s
-Modificado por: %s
-Alterações: %s
-==================`,
-		action.Timestamp.Format("02/01/2006 15:04:05"),
-		action.UserID,
-		action.Action, 
-		action.Details,
-		action.ModifiedBy,
-		action.Changes)
-	
-	logger.LogInfo(logEntry)
+	var actions []Action
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		var a Action
+		if len(line) > 0 && json.Unmarshal(line, &a) == nil && f.Match(a) {
+			actions = append(actions, a)
+		}
+		if err == io.EOF {
+			return actions, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler %s: %w", name, err)
+		}
+	}
+}
+
+// ParseSince interpreta o início de um filtro: uma data ("2006-01-02"), data
+// e hora ("2006-01-02 15:04"), RFC 3339 ou um tempo decorrido até agora
+// ("90m", "24h", "7d")
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	t, _, err := parseTime(value, now)
+	return t, err
+}
+
+// ParseUntil interpreta o fim de um filtro, nos formatos de ParseSince; uma
+// data sem hora inclui o dia inteiro
+func ParseUntil(value string, now time.Time) (time.Time, error) {
+	t, dateOnly, err := parseTime(value, now)
+	if dateOnly {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, err
+}
+
+// parseTime interpreta os formatos de ParseSince e informa se o valor é só
+// uma data
+func parseTime(value string, now time.Time) (t time.Time, dateOnly bool, err error) {
+	value = strings.TrimSpace(value)
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil && days >= 0 {
+			return now.AddDate(0, 0, -days), false, nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), false, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, false, nil
+		}
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, true, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	return time.Time{}, false, fmt.Errorf("data inválida: %s (use 2006-01-02, \"2006-01-02 15:04\", RFC 3339 ou 24h/7d)", value)
 }
@@ -0,0 +1,80 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Processos e ações de TestConcurrentWriters; o tamanho dos detalhes faz o
+// arquivo ser rotacionado algumas vezes durante o teste
+const (
+	testWriters     = 8
+	testActions     = 30
+	testDetailsSize = 256 << 10
+	envWriterDir    = "NMTUI_HISTORY_TEST_DIR"
+)
+
+// TestWriterProcess grava as ações de um dos processos de
+// TestConcurrentWriters
+func TestWriterProcess(t *testing.T) {
+	d := os.Getenv(envWriterDir)
+	if d == "" {
+		t.Skip("executado apenas por TestConcurrentWriters")
+	}
+	SetDir(d)
+	details := strings.Repeat("x", testDetailsSize)
+	for i := 0; i < testActions; i++ {
+		Record(Action{Action: "test", UserID: "root", Details: details, ModifiedBy: "cli"})
+	}
+}
+
+func TestConcurrentWriters(t *testing.T) {
+	d := t.TempDir()
+	var cmds []*exec.Cmd
+	for i := 0; i < testWriters; i++ {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestWriterProcess$")
+		cmd.Env = append(os.Environ(), envWriterDir+"="+d)
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		cmds = append(cmds, cmd)
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(d, "*.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) < 2 {
+		t.Errorf("arquivo não rotacionado: %v", files)
+	}
+	count := 0
+	for _, name := range files {
+		file, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 0, 2*testDetailsSize), 4*testDetailsSize)
+		for scanner.Scan() {
+			var a Action
+			if err := json.Unmarshal(scanner.Bytes(), &a); err != nil {
+				t.Errorf("linha inválida em %s: %v", filepath.Base(name), err)
+			}
+			count++
+		}
+		file.Close()
+	}
+	if count != testWriters*testActions {
+		t.Errorf("%d ações no histórico, esperadas %d", count, testWriters*testActions)
+	}
+}
@@ -0,0 +1,123 @@
+package history
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// UID de /proc/self/loginuid quando o processo não pertence a um login
+const unsetLoginUID = "4294967295"
+
+// Session identifica quem executa o programa e de onde
+type Session struct {
+	User   string // Usuário que fez login, mesmo sob sudo
+	TTY    string // Terminal de controle (pts/0, tty1)
+	Remote string // Endereço do cliente SSH, se a sessão for remota
+}
+
+var (
+	sessionOnce sync.Once
+	session     Session
+)
+
+// CurrentSession retorna a sessão do processo, detectada na primeira chamada
+func CurrentSession() Session {
+	sessionOnce.Do(func() {
+		session = Session{User: loginUser(), TTY: terminal(), Remote: sshClient()}
+	})
+	return session
+}
+
+// loginUser identifica o usuário que fez login: SUDO_USER sob sudo, o dono da
+// sessão de auditoria (loginuid) sob su ou serviços, e o usuário do processo
+// nos demais casos
+func loginUser() string {
+	if name := os.Getenv("SUDO_USER"); name != "" {
+		return name
+	}
+	if data, err := os.ReadFile("/proc/self/loginuid"); err == nil {
+		if uid := strings.TrimSpace(string(data)); uid != "" && uid != unsetLoginUID {
+			if u, err := user.LookupId(uid); err == nil {
+				return u.Username
+			}
+			return uid
+		}
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return strconv.Itoa(os.Getuid())
+}
+
+// terminal retorna o terminal ligado à entrada ou à saída do processo
+func terminal() string {
+	for fd := 0; fd <= 2; fd++ {
+		target, err := os.Readlink(fmt.Sprintf("/proc/self/fd/%d", fd))
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(target, "/dev/pts/") || strings.HasPrefix(target, "/dev/tty") ||
+			target == "/dev/console" {
+			return strings.TrimPrefix(target, "/dev/")
+		}
+	}
+	return ""
+}
+
+// sshClient retorna o endereço do cliente SSH. O sudo normalmente descarta
+// SSH_CONNECTION, então a variável também é procurada nos processos pais.
+func sshClient() string {
+	for _, key := range []string{"SSH_CONNECTION", "SSH_CLIENT"} {
+		if fields := strings.Fields(ancestorEnv(key)); len(fields) > 0 {
+			return fields[0]
+		}
+	}
+	return ""
+}
+
+// ancestorEnv procura a variável no ambiente do processo e, se não estiver
+// definida, no dos processos pais
+func ancestorEnv(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	pid := os.Getppid()
+	for depth := 0; depth < 16 && pid > 1; depth++ {
+		if data, err := os.ReadFile(fmt.Sprintf("/proc/%d/environ", pid)); err == nil {
+			prefix := []byte(key + "=")
+			for _, entry := range bytes.Split(data, []byte{0}) {
+				if bytes.HasPrefix(entry, prefix) && len(entry) > len(prefix) {
+					return string(entry[len(prefix):])
+				}
+			}
+		}
+		if pid = parentPID(pid); pid == 0 {
+			break
+		}
+	}
+	return ""
+}
+
+// parentPID lê o processo pai em /proc/<pid>/stat; o nome do comando, entre
+// parênteses, pode conter espaços
+func parentPID(pid int) int {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0
+	}
+	end := bytes.LastIndexByte(data, ')')
+	if end < 0 {
+		return 0
+	}
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 2 {
+		return 0
+	}
+	ppid, _ := strconv.Atoi(fields[1])
+	return ppid
+}
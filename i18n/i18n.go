@@ -14,6 +14,12 @@ var translations = map[string]map[string]string{
         "en": {
                 "menu_title":        "Network Manager TUI",
                 "menu_configure":    "Configure Network",
+                "menu_virtual":      "Virtual Interfaces",
+                "menu_vpn":          "VPN Tunnels",
+                "menu_wifi":         "Wi-Fi Networks",
+                "menu_profiles":     "Saved Profiles",
+                "menu_desired":      "Desired State",
+                "menu_history":      "Action History",
                 "menu_status":       "Network Status",
                 "menu_ping_test":    "Ping Test",
                 "menu_sysinfo":      "System Information",
@@ -36,6 +42,9 @@ var translations = map[string]map[string]string{
                 "network_title":     "Configure Network",
                 "network_status":    "Network Status",
                 "network_interface": "Network Interface (e.g., eth0):",
+                "network_profile":   "Connection Profile:",
+                "network_profile_new": "+ New profile: %s",
+                "network_profile_created": "(will be created)",
                 "network_dhcp":      "DHCP:",
                 "network_ipv4":      "IPv4 Address:",
                 "network_ipv6":      "IPv6 Address:",
@@ -47,8 +56,103 @@ var translations = map[string]map[string]string{
                 "network_dns2":      "Secondary DNS (optional):",
                 "network_save":      "Save",
                 "network_cancel":    "Cancel",
+                "network_ipv4_advanced": "IPv4 Advanced",
+                "network_ipv6_advanced": "IPv6 Advanced",
+                "network_extra_addresses": "Additional addresses (manual mode):",
+                "network_routes":    "Static routes:",
+                "network_routing_rules": "Routing rules:",
+                "network_dns_search": "DNS search domains:",
+                "network_route_metric": "Route metric (-1 = default):",
+                "network_never_default": "Never use as default route:",
+                "network_routing_help": "One item per line. Addresses: 10.0.0.5/24. Routes: destination/prefix [next-hop] [metric] [table=N] [src=IP] [mtu=N]...\n" +
+                "Rules: priority N [from X/len] [to Y/len] [iif name] [oif name] [fwmark M] table T. Search domains separated by commas.",
+                "network_preview":            "Review changes",
+                "network_preview_profile":    "Profile:",
+                "network_preview_property":   "Property",
+                "network_preview_current":    "Current",
+                "network_preview_new":        "New",
+                "network_preview_commands":   "Commands to be executed:",
+                "network_preview_no_commands": "(this backend does not run external commands)",
+                "network_preview_apply":      "Apply",
+                "network_preview_back":       "Back to edit",
+                "virtual_title":     "Create Virtual Interface",
+                "virtual_kind":      "Type:",
+                "virtual_name":      "Interface name:",
+                "virtual_parent":    "Parent device:",
+                "virtual_vlan_id":   "VLAN ID (1-4094):",
+                "virtual_ports":     "Port devices:",
+                "virtual_bond_mode": "Bond mode:",
+                "virtual_bond_miimon": "Link monitoring (ms):",
+                "virtual_bond_primary": "Primary port:",
+                "virtual_none":      "(none)",
+                "virtual_bridge_stp": "Spanning Tree (STP):",
+                "virtual_team_runner": "Team runner:",
+                "virtual_ip_help":   "IP settings apply to %s; the ports carry no IP configuration.",
+                "virtual_next":      "Next",
+                "virtual_create":    "Create",
+                "virtual_created":   "Interface %s created with the profiles: %s",
+                "virtual_no_ports":  "No Ethernet device available for the virtual interface",
+                "vpn_title":         "VPN Tunnels",
+                "vpn_remote":        "Server",
+                "vpn_handshake":     "Last handshake",
+                "vpn_toggle":        "Connect/Disconnect",
+                "vpn_peers":         "Peers",
+                "vpn_import":        "Import",
+                "vpn_actions":       "Actions",
+                "vpn_empty":         "No VPN profiles. Use Import to add a WireGuard or OpenVPN file.",
+                "vpn_active":        "connected",
+                "vpn_inactive":      "disconnected",
+                "vpn_never":         "never",
+                "profiles_title":    "Saved Connection Profiles",
+                "profiles_empty":    "No saved profile",
+                "profiles_autoconnect": "Autoconnect",
+                "profiles_priority": "Priority",
+                "profiles_last_used": "Last Used",
+                "profiles_never":    "never",
+                "profiles_yes":      "yes",
+                "profiles_no":       "no",
+                "profiles_clone":    "Clone",
+                "profiles_rename":   "Rename",
+                "profiles_delete":   "Delete",
+                "profiles_delete_confirm": "Delete profile %s? This cannot be undone.",
+                "profiles_delete_active": "The profile is active and its connection will be closed.",
+                "profiles_name_help": "The name must not be used by another profile.",
+                "profiles_priority_help": "Among profiles that can autoconnect on the same device, the highest priority is activated first (-999 to 999).",
+                "profiles_export":   "Export",
+                "profiles_import":   "Import",
+                "bundle_file":       "File",
+                "bundle_only_selected": "Only the selected profile",
+                "bundle_secrets":    "Include passwords and keys",
+                "bundle_device_map": "Device mapping",
+                "bundle_activate":   "Activate after importing",
+                "bundle_export_help": "Saves the profiles to a JSON or YAML file (by the extension) to import on another host. Passwords and private keys are left out unless included; the file is created with permission 0600.",
+                "bundle_import_help": "Device mapping renames the devices of the original host, e.g. eth0=ens3,eth1=ens4. Profiles with the same name are updated.",
+                "bundle_origin":     "Bundle from %s, exported on %s (version %d)",
+                "bundle_updated":    "(will be updated)",
+                "bundle_empty":      "The bundle has no profiles",
+                "bundle_missing_device": "device %s does not exist on this host",
+                "desired_title":     "Desired Network State",
+                "desired_check":     "Check again",
+                "desired_help":      "Changes are applied with \"networkmanager-tui reconcile\"",
+                "desired_empty":     "The desired state has no interfaces or Wi-Fi networks",
+                "desired_in_sync":   "in sync",
+                "desired_drift":     "out of sync",
+                "desired_activate":  "(will be activated)",
+                "vpn_ago":           "%s ago",
+                "vpn_file":          "File (.conf or .ovpn):",
+                "vpn_peer_help":     "One peer per line: <public-key> allowed-ips=10.0.0.0/24;fd00::/64 endpoint=host:port persistent-keepalive=25. Preshared keys are kept.",
+                "vpn_import_help":   "WireGuard: the file name becomes the interface name (wg0.conf → wg0). OpenVPN requires the NetworkManager OpenVPN plugin.",
                 "network_back":      "Back",
                 "network_refresh":   "Refresh",
+                "network_live_updates": "Live updates",
+                "network_safe_apply": "Safe Apply",
+                "network_confirm_timeout": "Confirm within (s, 0 = off):",
+                "network_check_host": "Reachability check host:",
+                "safe_apply_countdown": "Configuration applied to %s.\n\nConfirm within %d seconds or the previous settings will be restored automatically.",
+                "safe_apply_confirm": "Confirm",
+                "safe_apply_rollback": "Roll back now",
+                "safe_apply_confirmed": "Configuration confirmed.",
+                "safe_apply_rolled_back": "The previous network settings were restored.",
                 "network_device":    "Device",
                 "network_type":      "Type",
                 "network_state":     "Status",
@@ -110,10 +214,123 @@ var translations = map[string]map[string]string{
                 
                 "refresh":           "Refresh",
                 "back":              "Back",
+
+                "cli_usage":         "Usage: networkmanager-tui [-dev] [command] [options]\n\n" +
+                "Without a command, the terminal interface is started.\n\n" +
+                "Commands:\n" +
+                "  status                          Show the network connections\n" +
+                "  configure <iface> [options]     Configure IPv4/IPv6 of an interface (--dry-run to preview)\n" +
+                "  virtual <kind> <name> [options] Create a VLAN, bond, bridge or team interface\n" +
+                "  confirm                         Confirm changes applied with --confirm-timeout\n" +
+                "  rollback                        Restore the settings prior to pending changes\n" +
+                "  ping <host> [-c count]          Test connectivity\n" +
+                "  vpn <list|import|up|down|peers>  Manage WireGuard and OpenVPN tunnels\n" +
+                "  profile <list|clone|rename|delete|autoconnect|priority|export|import>  Manage, export and import saved connection profiles\n" +
+                "  reconcile [--file <file>] [--check]  Apply the desired-state file (--check only reports drift)\n" +
+                "  wifi scan [--rescan]            List visible Wi-Fi networks\n" +
+                "  wifi connect <ssid> [options]   Connect to a Wi-Fi network\n" +
+                "  history [--since] [--until] [--user] [--action] [--interface]  Show the audit history, with filters\n" +
+                "  history undo [id] [--dry-run]   Undo a configuration change (default: the last one)\n" +
+                "  forward <status|flush|test>     Show or deliver the audit history queued for the collector\n" +
+                "  sysinfo                         Show system information\n",
+                "cli_unknown_command": "Unknown command",
+                "cli_configured":    "Configuration applied to",
+                "cli_wifi_connected": "Connected to",
+                "cli_virtual_created": "Interface created",
+                "cli_vpn_imported":  "VPN imported:",
+                "cli_vpn_up":        "VPN connected:",
+                "cli_vpn_down":      "VPN disconnected:",
+                "cli_vpn_peers_saved": "Peers saved for",
+                "cli_profile_cloned": "Profile created:",
+                "cli_profile_renamed": "Profile renamed to",
+                "cli_profile_deleted": "Profile deleted:",
+                "cli_profile_not_deleted": "Profile not deleted",
+                "cli_profile_updated": "Profile updated:",
+                "cli_bundle_exported": "%d profiles exported to %s",
+                "cli_bundle_imported": "%d profiles imported",
+                "cli_drift_found":   "%d items differ from the desired state",
+                "cli_reconciled":    "%d items changed",
+                "cli_pending_confirm": "Run \"networkmanager-tui confirm\" within %d seconds or the previous settings will be restored.",
+                "cli_confirmed":     "Confirmed changes on",
+                "cli_rolled_back":   "Restored previous settings on",
+
+                "wifi_ssid":         "SSID",
+                "wifi_bssid":        "BSSID",
+                "wifi_signal":       "Signal",
+                "wifi_security":     "Security",
+                "wifi_secured":      "Secured",
+                "wifi_open":         "Open",
+                "wifi_title":        "Wi-Fi Networks",
+                "wifi_connect":      "Connect",
+                "wifi_rescan":       "Rescan",
+                "wifi_password":     "Password",
+                "wifi_password_help": "Leave empty to use the password saved for this network.",
+                "wifi_empty":        "No Wi-Fi network found",
+                "wifi_channel":      "Channel",
+                "wifi_band":         "Band",
+                "wifi_frequency":    "Frequency",
+                "wifi_bssids":       "BSSIDs",
+                "wifi_details":      "Access Points",
+                "wifi_sort":         "Sort",
+                "wifi_sort_signal":  "signal",
+                "wifi_sort_channel": "channel",
+                "wifi_sort_ssid":    "SSID",
+                "wifi_auto_rescan":  "Rescanning every %d s",
+                "wifi_hidden":       "Hidden Network",
+                "wifi_hidden_help":  "Networks that do not broadcast their SSID are not listed in the scan. The profile is saved as hidden so the device probes for it by name.",
+                "network_8021x":     "802.1X Authentication",
+                "eap_disabled":      "(disabled)",
+                "eap_method":        "EAP method",
+                "eap_identity":      "Identity",
+                "eap_anonymous_identity": "Anonymous identity",
+                "eap_ca_cert":       "CA certificate",
+                "eap_client_cert":   "Client certificate",
+                "eap_private_key":   "Private key",
+                "eap_private_key_password": "Private key password",
+                "eap_phase2":        "Phase 2 (PEAP/TTLS)",
+                "eap_password":      "Password",
+                "eap_help":          "PEAP/TTLS: identity, password and phase 2. EAP-TLS: client certificate and private key. Certificates are PEM file paths; empty passwords keep the saved ones.",
+
+                "history_time":      "Date/Time",
+                "history_user":      "User",
+                "history_action":    "Action",
+                "history_details":   "Details",
+                "history_empty":     "No actions recorded",
+                "history_title":     "Action History",
+                "history_interface": "Interface",
+                "history_via":       "Via",
+                "history_changes":   "Changes",
+                "history_origin":    "Origin",
+                "history_profile":   "Profile",
+                "history_outcome":   "Outcome",
+                "history_outcome_success": "Success",
+                "history_outcome_failure": "Failure",
+                "history_outcome_pending": "Pending",
+                "history_undo":      "Undo",
+                "history_undone":    "Change undone: the profile is back to its previous values.",
+                "history_undo_skipped": "Passwords and keys are not kept in the history and were not restored: %s",
+                "cli_undone":        "Undone:",
+                "forward_disabled":  "Audit forwarding is not configured (set NMTUI_FORWARD_URL or -forward-url)",
+                "forward_collector": "Collector:",
+                "forward_queue":     "Queue:",
+                "forward_queued":    "Waiting for delivery:",
+                "forward_oldest":    "Oldest action:",
+                "cli_forward_flushed": "%d actions forwarded",
+                "cli_forward_test":  "Test action delivered to the collector",
+                "history_since":     "Since",
+                "history_until":     "Until",
+                "history_filter":    "Filter",
+                "history_help":      "Dates: 2006-01-02, \"2006-01-02 15:04\" or elapsed time (24h, 7d). Action accepts a prefix (vpn). Enter: details and undo • Tab: filters •",
         },
         "pt": {
                 "menu_title":        "Gerenciador de Rede TUI",
                 "menu_configure":    "Configurar Rede",
+                "menu_virtual":      "Interfaces Virtuais",
+                "menu_vpn":          "Túneis VPN",
+                "menu_wifi":         "Redes Wi-Fi",
+                "menu_profiles":     "Perfis Salvos",
+                "menu_desired":      "Estado Desejado",
+                "menu_history":      "Histórico de Ações",
                 "menu_status":       "Status da Rede",
                 "menu_ping_test":    "Teste de Ping",
                 "menu_sysinfo":      "Informações do Sistema",
@@ -136,6 +353,9 @@ var translations = map[string]map[string]string{
                 "network_title":     "Configurar Rede",
                 "network_status":    "Status da Rede",
                 "network_interface": "Interface de Rede (ex.: eth0):",
+                "network_profile":   "Perfil de Conexão:",
+                "network_profile_new": "+ Novo perfil: %s",
+                "network_profile_created": "(será criado)",
                 "network_dhcp":      "DHCP:",
                 "network_ipv4":      "Endereço IPv4:",
                 "network_ipv6":      "Endereço IPv6:",
@@ -147,8 +367,103 @@ var translations = map[string]map[string]string{
                 "network_dns2":      "DNS Secundário (opcional):",
                 "network_save":      "Salvar",
                 "network_cancel":    "Cancelar",
+                "network_ipv4_advanced": "IPv4 Avançado",
+                "network_ipv6_advanced": "IPv6 Avançado",
+                "network_extra_addresses": "Endereços adicionais (modo manual):",
+                "network_routes":    "Rotas estáticas:",
+                "network_routing_rules": "Regras de roteamento:",
+                "network_dns_search": "Domínios de busca DNS:",
+                "network_route_metric": "Métrica das rotas (-1 = padrão):",
+                "network_never_default": "Nunca usar como rota padrão:",
+                "network_routing_help": "Um item por linha. Endereços: 10.0.0.5/24. Rotas: destino/prefixo [próximo-salto] [métrica] [table=N] [src=IP] [mtu=N]...\n" +
+                "Regras: priority N [from X/len] [to Y/len] [iif nome] [oif nome] [fwmark M] table T. Domínios de busca separados por vírgula.",
+                "network_preview":            "Revisar alterações",
+                "network_preview_profile":    "Perfil:",
+                "network_preview_property":   "Propriedade",
+                "network_preview_current":    "Atual",
+                "network_preview_new":        "Novo",
+                "network_preview_commands":   "Comandos que serão executados:",
+                "network_preview_no_commands": "(este backend não executa comandos externos)",
+                "network_preview_apply":      "Aplicar",
+                "network_preview_back":       "Voltar e editar",
+                "virtual_title":     "Criar Interface Virtual",
+                "virtual_kind":      "Tipo:",
+                "virtual_name":      "Nome da interface:",
+                "virtual_parent":    "Dispositivo pai:",
+                "virtual_vlan_id":   "ID da VLAN (1-4094):",
+                "virtual_ports":     "Dispositivos de porta:",
+                "virtual_bond_mode": "Modo do bond:",
+                "virtual_bond_miimon": "Monitoramento do link (ms):",
+                "virtual_bond_primary": "Porta primária:",
+                "virtual_none":      "(nenhuma)",
+                "virtual_bridge_stp": "Spanning Tree (STP):",
+                "virtual_team_runner": "Runner do team:",
+                "virtual_ip_help":   "A configuração IP vale para %s; as portas não recebem configuração IP.",
+                "virtual_next":      "Avançar",
+                "virtual_create":    "Criar",
+                "virtual_created":   "Interface %s criada com os perfis: %s",
+                "virtual_no_ports":  "Nenhum dispositivo Ethernet disponível para a interface virtual",
+                "vpn_title":         "Túneis VPN",
+                "vpn_remote":        "Servidor",
+                "vpn_handshake":     "Último handshake",
+                "vpn_toggle":        "Conectar/Desconectar",
+                "vpn_peers":         "Peers",
+                "vpn_import":        "Importar",
+                "vpn_actions":       "Ações",
+                "vpn_empty":         "Nenhum perfil de VPN. Use Importar para adicionar um arquivo WireGuard ou OpenVPN.",
+                "vpn_active":        "conectada",
+                "vpn_inactive":      "desconectada",
+                "vpn_never":         "nunca",
+                "profiles_title":    "Perfis de Conexão Salvos",
+                "profiles_empty":    "Nenhum perfil salvo",
+                "profiles_autoconnect": "Conexão Automática",
+                "profiles_priority": "Prioridade",
+                "profiles_last_used": "Último Uso",
+                "profiles_never":    "nunca",
+                "profiles_yes":      "sim",
+                "profiles_no":       "não",
+                "profiles_clone":    "Copiar",
+                "profiles_rename":   "Renomear",
+                "profiles_delete":   "Remover",
+                "profiles_delete_confirm": "Remover o perfil %s? Esta ação não pode ser desfeita.",
+                "profiles_delete_active": "O perfil está ativo e a conexão será encerrada.",
+                "profiles_name_help": "O nome não pode ser usado por outro perfil.",
+                "profiles_priority_help": "Entre os perfis com conexão automática no mesmo dispositivo, o de maior prioridade é ativado primeiro (-999 a 999).",
+                "profiles_export":   "Exportar",
+                "profiles_import":   "Importar",
+                "bundle_file":       "Arquivo",
+                "bundle_only_selected": "Somente o perfil selecionado",
+                "bundle_secrets":    "Incluir senhas e chaves",
+                "bundle_device_map": "Troca de dispositivos",
+                "bundle_activate":   "Ativar após importar",
+                "bundle_export_help": "Grava os perfis em um arquivo JSON ou YAML (pela extensão) para importar em outro host. Senhas e chaves privadas ficam de fora, a menos que incluídas; o arquivo é criado com permissão 0600.",
+                "bundle_import_help": "A troca de dispositivos renomeia os dispositivos do host de origem, ex.: eth0=ens3,eth1=ens4. Perfis com o mesmo nome são alterados.",
+                "bundle_origin":     "Pacote de %s, exportado em %s (versão %d)",
+                "bundle_updated":    "(será alterado)",
+                "bundle_empty":      "O pacote não tem perfis",
+                "bundle_missing_device": "o dispositivo %s não existe neste host",
+                "desired_title":     "Estado de Rede Desejado",
+                "desired_check":     "Verificar novamente",
+                "desired_help":      "As alterações são aplicadas com \"networkmanager-tui reconcile\"",
+                "desired_empty":     "O estado desejado não tem interfaces nem redes Wi-Fi",
+                "desired_in_sync":   "em conformidade",
+                "desired_drift":     "divergente",
+                "desired_activate":  "(será ativado)",
+                "vpn_ago":           "há %s",
+                "vpn_file":          "Arquivo (.conf ou .ovpn):",
+                "vpn_peer_help":     "Um peer por linha: <chave-pública> allowed-ips=10.0.0.0/24;fd00::/64 endpoint=host:porta persistent-keepalive=25. As chaves compartilhadas são mantidas.",
+                "vpn_import_help":   "WireGuard: o nome do arquivo vira o nome da interface (wg0.conf → wg0). OpenVPN exige o plugin OpenVPN do NetworkManager.",
                 "network_back":      "Voltar",
                 "network_refresh":   "Atualizar",
+                "network_live_updates": "Atualização automática",
+                "network_safe_apply": "Aplicação Segura",
+                "network_confirm_timeout": "Confirmar em (s, 0 = desliga):",
+                "network_check_host": "Host para teste de alcance:",
+                "safe_apply_countdown": "Configuração aplicada em %s.\n\nConfirme em até %d segundos ou as configurações anteriores serão restauradas automaticamente.",
+                "safe_apply_confirm": "Confirmar",
+                "safe_apply_rollback": "Reverter agora",
+                "safe_apply_confirmed": "Configuração confirmada.",
+                "safe_apply_rolled_back": "As configurações de rede anteriores foram restauradas.",
                 "network_device":    "Dispositivo",
                 "network_type":      "Tipo",
                 "network_state":     "Status",
@@ -200,6 +515,113 @@ var translations = map[string]map[string]string{
                 
                 "refresh":           "Atualizar",
                 "back":              "Voltar",
+
+                "cli_usage":         "Uso: networkmanager-tui [-dev] [comando] [opções]\n\n" +
+                "Sem comando, a interface de terminal é iniciada.\n\n" +
+                "Comandos:\n" +
+                "  status                          Mostra as conexões de rede\n" +
+                "  configure <iface> [opções]      Configura IPv4/IPv6 de uma interface (--dry-run para revisar)\n" +
+                "  virtual <tipo> <nome> [opções]  Cria uma interface VLAN, bond, bridge ou team\n" +
+                "  confirm                         Confirma alterações aplicadas com --confirm-timeout\n" +
+                "  rollback                        Restaura as configurações anteriores às alterações pendentes\n" +
+                "  ping <host> [-c quantidade]     Testa a conectividade\n" +
+                "  vpn <list|import|up|down|peers>  Gerencia túneis WireGuard e OpenVPN\n" +
+                "  profile <list|clone|rename|delete|autoconnect|priority|export|import>  Gerencia, exporta e importa os perfis de conexão salvos\n" +
+                "  reconcile [--file <arquivo>] [--check]  Aplica o arquivo de estado desejado (--check só mostra as diferenças)\n" +
+                "  wifi scan [--rescan]            Lista as redes Wi-Fi visíveis\n" +
+                "  wifi connect <ssid> [opções]    Conecta a uma rede Wi-Fi\n" +
+                "  history [--since] [--until] [--user] [--action] [--interface]  Mostra o histórico de auditoria, com filtros\n" +
+                "  history undo [id] [--dry-run]   Desfaz uma alteração de configuração (padrão: a última)\n" +
+                "  forward <status|flush|test>     Mostra ou entrega o histórico na fila do coletor\n" +
+                "  sysinfo                         Mostra as informações do sistema\n",
+                "cli_unknown_command": "Comando desconhecido",
+                "cli_configured":    "Configuração aplicada em",
+                "cli_wifi_connected": "Conectado a",
+                "cli_virtual_created": "Interface criada",
+                "cli_vpn_imported":  "VPN importada:",
+                "cli_vpn_up":        "VPN conectada:",
+                "cli_vpn_down":      "VPN desconectada:",
+                "cli_vpn_peers_saved": "Peers salvos em",
+                "cli_profile_cloned": "Perfil criado:",
+                "cli_profile_renamed": "Perfil renomeado para",
+                "cli_profile_deleted": "Perfil removido:",
+                "cli_profile_not_deleted": "Perfil não removido",
+                "cli_profile_updated": "Perfil alterado:",
+                "cli_bundle_exported": "%d perfis exportados para %s",
+                "cli_bundle_imported": "%d perfis importados",
+                "cli_drift_found":   "%d itens divergem do estado desejado",
+                "cli_reconciled":    "%d itens alterados",
+                "cli_pending_confirm": "Execute \"networkmanager-tui confirm\" em até %d segundos ou as configurações anteriores serão restauradas.",
+                "cli_confirmed":     "Alterações confirmadas em",
+                "cli_rolled_back":   "Configurações anteriores restauradas em",
+
+                "wifi_ssid":         "SSID",
+                "wifi_bssid":        "BSSID",
+                "wifi_signal":       "Sinal",
+                "wifi_security":     "Segurança",
+                "wifi_secured":      "Protegida",
+                "wifi_open":         "Aberta",
+                "wifi_title":        "Redes Wi-Fi",
+                "wifi_connect":      "Conectar",
+                "wifi_rescan":       "Nova varredura",
+                "wifi_password":     "Senha",
+                "wifi_password_help": "Deixe vazio para usar a senha salva desta rede.",
+                "wifi_empty":        "Nenhuma rede Wi-Fi encontrada",
+                "wifi_channel":      "Canal",
+                "wifi_band":         "Faixa",
+                "wifi_frequency":    "Frequência",
+                "wifi_bssids":       "BSSIDs",
+                "wifi_details":      "Pontos de Acesso",
+                "wifi_sort":         "Ordenar",
+                "wifi_sort_signal":  "sinal",
+                "wifi_sort_channel": "canal",
+                "wifi_sort_ssid":    "SSID",
+                "wifi_auto_rescan":  "Nova varredura a cada %d s",
+                "wifi_hidden":       "Rede Oculta",
+                "wifi_hidden_help":  "Redes que não anunciam o SSID não aparecem na varredura. O perfil é salvo como oculto para que o dispositivo procure a rede pelo nome.",
+                "network_8021x":     "Autenticação 802.1X",
+                "eap_disabled":      "(desabilitada)",
+                "eap_method":        "Método EAP",
+                "eap_identity":      "Identidade",
+                "eap_anonymous_identity": "Identidade anônima",
+                "eap_ca_cert":       "Certificado da CA",
+                "eap_client_cert":   "Certificado do cliente",
+                "eap_private_key":   "Chave privada",
+                "eap_private_key_password": "Senha da chave privada",
+                "eap_phase2":        "Fase 2 (PEAP/TTLS)",
+                "eap_password":      "Senha",
+                "eap_help":          "PEAP/TTLS: identidade, senha e fase 2. EAP-TLS: certificado do cliente e chave privada. Certificados são caminhos de arquivos PEM; senhas vazias mantêm as salvas.",
+
+                "history_time":      "Data/Hora",
+                "history_user":      "Usuário",
+                "history_action":    "Ação",
+                "history_details":   "Detalhes",
+                "history_empty":     "Nenhuma ação registrada",
+                "history_title":     "Histórico de Ações",
+                "history_interface": "Interface",
+                "history_via":       "Via",
+                "history_changes":   "Alterações",
+                "history_origin":    "Origem",
+                "history_profile":   "Perfil",
+                "history_outcome":   "Resultado",
+                "history_outcome_success": "Sucesso",
+                "history_outcome_failure": "Falha",
+                "history_outcome_pending": "Pendente",
+                "history_undo":      "Desfazer",
+                "history_undone":    "Alteração desfeita: o perfil voltou aos valores anteriores.",
+                "history_undo_skipped": "Senhas e chaves não ficam no histórico e não foram restauradas: %s",
+                "cli_undone":        "Desfeita:",
+                "forward_disabled":  "O encaminhamento do histórico não está configurado (defina NMTUI_FORWARD_URL ou -forward-url)",
+                "forward_collector": "Coletor:",
+                "forward_queue":     "Fila:",
+                "forward_queued":    "Aguardando entrega:",
+                "forward_oldest":    "Ação mais antiga:",
+                "cli_forward_flushed": "%d ações encaminhadas",
+                "cli_forward_test":  "Ação de teste entregue ao coletor",
+                "history_since":     "Desde",
+                "history_until":     "Até",
+                "history_filter":    "Filtrar",
+                "history_help":      "Datas: 2006-01-02, \"2006-01-02 15:04\" ou tempo decorrido (24h, 7d). Ação aceita prefixo (vpn). Enter: detalhes e desfazer • Tab: filtros •",
         },
 }
 
@@ -1,11 +1,12 @@
 package network
 
 import (
+	"errors"
 	"fmt"
 	"net"
-	"os/exec"
-	"regexp"
 	"strings"
+
+	"networkmanager-tui/backend"
 )
 
 // Interface represents a network interface
@@ -37,63 +38,36 @@ type ConfigAppliedMsg struct {
 
 // GetInterfaces returns all network interfaces on the system
 func GetInterfaces() ([]Interface, error) {
-	// Get physical interfaces
-	netInterfaces, err := net.Interfaces()
+	devices, err := backend.Default().Devices()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get network interfaces: %w", err)
 	}
 
-	// Run ip addr to get additional info
-	ipAddrCmd := exec.Command("ip", "addr")
-	ipAddrOutput, err := ipAddrCmd.CombinedOutput()
-	if err != nil {
-		return nil, fmt.Errorf("failed to run 'ip addr': %w", err)
-	}
-
-	// Run ip route to get gateway info
-	ipRouteCmd := exec.Command("ip", "route")
-	ipRouteOutput, err := ipRouteCmd.CombinedOutput()
-	if err != nil {
-		return nil, fmt.Errorf("failed to run 'ip route': %w", err)
-	}
-
-	// Get DNS info
-	dnsInfo, err := getDNSServers()
-	if err != nil {
-		// Non-fatal, just use empty string
-		dnsInfo = ""
-	}
-
 	interfaces := []Interface{}
-
-	for _, iface := range netInterfaces {
+	for _, dev := range devices {
 		// Skip loopback interfaces
-		if iface.Flags&net.FlagLoopback != 0 {
+		if dev.Type == "loopback" || dev.Name == "lo" {
 			continue
 		}
 
-		// Check if interface is up
-		isActive := iface.Flags&net.FlagUp != 0
-
-		// Extract information from ip addr output
-		ipInfo := extractIPInfo(string(ipAddrOutput), iface.Name)
-		
-		// Extract gateway from ip route output
-		gateway := extractGateway(string(ipRouteOutput), iface.Name)
-
-		// Determine interface type
-		ifaceType := determineInterfaceType(iface.Name)
-
-		// Create interface object
 		netInterface := Interface{
-			Name:       iface.Name,
-			Type:       ifaceType,
-			IPAddress:  ipInfo.ipAddress,
-			SubnetMask: ipInfo.subnetMask,
-			Gateway:    gateway,
-			DNS:        dnsInfo,
-			MACAddress: iface.HardwareAddr.String(),
-			IsActive:   isActive,
+			Name:       dev.Name,
+			Type:       interfaceType(dev),
+			Gateway:    dev.Gateway,
+			DNS:        strings.Join(dev.DNS, ", "),
+			MACAddress: dev.MAC,
+			IsActive:   dev.State == "connected",
+		}
+
+		// Split the first IPv4 address into address and subnet mask
+		if len(dev.IPv4) > 0 {
+			addrParts := strings.Split(dev.IPv4[0], "/")
+			netInterface.IPAddress = addrParts[0]
+			if len(addrParts) == 2 {
+				if mask, err := cidrToMask(addrParts[1]); err == nil {
+					netInterface.SubnetMask = mask
+				}
+			}
 		}
 
 		interfaces = append(interfaces, netInterface)
@@ -118,103 +92,68 @@ func ApplyInterfaceConfig(iface Interface) error {
 		}
 	}
 
+	settings := backend.Settings{}
+
 	// Set IP address and subnet
 	if iface.IPAddress != "" && iface.SubnetMask != "" {
-		// Convert subnet mask to CIDR notation
 		cidr, err := maskToCIDR(iface.SubnetMask)
 		if err != nil {
 			return fmt.Errorf("failed to convert subnet mask to CIDR: %w", err)
 		}
-
-		// First flush existing IP addresses
-		flushCmd := exec.Command("ip", "addr", "flush", "dev", iface.Name)
-		if err := flushCmd.Run(); err != nil {
-			return fmt.Errorf("failed to flush IP addresses: %w", err)
-		}
-
-		// Set new IP address
-		addrCmd := exec.Command("ip", "addr", "add", iface.IPAddress+"/"+cidr, "dev", iface.Name)
-		if err := addrCmd.Run(); err != nil {
-			return fmt.Errorf("failed to set IP address: %w", err)
-		}
+		settings["ipv4.method"] = "manual"
+		settings["ipv4.addresses"] = iface.IPAddress + "/" + cidr
 	}
 
 	// Set default gateway if provided
 	if iface.Gateway != "" {
-		// First delete existing default route
-		delRouteCmd := exec.Command("ip", "route", "del", "default", "dev", iface.Name)
-		// Ignore errors - the route might not exist
-		delRouteCmd.Run()
-
-		// Add new default route
-		addRouteCmd := exec.Command("ip", "route", "add", "default", "via", iface.Gateway, "dev", iface.Name)
-		if err := addRouteCmd.Run(); err != nil {
-			return fmt.Errorf("failed to set default gateway: %w", err)
-		}
+		settings["ipv4.gateway"] = iface.Gateway
 	}
 
 	// Set DNS servers if provided
 	if iface.DNS != "" {
-		if err := setDNSServers(iface.DNS); err != nil {
-			return fmt.Errorf("failed to set DNS servers: %w", err)
-		}
-	}
-
-	// Make sure the interface is up
-	upCmd := exec.Command("ip", "link", "set", "dev", iface.Name, "up")
-	if err := upCmd.Run(); err != nil {
-		return fmt.Errorf("failed to bring interface up: %w", err)
+		settings["ipv4.dns"] = strings.ReplaceAll(iface.DNS, " ", "")
 	}
 
-	return nil
-}
-
-// IPInfo represents extracted IP information
-type ipInfo struct {
-	ipAddress  string
-	subnetMask string
-}
-
-// extractIPInfo extracts IP information from ip addr output
-func extractIPInfo(ipAddrOutput, interfaceName string) ipInfo {
-	var info ipInfo
-
-	// Find the section for the specific interface
-	pattern := fmt.Sprintf(`(?m)^\d+: %s:.*?(?:\n\d+:|$)`, regexp.QuoteMeta(interfaceName))
-	re := regexp.MustCompile(pattern)
-	ifaceSection := re.FindString(ipAddrOutput)
+	b := backend.Default()
 
-	// Extract IPv4 address and CIDR
-	ipv4Pattern := `inet\s+(\d+\.\d+\.\d+\.\d+)/(\d+)`
-	ipv4Re := regexp.MustCompile(ipv4Pattern)
-	ipv4Match := ipv4Re.FindStringSubmatch(ifaceSection)
+	// Use the profile bound to the interface, creating one if there is none
+	profile, err := backend.FindProfileForDevice(b, iface.Name)
+	if errors.Is(err, backend.ErrNotFound) {
+		profile, err = b.AddProfile(backend.Profile{
+			Name:     iface.Name,
+			Type:     "ethernet",
+			Device:   iface.Name,
+			Settings: settings,
+		})
+	} else if err == nil {
+		err = b.ModifyProfile(profile.ID(), settings)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply interface config: %w", err)
+	}
 
-	if len(ipv4Match) >= 3 {
-		info.ipAddress = ipv4Match[1]
-		
-		// Convert CIDR to subnet mask
-		cidr := ipv4Match[2]
-		subnetMask, err := cidrToMask(cidr)
-		if err == nil {
-			info.subnetMask = subnetMask
-		}
+	// Make sure the interface is up with the new configuration
+	if err := b.Activate(profile.ID()); err != nil {
+		return fmt.Errorf("failed to bring interface up: %w", err)
 	}
 
-	return info
+	return nil
 }
 
-// extractGateway extracts the default gateway for an interface
-func extractGateway(ipRouteOutput, interfaceName string) string {
-	// Look for default route
-	pattern := fmt.Sprintf(`default via (\d+\.\d+\.\d+\.\d+) dev %s`, regexp.QuoteMeta(interfaceName))
-	re := regexp.MustCompile(pattern)
-	match := re.FindStringSubmatch(ipRouteOutput)
-
-	if len(match) >= 2 {
-		return match[1]
+// interfaceType maps the backend device type to the interface types used by
+// the UI, falling back to guessing from the name
+func interfaceType(dev backend.Device) string {
+	switch dev.Type {
+	case "wifi":
+		return "wireless"
+	case "ethernet":
+		return "ethernet"
+	case "tun", "wireguard", "vpn":
+		return "vpn"
+	case "bridge", "bond", "team", "vlan":
+		return "virtual"
 	}
-
-	return ""
+	return determineInterfaceType(dev.Name)
 }
 
 // determineInterfaceType guesses the interface type based on its name
@@ -231,58 +170,6 @@ func determineInterfaceType(name string) string {
 	return "unknown"
 }
 
-// getDNSServers reads DNS server information from resolv.conf
-func getDNSServers() (string, error) {
-	// Read /etc/resolv.conf
-	cmd := exec.Command("cat", "/etc/resolv.conf")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("failed to read resolv.conf: %w", err)
-	}
-
-	// Extract nameserver lines
-	pattern := `nameserver\s+(\d+\.\d+\.\d+\.\d+)`
-	re := regexp.MustCompile(pattern)
-	matches := re.FindAllStringSubmatch(string(output), -1)
-
-	servers := []string{}
-	for _, match := range matches {
-		if len(match) >= 2 {
-			servers = append(servers, match[1])
-		}
-	}
-
-	return strings.Join(servers, ", "), nil
-}
-
-// setDNSServers writes DNS server information to resolv.conf
-func setDNSServers(dnsServers string) error {
-	// Parse DNS servers
-	servers := strings.Split(dnsServers, ",")
-	
-	// Build new resolv.conf content
-	var content strings.Builder
-	content.WriteString("# Generated by Network Manager TUI\n")
-	
-	for _, server := range servers {
-		server = strings.TrimSpace(server)
-		if net.ParseIP(server) != nil {
-			content.WriteString(fmt.Sprintf("nameserver %s\n", server))
-		}
-	}
-	
-	// Add some common options
-	content.WriteString("options timeout:2 attempts:3\n")
-	
-	// Write to resolv.conf
-	cmd := exec.Command("sh", "-c", fmt.Sprintf("echo '%s' > /etc/resolv.conf", content.String()))
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to write to resolv.conf: %w", err)
-	}
-	
-	return nil
-}
-
 // cidrToMask converts CIDR notation to subnet mask
 func cidrToMask(cidr string) (string, error) {
 	cidrInt, err := parseInt(cidr)
@@ -1,19 +1,16 @@
 package network
 
 import (
-	"fmt"
-	"os/exec"
-	"regexp"
-	"strconv"
-	"strings"
+	"networkmanager-tui/backend"
 )
 
 // WiFiNetwork represents a wireless network
 type WiFiNetwork struct {
 	SSID           string
 	BSSID          string
-	SignalStrength int  // 0 to 5, with 5 being the strongest
+	SignalStrength int // 0 to 5, with 5 being the strongest
 	Secured        bool
+	Security       string // Open, WEP, WPA2-PSK, WPA3-SAE or 802.1X
 }
 
 // WiFiScannedMsg is a message containing scanned WiFi networks
@@ -26,177 +23,55 @@ type WiFiConnectedMsg struct {
 	SSID string
 }
 
-// ScanWiFiNetworks scans for available WiFi networks
+// ScanWiFiNetworks scans for available WiFi networks through the active
+// backend. NetworkManager is asked for a fresh scan first; hosts without it
+// fall back to iwlist.
 func ScanWiFiNetworks() ([]WiFiNetwork, error) {
-	// First check if we have a wireless interface
-	cmd := exec.Command("iwconfig")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return nil, fmt.Errorf("failed to run iwconfig: %w", err)
-	}
-
-	// Find wireless interfaces
-	interfaceRe := regexp.MustCompile(`(\w+)\s+IEEE 802\.11`)
-	matches := interfaceRe.FindStringSubmatch(string(output))
-	if len(matches) < 2 {
-		return nil, fmt.Errorf("no wireless interfaces found")
+	b := backend.Default()
+	if w, ok := b.(backend.WiFi); ok {
+		// A rescan may be refused while another one is running; the cached
+		// results are still good enough
+		_ = w.Rescan("")
 	}
 
-	wirelessIface := matches[1]
-
-	// Scan for networks
-	scanCmd := exec.Command("iwlist", wirelessIface, "scan")
-	scanOutput, err := scanCmd.CombinedOutput()
+	aps, err := b.Scan()
 	if err != nil {
-		return nil, fmt.Errorf("failed to scan for networks: %w", err)
-	}
-
-	// Parse the output
-	return parseWiFiScan(string(scanOutput)), nil
-}
-
-// ConnectToWiFi connects to a WiFi network
-func ConnectToWiFi(ssid, password string) error {
-	// First check if we have a wireless interface
-	cmd := exec.Command("iwconfig")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to run iwconfig: %w", err)
-	}
-
-	// Find wireless interfaces
-	interfaceRe := regexp.MustCompile(`(\w+)\s+IEEE 802\.11`)
-	matches := interfaceRe.FindStringSubmatch(string(output))
-	if len(matches) < 2 {
-		return fmt.Errorf("no wireless interfaces found")
-	}
-
-	wirelessIface := matches[1]
-
-	// Create a temporary wpa_supplicant configuration
-	configContent := fmt.Sprintf(`
-network={
-    ssid="%s"
-    %s
-}
-`, ssid, getAuthConfig(password))
-
-	// Write config to temporary file
-	configFile := "/tmp/wpa_supplicant.conf"
-	writeCmd := exec.Command("sh", "-c", fmt.Sprintf("echo '%s' > %s", configContent, configFile))
-	if err := writeCmd.Run(); err != nil {
-		return fmt.Errorf("failed to write wpa_supplicant config: %w", err)
-	}
-
-	// Stop any running wpa_supplicant instances
-	stopCmd := exec.Command("killall", "wpa_supplicant")
-	// Ignore errors - it might not be running
-	stopCmd.Run()
-
-	// Start wpa_supplicant
-	wpaCmd := exec.Command("wpa_supplicant", "-B", "-i", wirelessIface, "-c", configFile)
-	if err := wpaCmd.Run(); err != nil {
-		return fmt.Errorf("failed to start wpa_supplicant: %w", err)
-	}
-
-	// Get IP via DHCP
-	dhcpCmd := exec.Command("dhclient", "-v", wirelessIface)
-	if err := dhcpCmd.Run(); err != nil {
-		return fmt.Errorf("failed to get IP address: %w", err)
+		return nil, err
 	}
 
-	return nil
-}
-
-// parseWiFiScan parses the output of iwlist scan
-func parseWiFiScan(scanOutput string) []WiFiNetwork {
+	// Keep one entry per SSID, from the strongest access point
 	networks := []WiFiNetwork{}
-	
-	// Split output into cells
-	cells := strings.Split(scanOutput, "Cell ")
-	
-	// Skip the first element, which is just header text
-	cells = cells[1:]
-	
-	for _, cell := range cells {
-		var network WiFiNetwork
-		
-		// Extract SSID
-		ssidRe := regexp.MustCompile(`ESSID:"([^"]*)"`)
-		ssidMatch := ssidRe.FindStringSubmatch(cell)
-		if len(ssidMatch) < 2 {
-			continue
-		}
-		network.SSID = ssidMatch[1]
-		
-		// Skip hidden networks
-		if network.SSID == "" {
+	seen := map[string]int{}
+	for _, ap := range aps {
+		if ap.SSID == "" {
 			continue
 		}
-		
-		// Extract BSSID
-		bssidRe := regexp.MustCompile(`Address: ([0-9A-F:]{17})`)
-		bssidMatch := bssidRe.FindStringSubmatch(cell)
-		if len(bssidMatch) < 2 {
-			continue
+		network := WiFiNetwork{
+			SSID:           ap.SSID,
+			BSSID:          ap.BSSID,
+			SignalStrength: mapSignalQuality(ap.Signal, 0, 100, 0, 5),
+			Secured:        ap.Secured,
+			Security:       backend.SecurityLabel(ap.Security),
 		}
-		network.BSSID = bssidMatch[1]
-		
-		// Extract signal strength
-		signalRe := regexp.MustCompile(`Signal level=(-\d+) dBm`)
-		signalMatch := signalRe.FindStringSubmatch(cell)
-		if len(signalMatch) < 2 {
-			// Alternative format
-			signalRe = regexp.MustCompile(`Quality=(\d+)/70`)
-			signalMatch = signalRe.FindStringSubmatch(cell)
-			if len(signalMatch) < 2 {
-				// Use default value
-				network.SignalStrength = 3
-			} else {
-				quality, _ := strconv.Atoi(signalMatch[1])
-				network.SignalStrength = mapSignalQuality(quality, 0, 70, 0, 5)
+		if i, ok := seen[ap.SSID]; ok {
+			if network.SignalStrength > networks[i].SignalStrength {
+				networks[i] = network
 			}
-		} else {
-			// Convert dBm to our scale (0-5)
-			signalDBm, _ := strconv.Atoi(signalMatch[1])
-			network.SignalStrength = mapSignalStrength(signalDBm, -100, -50, 0, 5)
-		}
-		
-		// Check encryption
-		encryptionRe := regexp.MustCompile(`Encryption key:(.*)`)
-		encryptionMatch := encryptionRe.FindStringSubmatch(cell)
-		if len(encryptionMatch) < 2 || strings.TrimSpace(encryptionMatch[1]) == "off" {
-			network.Secured = false
-		} else {
-			network.Secured = true
+			continue
 		}
-		
+		seen[ap.SSID] = len(networks)
 		networks = append(networks, network)
 	}
-	
-	return networks
+	return networks, nil
 }
 
-// getAuthConfig returns the WPA configuration based on password
-func getAuthConfig(password string) string {
-	if password == "" {
-		return "key_mgmt=NONE"
-	}
-	return fmt.Sprintf("psk=\"%s\"", password)
-}
-
-// mapSignalStrength maps a signal level (dBm) to a range
-func mapSignalStrength(value, fromLow, fromHigh, toLow, toHigh int) int {
-	// Ensure value is in range
-	if value < fromLow {
-		value = fromLow
-	}
-	if value > fromHigh {
-		value = fromHigh
-	}
-	
-	// Map to new range
-	return (value-fromLow)*(toHigh-toLow)/(fromHigh-fromLow) + toLow
+// ConnectToWiFi connects to a WiFi network on the first wireless device,
+// reusing its saved profile or creating one that autoconnects. A password
+// means WPA2-PSK; enterprise networks go through the main network package.
+func ConnectToWiFi(ssid, password string) error {
+	security := backend.WiFiSecurity(backend.SecurityPSK, password)
+	_, err := backend.ConnectWiFi(backend.Default(), "", ssid, security)
+	return err
 }
 
 // mapSignalQuality maps a quality value to a range
@@ -208,7 +83,7 @@ func mapSignalQuality(value, fromLow, fromHigh, toLow, toHigh int) int {
 	if value > fromHigh {
 		value = fromHigh
 	}
-	
+
 	// Map to new range
 	return (value-fromLow)*(toHigh-toLow)/(fromHigh-fromLow) + toLow
 }
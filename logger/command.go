@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"errors"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Valor gravado no lugar de argumentos secretos
+const redacted = "********"
+
+// Command grava em nível debug a execução de um comando externo, com os
+// argumentos secretos ocultos, a duração e o código de saída (-1 se o comando
+// não chegou a terminar)
+func Command(name string, args []string, duration time.Duration, err error) {
+	if !Enabled(LevelDebug) {
+		return
+	}
+	code := 0
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.ExitCode()
+	} else if err != nil {
+		code = -1
+	}
+	keyvals := []interface{}{
+		"cmd", name,
+		"args", strings.Join(RedactArgs(args), " "),
+		"duration_ms", duration.Milliseconds(),
+		"exit_code", code,
+	}
+	if err != nil {
+		keyvals = append(keyvals, "error", err)
+	}
+	Debug("Comando executado", keyvals...)
+}
+
+// Chave compartilhada dentro de um valor, como nos peers de wireguard.peers
+var presharedKeyRegex = regexp.MustCompile(`(preshared-key=)[^\s,]+`)
+
+// RedactArgs oculta os valores secretos de uma linha de comando: o argumento
+// seguinte a uma propriedade secreta (como 802-11-wireless-security.psk), o
+// valor de pares chave=valor com chave secreta e as chaves compartilhadas
+// (preshared-key=) dentro de qualquer argumento
+func RedactArgs(args []string) []string {
+	masked := make([]string, len(args))
+	next := false
+	for i, arg := range args {
+		if next {
+			masked[i], next = redacted, false
+			continue
+		}
+		if key, _, ok := strings.Cut(arg, "="); ok && isSecretName(key) {
+			masked[i] = key + "=" + redacted
+			continue
+		}
+		masked[i] = presharedKeyRegex.ReplaceAllString(arg, "${1}"+redacted)
+		// Opções como --show-secrets não recebem valor
+		next = !strings.HasPrefix(arg, "-") && isSecretName(arg)
+	}
+	return masked
+}
+
+// isSecretName informa se o nome é de uma propriedade ou opção secreta
+func isSecretName(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range []string{"password", "passwd", "secret", "psk", "token", "wep-key", "private-key"} {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '.' || r == '-' || r == '_' }) {
+		if part == "pin" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Arquivo atual e formato dos rotacionados (networkmanager-tui-<data>.log)
+const (
+	currentFile   = Identifier + ".log"
+	rotatedPrefix = Identifier + "-"
+	rotatedLayout = "20060102-150405.000000"
+)
+
+// fileSink grava os registros em um arquivo, rotacionado ao atingir o tamanho
+// máximo; os rotacionados mais antigos que MaxAge ou além de MaxFiles são
+// removidos
+type fileSink struct {
+	cfg  Config
+	file *os.File
+	size int64
+}
+
+func newFileSink(cfg Config) (*fileSink, error) {
+	// O diretório e os arquivos só são legíveis pelo dono, pois os logs
+	// registram as alterações de rede
+	if err := os.MkdirAll(cfg.Dir, 0700); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de logs: %w", err)
+	}
+	s := &fileSink{cfg: cfg}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	if err := s.clean(); err != nil {
+		s.close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// open abre o arquivo atual para acrescentar registros
+func (s *fileSink) open() error {
+	file, err := os.OpenFile(filepath.Join(s.cfg.Dir, currentFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir arquivo de log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("erro ao abrir arquivo de log: %w", err)
+	}
+	s.file, s.size = file, info.Size()
+	return nil
+}
+
+func (s *fileSink) write(e entry) error {
+	var line string
+	if s.cfg.Format == FormatJSON {
+		line = formatJSON(e, true)
+	} else {
+		line = formatText(e, true)
+	}
+	line += "\n"
+
+	if s.cfg.MaxSize > 0 && s.size > 0 && s.size+int64(len(line)) > s.cfg.MaxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.WriteString(line)
+	s.size += int64(n)
+	return err
+}
+
+// rotate renomeia o arquivo atual, abre um novo e aplica a retenção
+func (s *fileSink) rotate() error {
+	s.file.Close()
+	current := filepath.Join(s.cfg.Dir, currentFile)
+	rotated := filepath.Join(s.cfg.Dir, rotatedPrefix+time.Now().Format(rotatedLayout)+".log")
+	if err := os.Rename(current, rotated); err != nil {
+		// Continua no arquivo atual; a rotação é tentada de novo na próxima
+		// gravação
+		if openErr := s.open(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+	if err := s.open(); err != nil {
+		return err
+	}
+	return s.clean()
+}
+
+// clean remove os arquivos rotacionados mais antigos que MaxAge dias e os
+// que excedem MaxFiles
+func (s *fileSink) clean() error {
+	files, err := filepath.Glob(filepath.Join(s.cfg.Dir, rotatedPrefix+"*.log"))
+	if err != nil {
+		return err
+	}
+	// O nome contém a data, então a ordem alfabética é a cronológica
+	sort.Strings(files)
+
+	cutoff := time.Now().AddDate(0, 0, -s.cfg.MaxAge)
+	var kept []string
+	for _, name := range files {
+		info, err := os.Stat(name)
+		if err == nil && s.cfg.MaxAge > 0 && info.ModTime().Before(cutoff) {
+			os.Remove(name)
+			continue
+		}
+		kept = append(kept, name)
+	}
+	for s.cfg.MaxFiles > 0 && len(kept) > s.cfg.MaxFiles {
+		os.Remove(kept[0])
+		kept = kept[1:]
+	}
+	return nil
+}
+
+func (s *fileSink) close() error {
+	return s.file.Close()
+}
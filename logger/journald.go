@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Socket do protocolo nativo do journald
+const journalSocket = "/run/systemd/journal/socket"
+
+// Prioridades do syslog usadas pelo journald para cada nível
+var journalPriority = map[Level]string{
+	LevelDebug: "7",
+	LevelInfo:  "6",
+	LevelWarn:  "4",
+	LevelError: "3",
+}
+
+// journaldSink envia os registros ao journald pelo protocolo nativo; os campos
+// viram campos do journal (em maiúsculas), consultáveis com journalctl
+type journaldSink struct {
+	conn *net.UnixConn
+}
+
+func newJournald() (*journaldSink, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journalSocket, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar ao journald: %w", err)
+	}
+	return &journaldSink{conn: conn}, nil
+}
+
+func (s *journaldSink) write(e entry) error {
+	var b bytes.Buffer
+	appendJournalField(&b, "MESSAGE", e.Message)
+	appendJournalField(&b, "PRIORITY", journalPriority[e.Level])
+	appendJournalField(&b, "SYSLOG_IDENTIFIER", Identifier)
+	for _, f := range e.Fields {
+		if name := journalFieldName(f.Key); name != "" {
+			appendJournalField(&b, name, fmt.Sprint(f.Value))
+		}
+	}
+	_, err := s.conn.Write(b.Bytes())
+	return err
+}
+
+// appendJournalField acrescenta um campo ao datagrama. Valores com quebra de
+// linha usam o formato binário: nome, tamanho em 64 bits little-endian e valor.
+func appendJournalField(b *bytes.Buffer, name, value string) {
+	if !strings.Contains(value, "\n") {
+		fmt.Fprintf(b, "%s=%s\n", name, value)
+		return
+	}
+	b.WriteString(name)
+	b.WriteByte('\n')
+	binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+// journalFieldName converte a chave em um nome de campo aceito pelo journald:
+// letras maiúsculas, dígitos e sublinhado, sem começar com sublinhado ou
+// dígito. O prefixo NMTUI_ evita conflito com os campos do próprio journal.
+func journalFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, key)
+	name = strings.Trim(name, "_")
+	if name == "" {
+		return ""
+	}
+	return "NMTUI_" + name
+}
+
+func (s *journaldSink) close() error {
+	return s.conn.Close()
+}
@@ -1,87 +1,345 @@
-
+// Package logger grava os logs da aplicação em níveis (debug, info, warn e
+// error), com campos chave-valor, em texto ou JSON. O destino pode ser um
+// arquivo com rotação por tamanho, o journald (protocolo nativo) ou o syslog.
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
-	"log"
 	"os"
-	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
-var (
-	InfoLogger  *log.Logger
-	ErrorLogger *log.Logger
-	logFile     *os.File
+// Level é o nível de um registro de log
+type Level int
+
+// Níveis de log, do mais detalhado ao mais grave
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
 )
 
-func cleanOldLogs() error {
-	logDir := "logs"
-	files, err := os.ReadDir(logDir)
-	if err != nil {
-		return fmt.Errorf("erro ao ler diretório de logs: %v", err)
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return "level(" + strconv.Itoa(int(l)) + ")"
 	}
+	return levelNames[l]
+}
 
-	cutoffDate := time.Now().AddDate(0, 0, -90)
-	for _, file := range files {
-		if !file.IsDir() {
-			filePath := filepath.Join(logDir, file.Name())
-			fileInfo, err := file.Info()
-			if err != nil {
-				continue
-			}
-			
-			if fileInfo.ModTime().Before(cutoffDate) {
-				os.Remove(filePath)
-				LogInfo("Log antigo removido: %s", file.Name())
+// ParseLevel interpreta o nome de um nível (debug, info, warn ou error)
+func ParseLevel(name string) (Level, error) {
+	for i, level := range levelNames {
+		if strings.EqualFold(name, level) || (level == "warn" && strings.EqualFold(name, "warning")) {
+			return Level(i), nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("nível de log inválido: %s (use debug, info, warn ou error)", name)
+}
+
+// Formatos e destinos aceitos
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+
+	OutputFile     = "file"
+	OutputJournald = "journald"
+	OutputSyslog   = "syslog"
+)
+
+// Identificador da aplicação no journald e no syslog
+const Identifier = "networkmanager-tui"
+
+// Diretório padrão dos arquivos de log
+const DefaultDir = "/var/log/networkmanager-tui"
+
+// Config define o nível, o formato e o destino dos logs
+type Config struct {
+	Level  Level
+	Format string // text ou json (arquivo e syslog)
+	Output string // file, journald ou syslog
+
+	Dir      string // Diretório dos arquivos (destino file)
+	MaxSize  int64  // Tamanho em bytes a partir do qual o arquivo é rotacionado
+	MaxAge   int    // Dias que os arquivos rotacionados são mantidos (0 não remove por idade)
+	MaxFiles int    // Quantidade de arquivos rotacionados mantidos (0 não limita)
+}
+
+// DefaultConfig retorna a configuração padrão: nível info, em texto, no
+// diretório padrão, com rotação a cada 10 MiB e 90 dias de retenção
+func DefaultConfig() Config {
+	return Config{
+		Level:    LevelInfo,
+		Format:   FormatText,
+		Output:   OutputFile,
+		Dir:      DefaultDir,
+		MaxSize:  10 << 20,
+		MaxAge:   90,
+		MaxFiles: 10,
+	}
+}
+
+// Variáveis de ambiente que sobrepõem a configuração padrão. Também são
+// passadas aos processos filhos da aplicação (ver Environ).
+const (
+	EnvLevel    = "NMTUI_LOG_LEVEL"
+	EnvFormat   = "NMTUI_LOG_FORMAT"
+	EnvOutput   = "NMTUI_LOG_OUTPUT"
+	EnvDir      = "NMTUI_LOG_DIR"
+	EnvMaxSize  = "NMTUI_LOG_MAX_SIZE"  // Em MiB
+	EnvMaxAge   = "NMTUI_LOG_MAX_AGE"   // Em dias
+	EnvMaxFiles = "NMTUI_LOG_MAX_FILES" // Quantidade de arquivos
+)
+
+// ConfigFromEnv retorna a configuração padrão com os valores definidos nas
+// variáveis de ambiente
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+	if value := os.Getenv(EnvLevel); value != "" {
+		level, err := ParseLevel(value)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Level = level
+	}
+	if value := os.Getenv(EnvFormat); value != "" {
+		cfg.Format = value
+	}
+	if value := os.Getenv(EnvOutput); value != "" {
+		cfg.Output = value
+	}
+	if value := os.Getenv(EnvDir); value != "" {
+		cfg.Dir = value
+	}
+	for _, item := range []struct {
+		env   string
+		apply func(int)
+	}{
+		{EnvMaxSize, func(n int) { cfg.MaxSize = int64(n) << 20 }},
+		{EnvMaxAge, func(n int) { cfg.MaxAge = n }},
+		{EnvMaxFiles, func(n int) { cfg.MaxFiles = n }},
+	} {
+		if value := os.Getenv(item.env); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return cfg, fmt.Errorf("valor inválido em %s: %s", item.env, value)
 			}
+			item.apply(n)
 		}
 	}
+	return cfg, cfg.Validate()
+}
+
+// Validate verifica o formato, o destino e os limites da configuração
+func (c Config) Validate() error {
+	switch {
+	case c.Format != FormatText && c.Format != FormatJSON:
+		return fmt.Errorf("formato de log inválido: %s (use text ou json)", c.Format)
+	case c.Output != OutputFile && c.Output != OutputJournald && c.Output != OutputSyslog:
+		return fmt.Errorf("destino de log inválido: %s (use file, journald ou syslog)", c.Output)
+	case c.Output == OutputFile && c.Dir == "":
+		return fmt.Errorf("diretório de log não informado")
+	case c.MaxSize < 0 || c.MaxAge < 0 || c.MaxFiles < 0:
+		return fmt.Errorf("limites de rotação de log não podem ser negativos")
+	}
 	return nil
 }
 
-func Init() error {
-	// Cria diretório de logs se não existir
-	logDir := "logs"
-	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return fmt.Errorf("erro ao criar diretório de logs: %v", err)
+// Environ descreve a configuração em uso como variáveis de ambiente, para que
+// os processos filhos (como a guarda da aplicação segura) gravem no mesmo
+// destino
+func Environ() []string {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return []string{
+		EnvLevel + "=" + config.Level.String(),
+		EnvFormat + "=" + config.Format,
+		EnvOutput + "=" + config.Output,
+		EnvDir + "=" + config.Dir,
+		EnvMaxSize + "=" + strconv.FormatInt(config.MaxSize>>20, 10),
+		EnvMaxAge + "=" + strconv.Itoa(config.MaxAge),
+		EnvMaxFiles + "=" + strconv.Itoa(config.MaxFiles),
 	}
+}
+
+// Field é um campo chave-valor de um registro
+type Field struct {
+	Key   string
+	Value interface{}
+}
 
-	// Limpa logs antigos
-	if err := cleanOldLogs(); err != nil {
-		LogError("Erro ao limpar logs antigos: %v", err)
+// entry é um registro de log
+type entry struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Fields  []Field
+}
+
+// sink grava os registros em um destino
+type sink interface {
+	write(e entry) error
+	close() error
+}
+
+var (
+	mutex  sync.Mutex
+	config = DefaultConfig()
+	out    sink
+)
+
+// Init abre o destino de log da configuração
+func Init(cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
 	}
 
-	// Nome do arquivo de log com timestamp
-	logFileName := filepath.Join(logDir, fmt.Sprintf("system_%s.log", time.Now().Format("2006-01-02")))
-	
-	// Abre arquivo de log
-	file, err := os.OpenFile(logFileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	var s sink
+	var err error
+	switch cfg.Output {
+	case OutputJournald:
+		s, err = newJournald()
+	case OutputSyslog:
+		s, err = newSyslog(cfg.Format)
+	default:
+		s, err = newFileSink(cfg)
+	}
 	if err != nil {
-		return fmt.Errorf("erro ao abrir arquivo de log: %v", err)
+		return err
 	}
 
-	logFile = file
-	InfoLogger = log.New(file, "INFO: ", log.Ldate|log.Ltime)
-	ErrorLogger = log.New(file, "ERROR: ", log.Ldate|log.Ltime)
-	
+	mutex.Lock()
+	if out != nil {
+		out.close()
+	}
+	config, out = cfg, s
+	mutex.Unlock()
 	return nil
 }
 
+// Close fecha o destino de log
 func Close() {
-	if logFile != nil {
-		logFile.Close()
+	mutex.Lock()
+	defer mutex.Unlock()
+	if out != nil {
+		out.close()
+		out = nil
+	}
+}
+
+// Enabled informa se registros do nível são gravados
+func Enabled(level Level) bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return out != nil && level >= config.Level
+}
+
+// Log grava um registro com campos chave-valor (chave, valor, chave, valor...)
+func Log(level Level, msg string, keyvals ...interface{}) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if out == nil || level < config.Level {
+		return
+	}
+	// Falhas de gravação não têm onde ser registradas
+	out.write(entry{Time: time.Now(), Level: level, Message: msg, Fields: fields(keyvals)})
+}
+
+// Debug, Info, Warn e Error gravam um registro estruturado no nível
+func Debug(msg string, keyvals ...interface{}) { Log(LevelDebug, msg, keyvals...) }
+func Info(msg string, keyvals ...interface{})  { Log(LevelInfo, msg, keyvals...) }
+func Warn(msg string, keyvals ...interface{})  { Log(LevelWarn, msg, keyvals...) }
+func Error(msg string, keyvals ...interface{}) { Log(LevelError, msg, keyvals...) }
+
+// LogDebug, LogInfo, LogWarn e LogError gravam uma mensagem formatada como em
+// fmt.Printf, sem campos
+func LogDebug(format string, v ...interface{}) { logf(LevelDebug, format, v...) }
+func LogInfo(format string, v ...interface{})  { logf(LevelInfo, format, v...) }
+func LogWarn(format string, v ...interface{})  { logf(LevelWarn, format, v...) }
+func LogError(format string, v ...interface{}) { logf(LevelError, format, v...) }
+
+func logf(level Level, format string, v ...interface{}) {
+	if Enabled(level) {
+		Log(level, fmt.Sprintf(format, v...))
 	}
 }
 
-func LogInfo(format string, v ...interface{}) {
-	if InfoLogger != nil {
-		InfoLogger.Printf(format, v...)
+// fields converte a lista chave, valor, ... em campos; uma chave sem valor
+// é gravada com o valor vazio
+func fields(keyvals []interface{}) []Field {
+	list := make([]Field, 0, (len(keyvals)+1)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		var value interface{} = ""
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		list = append(list, Field{Key: key, Value: value})
 	}
+	return list
 }
 
-func LogError(format string, v ...interface{}) {
-	if ErrorLogger != nil {
-		ErrorLogger.Printf(format, v...)
+// formatText descreve o registro em uma linha de texto:
+// data nível mensagem chave=valor ...
+func formatText(e entry, withTime bool) string {
+	var b strings.Builder
+	if withTime {
+		b.WriteString(e.Time.Format("2006-01-02 15:04:05.000"))
+		b.WriteByte(' ')
+	}
+	b.WriteString(strings.ToUpper(e.Level.String()))
+	b.WriteString(": ")
+	b.WriteString(e.Message)
+	for _, f := range e.Fields {
+		fmt.Fprintf(&b, " %s=%s", f.Key, quoteValue(fmt.Sprint(f.Value)))
+	}
+	return b.String()
+}
+
+// quoteValue coloca entre aspas valores vazios ou com espaços, aspas ou
+// quebras de linha
+func quoteValue(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\r\n\"=") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// formatJSON descreve o registro como um objeto JSON em uma linha, começando
+// por time, level e msg. Campos com esses nomes recebem o prefixo "field.".
+func formatJSON(e entry, withTime bool) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	if withTime {
+		fmt.Fprintf(&b, "\"time\":%q,", e.Time.Format(time.RFC3339Nano))
+	}
+	fmt.Fprintf(&b, "\"level\":%q,\"msg\":%s", e.Level.String(), jsonValue(e.Message))
+	for _, f := range e.Fields {
+		key := f.Key
+		if key == "time" || key == "level" || key == "msg" {
+			key = "field." + key
+		}
+		fmt.Fprintf(&b, ",%s:%s", jsonValue(key), jsonValue(f.Value))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// jsonValue codifica o valor em JSON; valores que não podem ser codificados
+// são gravados como texto
+func jsonValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(value))
 	}
+	return string(data)
 }
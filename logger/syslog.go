@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"fmt"
+	"log/syslog"
+)
+
+// syslogSink envia os registros ao socket local do syslog (/dev/log), com a
+// facility daemon; o syslog acrescenta a data
+type syslogSink struct {
+	writer *syslog.Writer
+	format string
+}
+
+func newSyslog(format string) (*syslogSink, error) {
+	writer, err := syslog.New(syslog.LOG_DAEMON|syslog.LOG_INFO, Identifier)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar ao syslog: %w", err)
+	}
+	return &syslogSink{writer: writer, format: format}, nil
+}
+
+func (s *syslogSink) write(e entry) error {
+	var line string
+	if s.format == FormatJSON {
+		line = formatJSON(e, false)
+	} else {
+		line = formatText(e, false)
+	}
+	switch e.Level {
+	case LevelDebug:
+		return s.writer.Debug(line)
+	case LevelWarn:
+		return s.writer.Warning(line)
+	case LevelError:
+		return s.writer.Err(line)
+	}
+	return s.writer.Info(line)
+}
+
+func (s *syslogSink) close() error {
+	return s.writer.Close()
+}
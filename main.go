@@ -4,15 +4,20 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 
+	"networkmanager-tui/backend"
+	"networkmanager-tui/cli"
+	"networkmanager-tui/forward"
 	"networkmanager-tui/history"
 	"networkmanager-tui/i18n"
 	"networkmanager-tui/logger"
 	"networkmanager-tui/menu"
+	"networkmanager-tui/safeapply"
 )
 
 // Define o tema personalizado (escuro e moderno)
@@ -43,18 +48,97 @@ func animateTitle(app *tview.Application, title string) {
 	}()
 }
 
+// userLogDir retorna o diretório de logs de um usuário sem root, que não pode
+// gravar em /var/log: $XDG_STATE_HOME ou ~/.local/state
+func userLogDir() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "networkmanager-tui", "log")
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "state", "networkmanager-tui", "log")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("networkmanager-tui-%d", os.Getuid()), "log")
+}
+
 func main() {
+	// Configuração dos logs: padrão, sobreposta pelas variáveis de ambiente
+	// NMTUI_LOG_* e pelas opções abaixo
+	logCfg, err := logger.ConfigFromEnv()
+	if err != nil {
+		fmt.Printf("Erro ao inicializar logs: %v\n", err)
+		os.Exit(2)
+	}
+
+	// Encaminhamento do histórico: NMTUI_FORWARD_*, sobreposto pelas opções
+	fwdCfg := forward.ConfigFromEnv()
+
 	// Parse command line flags
 	devMode := flag.Bool("dev", false, "Enable development mode")
+	logLevel := flag.String("log-level", logCfg.Level.String(), "Log level: debug, info, warn or error")
+	logFormat := flag.String("log-format", logCfg.Format, "Log format: text or json")
+	logOutput := flag.String("log-output", logCfg.Output, "Log destination: file, journald or syslog")
+	logDir := flag.String("log-dir", logCfg.Dir, "Log directory (file destination)")
+	logMaxSize := flag.Int64("log-max-size", logCfg.MaxSize>>20, "Rotate the log file at this size, in MiB (0 disables)")
+	logMaxAge := flag.Int("log-max-age", logCfg.MaxAge, "Days to keep rotated log files (0 keeps them)")
+	logMaxFiles := flag.Int("log-max-files", logCfg.MaxFiles, "Number of rotated log files to keep (0 keeps all)")
+	forwardURL := flag.String("forward-url", fwdCfg.URL, "Forward audit history to a collector: tcp://, tls:// or udp://host[:port] (RFC 5424 syslog; tcp without a scheme; udp is best-effort) or http(s)://... (JSON webhook)")
+	forwardCA := flag.String("forward-ca", fwdCfg.CAFile, "CA certificates (PEM) that sign the collector certificate (tls and https)")
+	forwardQueue := flag.String("forward-queue", fwdCfg.QueueDir, "Directory of the queue of actions not yet forwarded")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), i18n.T("cli_usage"))
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
+	// Sem diretório escolhido, o modo -dev e usuários sem root não gravam
+	// em /var/log
+	if *logDir == logger.DefaultDir {
+		if *devMode || os.Getenv("DEV_MODE") == "true" {
+			*logDir = filepath.Join(os.TempDir(), "networkmanager-tui", "log")
+		} else if os.Geteuid() != 0 {
+			*logDir = userLogDir()
+		}
+	}
+
 	// Inicializa o sistema de logs
-	if err := logger.Init(); err != nil {
+	logCfg.Format, logCfg.Output, logCfg.Dir = *logFormat, *logOutput, *logDir
+	logCfg.MaxSize, logCfg.MaxAge, logCfg.MaxFiles = *logMaxSize<<20, *logMaxAge, *logMaxFiles
+	if logCfg.Level, err = logger.ParseLevel(*logLevel); err == nil {
+		err = logger.Init(logCfg)
+	}
+	if err != nil {
 		fmt.Printf("Erro ao inicializar logs: %v\n", err)
 		os.Exit(1)
 	}
 	defer logger.Close()
 
+	// Em desenvolvimento usa o backend em memória, sem tocar na rede do host
+	if *devMode || os.Getenv("DEV_MODE") == "true" {
+		backend.SetDefault(backend.NewFake())
+		safeapply.SetStateDir(filepath.Join(os.TempDir(), "networkmanager-tui", "pending"))
+		history.SetDir(filepath.Join(os.TempDir(), "networkmanager-tui", "history"))
+		if *forwardQueue == forward.DefaultQueueDir {
+			*forwardQueue = filepath.Join(os.TempDir(), "networkmanager-tui", "forward-queue")
+		}
+	}
+
+	// Encaminha cada ação do histórico ao coletor, se configurado
+	fwdCfg.URL, fwdCfg.CAFile, fwdCfg.QueueDir = *forwardURL, *forwardCA, *forwardQueue
+	if err := forward.Init(fwdCfg); err != nil {
+		fmt.Printf("Erro ao configurar encaminhamento do histórico: %v\n", err)
+		os.Exit(1)
+	}
+	defer forward.Close()
+	history.SetForwarder(forward.Send)
+
+	// Com um subcomando, executa sem a interface de terminal
+	if flag.NArg() > 0 {
+		code := cli.Run(flag.Args(), *devMode)
+		forward.Close()
+		logger.Close()
+		os.Exit(code)
+	}
+
 	// Verifica privilégios root (skip in dev mode)
 	if !*devMode && os.Geteuid() != 0 {
 		fmt.Println(i18n.T("error_root_required"))
@@ -85,7 +169,7 @@ func main() {
 	})
 
 	// Registra início da aplicação no histórico
-	history.AddAction("system", "app_start", "Aplicação iniciada", "", "system")
+	history.AddAction("app_start", "Aplicação iniciada", "", "tui")
 	
 	// Inicia o menu principal
 	menu.StartMenu(app)
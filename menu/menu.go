@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os/exec"
+	"strconv"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
@@ -35,6 +36,9 @@ var (
 
 // StartMenu inicia o menu principal da aplicação
 func StartMenu(app *tview.Application) {
+	// Sai da tela de status, se estiver aberta, parando a atualização automática
+	network.StopNetworkStatus()
+
 	mainFlex := createMainMenu(app)
 	app.SetRoot(mainFlex, true)
 }
@@ -44,15 +48,38 @@ func createMainMenu(app *tview.Application) *tview.Flex {
 	// Lista com as opções do menu sem descrições
 	list := tview.NewList().
 		AddItem("🔌 "+i18n.T("menu_configure"), "", '1', func() {
-			history.AddAction("user", "menu_access", "Configure Network", "", "system")
+			history.AddAction("menu_access", "Configure Network", "", "tui")
 			configureNetworkMenu(app)
 		}).
+		AddItem("🔗 "+i18n.T("menu_virtual"), "", 'v', func() {
+			history.AddAction("menu_access", "Virtual Interfaces", "", "tui")
+			network.ShowVirtualWizard(app)
+		}).
+		AddItem("🔐 "+i18n.T("menu_vpn"), "", 'n', func() {
+			history.AddAction("menu_access", "VPN Tunnels", "", "tui")
+			network.ShowVPN(app)
+		}).
+		AddItem("📶 "+i18n.T("menu_wifi"), "", 'w', func() {
+			history.AddAction("menu_access", "Wi-Fi Networks", "", "tui")
+			network.ShowWiFi(app)
+		}).
+		AddItem("🗂️ "+i18n.T("menu_profiles"), "", 'p', func() {
+			history.AddAction("menu_access", "Saved Profiles", "", "tui")
+			network.ShowProfiles(app)
+		}).
+		AddItem("📋 "+i18n.T("menu_desired"), "", 'd', func() {
+			history.AddAction("menu_access", "Desired State", "", "tui")
+			network.ShowDesiredState(app)
+		}).
+		AddItem("📜 "+i18n.T("menu_history"), "", 'h', func() {
+			network.ShowHistory(app)
+		}).
 		AddItem("📡 "+i18n.T("menu_status"), "", '2', func() {
-			history.AddAction("user", "menu_access", "Network Status", "", "system")
+			history.AddAction("menu_access", "Network Status", "", "tui")
 			showNetworkStatus(app)
 		}).
 		AddItem("📶 "+i18n.T("menu_ping_test"), "", '3', func() {
-			history.AddAction("user", "menu_access", "Ping Test", "", "system")
+			history.AddAction("menu_access", "Ping Test", "", "tui")
 			showPingTest(app)
 		}).
 		AddItem("📊 "+i18n.T("menu_sysinfo"), "", '4', func() {
@@ -95,7 +122,7 @@ func createMainMenu(app *tview.Application) *tview.Flex {
 			AddItem(nil, 0, 1, false). // Espaço em branco à esquerda
 			AddItem(list, 60, 1, true). // Lista centralizada com largura fixa (mais larga que antes)
 			AddItem(nil, 0, 1, false), // Espaço em branco à direita
-			15, 1, true). // Altura do menu (maior que antes)
+			21, 1, true). // Altura do menu (maior que antes)
 		AddItem(nil, 0, 1, false) // Espaço em branco inferior
 
 	// Definindo o fundo preto para o layout principal
@@ -168,12 +195,35 @@ func showSystemInfo(app *tview.Application) {
 
 // Reinicia o sistema
 func rebootSystem() error {
-	return exec.Command("reboot").Run()
+	return powerAction("system_reboot", "Reinicialização do sistema", "reboot")
 }
 
 // Desliga o sistema
 func shutdownSystem() error {
-	return exec.Command("shutdown", "-h", "now").Run()
+	return powerAction("system_shutdown", "Desligamento do sistema", "shutdown", "-h", "now")
+}
+
+// powerAction registra a solicitação no histórico antes de executar o
+// comando, já que o processo pode ser encerrado antes de voltar; uma falha é
+// registrada em seguida
+func powerAction(action, details string, command ...string) error {
+	history.Record(history.Action{
+		Action:     action,
+		Details:    details,
+		Outcome:    history.OutcomePending,
+		ModifiedBy: "tui",
+	})
+	start := time.Now()
+	err := exec.Command(command[0], command[1:]...).Run()
+	logger.Command(command[0], command[1:], time.Since(start), err)
+	if err != nil {
+		history.Record(history.Action{
+			Action:     action,
+			Details:    fmt.Sprintf("%s (falha: %v)", details, err),
+			ModifiedBy: "tui",
+		}.WithResult(err))
+	}
+	return err
 }
 
 // Confirmação antes de executar uma ação
@@ -305,10 +355,10 @@ func showPingTest(app *tview.Application) {
 			return
 		}
 
-		// Se count for vazio, usar valor padrão
-		pingArgs := []string{"-c", "4", targetHost}
-		if countStr != "" {
-			pingArgs = []string{"-c", countStr, targetHost}
+		// Se count for vazio ou inválido, usar valor padrão
+		count, err := strconv.Atoi(countStr)
+		if err != nil || count <= 0 {
+			count = 4
 		}
 
 		// Executar o ping
@@ -340,17 +390,16 @@ func showPingTest(app *tview.Application) {
 		}
 
 		// Executa o comando ping
-		cmd := exec.Command("ping", pingArgs...)
-		output, err := cmd.CombinedOutput()
+		output, err := network.Ping(targetHost, count)
 
 		if err != nil {
 			resultsTextView.SetText(i18n.T("ping_results") + ":\n\n" +
-				"[red]" + string(output) + "\n" + err.Error() + "[white]")
+				"[red]" + output + "\n" + err.Error() + "[white]")
 			return
 		}
 
 		resultsTextView.SetText(i18n.T("ping_results") + ":\n\n" +
-			"[green]" + string(output) + "[white]")
+			"[green]" + output + "[white]")
 	})
 
 	form.AddButton(i18n.T("network_back"), func() {
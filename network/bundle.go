@@ -0,0 +1,549 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+	"gopkg.in/yaml.v3"
+
+	"networkmanager-tui/backend"
+	"networkmanager-tui/history"
+	"networkmanager-tui/i18n"
+	"networkmanager-tui/logger"
+)
+
+// BundleVersion é a versão do formato dos pacotes de configuração; pacotes de
+// versões mais novas são recusados na importação
+const BundleVersion = 1
+
+// Formatos de arquivo dos pacotes
+const (
+	BundleJSON = "json"
+	BundleYAML = "yaml"
+)
+
+// DefaultBundleFile é o arquivo sugerido na exportação pela interface
+const DefaultBundleFile = "networkmanager-tui-bundle.json"
+
+// Bundle é um pacote de perfis exportados, para importação em outro host
+type Bundle struct {
+	Version  int             `json:"version" yaml:"version"`
+	Created  time.Time       `json:"created" yaml:"created"`
+	Host     string          `json:"host" yaml:"host"`       // Host de origem
+	Secrets  bool            `json:"secrets" yaml:"secrets"` // Se inclui senhas e chaves privadas
+	Profiles []BundleProfile `json:"profiles" yaml:"profiles"`
+}
+
+// BundleProfile é um perfil exportado, sem o UUID nem o último uso
+type BundleProfile struct {
+	Name     string           `json:"name" yaml:"name"`
+	Type     string           `json:"type" yaml:"type"`
+	Device   string           `json:"device,omitempty" yaml:"device,omitempty"`
+	Settings backend.Settings `json:"settings" yaml:"settings"`
+}
+
+// Propriedades que não são exportadas: identificam o perfil (e vão nos campos
+// do BundleProfile) ou o hardware do host de origem
+var bundleSkippedKeys = map[string]bool{
+	"connection.id":               true,
+	"connection.uuid":             true,
+	"connection.type":             true,
+	"connection.interface-name":   true,
+	"connection.timestamp":        true,
+	"802-3-ethernet.mac-address":  true,
+	"802-11-wireless.mac-address": true,
+	"802-11-wireless.seen-bssids": true,
+}
+
+// Propriedades que indicam outro dispositivo, trocadas junto com o
+// dispositivo do perfil na importação
+var bundleDeviceKeys = []string{"connection.master", "vlan.parent"}
+
+// ExportBundle exporta os perfis indicados (todos, se ids for vazio). Senhas e
+// chaves privadas só são incluídas com secrets.
+func ExportBundle(ids []string, secrets bool) (Bundle, error) {
+	b := backend.Default()
+	if len(ids) == 0 {
+		profiles, err := b.Profiles()
+		if err != nil {
+			return Bundle{}, fmt.Errorf("erro ao listar perfis: %w", err)
+		}
+		for _, p := range profiles {
+			ids = append(ids, p.ID())
+		}
+	}
+
+	host, _ := os.Hostname()
+	bundle := Bundle{
+		Version:  BundleVersion,
+		Created:  time.Now().UTC().Truncate(time.Second),
+		Host:     host,
+		Secrets:  secrets,
+		Profiles: []BundleProfile{},
+	}
+	for _, id := range ids {
+		var p backend.Profile
+		var err error
+		if secrets {
+			p, err = backend.ProfileWithSecrets(b, id)
+		} else {
+			p, err = b.Profile(id)
+		}
+		if err != nil {
+			return Bundle{}, fmt.Errorf("perfil %s: %w", id, err)
+		}
+		bundle.Profiles = append(bundle.Profiles, exportProfile(b, p, secrets))
+	}
+	return bundle, nil
+}
+
+// exportProfile converte o perfil para o pacote, sem as propriedades vazias,
+// as do host de origem e, sem secrets, os segredos
+func exportProfile(b backend.Backend, p backend.Profile, secrets bool) BundleProfile {
+	source := p.Settings
+	if !secrets {
+		source = source.WithoutSecrets()
+	}
+	settings := backend.Settings{}
+	for key, value := range source {
+		if value == "" || bundleSkippedKeys[key] {
+			continue
+		}
+		settings[key] = value
+	}
+
+	// O mestre e o pai podem ser indicados pelo UUID do perfil, que muda na
+	// importação; o nome do dispositivo vale nos dois hosts
+	for _, key := range bundleDeviceKeys {
+		if device := profileInterface(b, settings[key]); device != "" {
+			settings[key] = device
+		}
+	}
+
+	device, ok := p.Settings["connection.interface-name"]
+	if !ok {
+		device = p.Device
+	}
+	return BundleProfile{Name: p.Name, Type: p.Type, Device: device, Settings: settings}
+}
+
+// profileInterface retorna o dispositivo do perfil indicado pelo UUID, ou
+// vazio se o valor não é um UUID
+func profileInterface(b backend.Backend, value string) string {
+	if len(value) != 36 || strings.Count(value, "-") != 4 {
+		return ""
+	}
+	p, err := b.Profile(value)
+	if err != nil {
+		return ""
+	}
+	if device := p.Settings["connection.interface-name"]; device != "" {
+		return device
+	}
+	return p.Device
+}
+
+// BundleFormat escolhe o formato do pacote pela extensão do arquivo: YAML
+// para .yaml e .yml, JSON nos demais casos
+func BundleFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return BundleYAML
+	default:
+		return BundleJSON
+	}
+}
+
+// WriteBundle escreve o pacote em JSON ou YAML
+func WriteBundle(w io.Writer, bundle Bundle, format string) error {
+	switch format {
+	case BundleJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(bundle)
+	case BundleYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(bundle); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("%w: formato de pacote desconhecido: %s (use json ou yaml)", ErrInvalidConfig, format)
+	}
+}
+
+// SaveBundle grava o pacote no arquivo, com permissão 0600, já que ele pode
+// conter senhas e sempre descreve a rede do host
+func SaveBundle(path string, bundle Bundle, format string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("erro ao criar pacote: %w", err)
+	}
+	defer file.Close()
+	// Arquivos existentes mantêm a permissão antiga sem o Chmod
+	if err := file.Chmod(0600); err != nil {
+		return fmt.Errorf("erro ao criar pacote: %w", err)
+	}
+
+	if err := WriteBundle(file, bundle, format); err != nil {
+		return err
+	}
+	logger.LogInfo("Pacote %s exportado com %d perfis", path, len(bundle.Profiles))
+	return file.Close()
+}
+
+// ReadBundle lê um pacote JSON ou YAML e valida a versão e os perfis
+func ReadBundle(r io.Reader) (Bundle, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Bundle{}, fmt.Errorf("erro ao ler pacote: %w", err)
+	}
+
+	// JSON também é YAML válido, então um só decodificador atende os dois
+	var bundle Bundle
+	if err := yaml.Unmarshal(data, &bundle); err != nil {
+		return Bundle{}, fmt.Errorf("%w: pacote ilegível: %v", ErrInvalidConfig, err)
+	}
+
+	switch {
+	case bundle.Version < 1:
+		return Bundle{}, fmt.Errorf("%w: pacote sem versão", ErrInvalidConfig)
+	case bundle.Version > BundleVersion:
+		return Bundle{}, fmt.Errorf("%w: versão %d do pacote não suportada (máximo %d)",
+			ErrInvalidConfig, bundle.Version, BundleVersion)
+	}
+	for i, p := range bundle.Profiles {
+		if p.Name == "" || p.Type == "" {
+			return Bundle{}, fmt.Errorf("%w: perfil %d do pacote sem nome ou tipo", ErrInvalidConfig, i+1)
+		}
+	}
+	return bundle, nil
+}
+
+// LoadBundle lê o pacote do arquivo
+func LoadBundle(path string) (Bundle, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Bundle{}, fmt.Errorf("erro ao abrir pacote: %w", err)
+	}
+	defer file.Close()
+	return ReadBundle(file)
+}
+
+// ParseDeviceMap interpreta a troca de dispositivos da importação, no formato
+// "eth0=ens3,eth1=ens4"
+func ParseDeviceMap(text string) (map[string]string, error) {
+	devices := map[string]string{}
+	for _, entry := range splitValues(text) {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("%w: troca de dispositivo inválida: %s (use origem=destino)", ErrInvalidConfig, entry)
+		}
+		devices[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return devices, nil
+}
+
+// ImportStep é a criação ou a alteração de um perfil na importação de um
+// pacote. As alterações mostram os segredos mascarados.
+type ImportStep struct {
+	Preview  `yaml:",inline"`
+	Type     string   `json:"type" yaml:"type"`
+	Device   string   `json:"device" yaml:"device"`
+	Warnings []string `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+
+	id       string           // Perfil existente que será alterado
+	settings backend.Settings // Propriedades gravadas, com os segredos
+	previous backend.Settings // Propriedades do perfil existente antes da importação
+}
+
+// PlanImport compara os perfis do pacote com os deste host, sem alterar nada.
+// Perfis com o mesmo nome são alterados e os demais, criados; devices troca
+// os nomes dos dispositivos do host de origem.
+func PlanImport(bundle Bundle, devices map[string]string) ([]ImportStep, error) {
+	b := backend.Default()
+	hostDevices := map[string]bool{}
+	if list, err := b.Devices(); err == nil {
+		for _, dev := range list {
+			hostDevices[dev.Name] = true
+		}
+	}
+	remap := func(device string) string {
+		if to, ok := devices[device]; ok {
+			return to
+		}
+		return device
+	}
+
+	// Interfaces virtuais primeiro, já que as portas indicam o mestre
+	profiles := append([]BundleProfile(nil), bundle.Profiles...)
+	sort.SliceStable(profiles, func(i, j int) bool {
+		return backend.IsVirtualType(profiles[i].Type) && !backend.IsVirtualType(profiles[j].Type)
+	})
+
+	steps := []ImportStep{}
+	for _, bp := range profiles {
+		settings := bp.Settings.Clone()
+		for _, key := range bundleDeviceKeys {
+			if value, ok := settings[key]; ok {
+				settings[key] = remap(value)
+			}
+		}
+		step := ImportStep{
+			Preview:  Preview{ProfileID: bp.Name, Create: true},
+			Type:     bp.Type,
+			Device:   remap(bp.Device),
+			settings: settings,
+		}
+
+		switch backend.ProfileType(bp.Type) {
+		case "802-3-ethernet", "802-11-wireless":
+			if step.Device != "" && !hostDevices[step.Device] {
+				step.Warnings = append(step.Warnings, fmt.Sprintf(i18n.T("bundle_missing_device"), step.Device))
+			}
+		}
+
+		current := backend.Settings{}
+		if existing, err := b.Profile(bp.Name); err == nil {
+			if backend.ProfileType(existing.Type) != backend.ProfileType(bp.Type) {
+				return nil, fmt.Errorf("%w: o perfil %s já existe com o tipo %s", ErrInvalidConfig, bp.Name, existing.Type)
+			}
+			step.Create = false
+			step.id = existing.ID()
+			step.previous = existing.Settings
+			settings["connection.interface-name"] = step.Device
+			current = existing.Settings
+		}
+		step.Changes = backend.DiffSettings(current.Masked(), settings.Masked())
+
+		if planner, ok := b.(backend.Planner); ok && step.Create {
+			step.Commands = planner.PlanAdd(step.profile())
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// profile monta o perfil criado pelo passo
+func (s ImportStep) profile() backend.Profile {
+	return backend.Profile{Name: s.ProfileID, Type: s.Type, Device: s.Device, Settings: s.settings}
+}
+
+// ApplyImport cria ou altera os perfis, na ordem do plano, e os ativa se
+// activate for verdadeiro. Para no primeiro erro e retorna quantos perfis
+// foram importados.
+func ApplyImport(steps []ImportStep, activate bool) (int, error) {
+	b := backend.Default()
+	for i, step := range steps {
+		id := step.id
+		var err error
+		if step.Create {
+			var created backend.Profile
+			created, err = b.AddProfile(step.profile())
+			id = created.ID()
+		} else {
+			err = b.ModifyProfile(id, step.settings)
+		}
+		if err == nil {
+			err = verifySecrets(b, id, step.settings)
+		}
+		if err == nil && activate {
+			err = b.Activate(id)
+		}
+		if err != nil {
+			return i, fmt.Errorf("perfil %s: %w", step.ProfileID, err)
+		}
+		logger.LogInfo("Perfil %s importado", step.ProfileID)
+	}
+	return len(steps), nil
+}
+
+// RecordImport registra no histórico cada perfil importado, com as
+// propriedades gravadas. imported é a quantidade de passos concluídos por
+// ApplyImport; com err, o passo seguinte é registrado como falha.
+func RecordImport(path string, steps []ImportStep, imported int, via string, err error) {
+	for i := 0; i < len(steps) && i <= imported; i++ {
+		var stepErr error
+		if i == imported {
+			if err == nil {
+				break
+			}
+			stepErr = err
+		}
+		step := steps[i]
+		after := step.previous.Clone()
+		for key, value := range step.settings {
+			after[key] = value
+		}
+		profile, status := step.id, "alterado"
+		if step.Create {
+			profile, status = step.ProfileID, "criado"
+		}
+		diff := history.Diff(step.previous, after)
+		action := history.Action{
+			Action:     "profile_import",
+			Interface:  step.Device,
+			Profile:    profile,
+			Details:    fmt.Sprintf("Pacote %s: perfil %s %s (%s)", path, step.ProfileID, status, outcomeText(stepErr)),
+			Diff:       diff,
+			ModifiedBy: via,
+		}.WithResult(stepErr)
+		// Só perfis que já existiam podem voltar ao estado anterior
+		if stepErr == nil && !step.Create {
+			action.Snapshot = history.Snapshot(step.previous, diff)
+		}
+		history.Record(action)
+	}
+}
+
+// RecordExport registra no histórico a exportação do pacote
+func RecordExport(path string, bundle Bundle, via string, err error) {
+	names := make([]string, len(bundle.Profiles))
+	for i, p := range bundle.Profiles {
+		names[i] = p.Name
+	}
+	details := fmt.Sprintf("Pacote %s com %d perfis", path, len(bundle.Profiles))
+	if bundle.Secrets {
+		details += ", com segredos"
+	}
+	history.Record(history.Action{
+		Action:     "profile_export",
+		Details:    fmt.Sprintf("%s: %s (%s)", details, strings.Join(names, ", "), outcomeText(err)),
+		ModifiedBy: via,
+	}.WithResult(err))
+}
+
+// Pede o arquivo e as opções da exportação. Sem "somente o selecionado",
+// todos os perfis são exportados.
+func showExportForm(app *tview.Application, selected string, done func(path string, bundle Bundle, err error), back func()) {
+	form := newForm(i18n.T("profiles_export"))
+	form.AddInputField(i18n.T("bundle_file"), DefaultBundleFile, 50, nil, nil)
+	form.AddCheckbox(i18n.T("bundle_only_selected")+" ("+selected+")", false, nil)
+	form.AddCheckbox(i18n.T("bundle_secrets"), false, nil)
+	form.AddButton("OK", func() {
+		path := strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
+		var ids []string
+		if form.GetFormItem(1).(*tview.Checkbox).IsChecked() {
+			ids = []string{selected}
+		}
+		secrets := form.GetFormItem(2).(*tview.Checkbox).IsChecked()
+
+		bundle, err := ExportBundle(ids, secrets)
+		if err == nil {
+			err = SaveBundle(path, bundle, BundleFormat(path))
+		}
+		done(path, bundle, err)
+	})
+	form.AddButton(i18n.T("network_cancel"), back)
+
+	showWizardStep(app, form, i18n.T("bundle_export_help"))
+}
+
+// Pede o arquivo, a troca de dispositivos e se os perfis serão ativados, e
+// mostra as alterações antes de importar
+func showImportForm(app *tview.Application, done func(path string, steps []ImportStep, imported int, err error), back func()) {
+	form := newForm(i18n.T("profiles_import"))
+	form.AddInputField(i18n.T("bundle_file"), DefaultBundleFile, 50, nil, nil)
+	form.AddInputField(i18n.T("bundle_device_map"), "", 50, nil, nil)
+	form.AddCheckbox(i18n.T("bundle_activate"), false, nil)
+
+	var showForm func()
+	showForm = func() {
+		showWizardStep(app, form, i18n.T("bundle_import_help"))
+	}
+	form.AddButton(i18n.T("network_preview"), func() {
+		path := strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
+		activate := form.GetFormItem(2).(*tview.Checkbox).IsChecked()
+
+		devices, err := ParseDeviceMap(form.GetFormItem(1).(*tview.InputField).GetText())
+		if err != nil {
+			showMessage(app, i18n.T("error_title"), err.Error())
+			return
+		}
+		bundle, err := LoadBundle(path)
+		if err != nil {
+			showMessage(app, i18n.T("error_title"), err.Error())
+			return
+		}
+		steps, err := PlanImport(bundle, devices)
+		if err != nil {
+			showMessage(app, i18n.T("error_title"), err.Error())
+			return
+		}
+
+		showImportPreview(app, bundle, steps, func() {
+			imported, err := ApplyImport(steps, activate)
+			done(path, steps, imported, err)
+		}, showForm)
+	})
+	form.AddButton(i18n.T("network_cancel"), back)
+
+	showForm()
+}
+
+// Mostra os perfis criados e alterados pela importação, com as propriedades
+// de cada um, como a revisão da configuração de rede
+func showImportPreview(app *tview.Application, bundle Bundle, steps []ImportStep, apply, back func()) {
+	textView := tview.NewTextView()
+	textView.SetDynamicColors(true)
+	textView.SetWordWrap(true)
+	textView.SetScrollable(true)
+	textView.SetBackgroundColor(backgroundColor)
+	textView.SetBorder(true).
+		SetTitle(" " + i18n.T("network_preview") + " ").
+		SetTitleAlign(tview.AlignCenter).
+		SetTitleColor(titleColor).
+		SetBorderColor(titleColor)
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "[yellow]%s[white]\n", tview.Escape(fmt.Sprintf(i18n.T("bundle_origin"),
+		bundle.Host, bundle.Created.Local().Format("2006-01-02 15:04"), bundle.Version)))
+	if len(steps) == 0 {
+		fmt.Fprintf(&text, "\n[gray]%s[white]\n", i18n.T("bundle_empty"))
+	}
+	for _, step := range steps {
+		fmt.Fprintf(&text, "\n[yellow]%s[white] %s (%s, %s)", i18n.T("network_preview_profile"),
+			tview.Escape(step.ProfileID), tview.Escape(step.Type), tview.Escape(orNone(step.Device)))
+		if step.Create {
+			fmt.Fprintf(&text, " [green]%s[white]", i18n.T("network_profile_created"))
+		} else {
+			fmt.Fprintf(&text, " [aqua]%s[white]", i18n.T("bundle_updated"))
+		}
+		text.WriteString("\n")
+		for _, warning := range step.Warnings {
+			fmt.Fprintf(&text, "[red]! %s[white]\n", tview.Escape(warning))
+		}
+		for _, change := range step.Changes {
+			if change.Changed() {
+				fmt.Fprintf(&text, "[white]%-32s [red]%s[white] → [green]%s[white]\n",
+					change.Key, tview.Escape(orNone(change.Before)), tview.Escape(orNone(change.After)))
+			} else {
+				fmt.Fprintf(&text, "[gray]%-32s %s[white]\n", change.Key, tview.Escape(orNone(change.After)))
+			}
+		}
+	}
+	textView.SetText(text.String())
+
+	buttons := tview.NewForm()
+	buttons.SetButtonsAlign(tview.AlignCenter)
+	buttons.SetBackgroundColor(backgroundColor)
+	if len(steps) > 0 {
+		buttons.AddButton(i18n.T("profiles_import"), apply)
+	}
+	buttons.AddButton(i18n.T("network_preview_back"), back)
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(textView, 0, 1, false).
+		AddItem(buttons, 3, 0, true)
+
+	app.SetRoot(flex, true).SetFocus(buttons)
+}
@@ -0,0 +1,610 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"gopkg.in/yaml.v3"
+
+	"networkmanager-tui/backend"
+	"networkmanager-tui/history"
+	"networkmanager-tui/i18n"
+	"networkmanager-tui/logger"
+)
+
+// DesiredStateVersion é a versão do formato do arquivo de estado desejado
+const DesiredStateVersion = 1
+
+// DefaultDesiredStateFile é o arquivo de estado desejado usado quando nenhum
+// outro é informado
+const DefaultDesiredStateFile = "/etc/networkmanager-tui/desired.yaml"
+
+// DesiredState descreve o estado de rede pretendido para o host: a
+// configuração IP de cada interface e os perfis Wi-Fi salvos
+type DesiredState struct {
+	Version    int                `json:"version" yaml:"version"`
+	Interfaces []DesiredInterface `json:"interfaces" yaml:"interfaces"`
+	WiFi       []DesiredWiFi      `json:"wifi" yaml:"wifi"`
+}
+
+// DesiredInterface é a configuração pretendida de uma interface. Uma família
+// omitida mantém a configuração atual.
+type DesiredInterface struct {
+	Name    string     `json:"name" yaml:"name"`
+	Profile string     `json:"profile,omitempty" yaml:"profile,omitempty"` // Vazio usa o perfil do dispositivo
+	IPv4    *DesiredIP `json:"ipv4,omitempty" yaml:"ipv4,omitempty"`
+	IPv6    *DesiredIP `json:"ipv6,omitempty" yaml:"ipv6,omitempty"`
+}
+
+// DesiredIP é a configuração pretendida de uma família de endereços. Listas
+// omitidas ficam vazias: rotas, regras e domínios que não estão no arquivo
+// são removidos.
+type DesiredIP struct {
+	Method       string   `json:"method" yaml:"method"`                                   // auto, manual ou (IPv6) disabled
+	Addresses    []string `json:"addresses,omitempty" yaml:"addresses,omitempty"`         // CIDR; o primeiro é o principal
+	Gateway      string   `json:"gateway,omitempty" yaml:"gateway,omitempty"`             // Apenas no modo manual
+	DNS          []string `json:"dns,omitempty" yaml:"dns,omitempty"`                     // Também vale no modo auto
+	DNSSearch    []string `json:"dns_search,omitempty" yaml:"dns_search,omitempty"`       // Domínios de busca
+	Routes       []string `json:"routes,omitempty" yaml:"routes,omitempty"`               // "destino/prefixo [próximo-salto] [métrica]"
+	RoutingRules []string `json:"routing_rules,omitempty" yaml:"routing_rules,omitempty"` // Como em "configure --rule"
+	RouteMetric  string   `json:"route_metric,omitempty" yaml:"route_metric,omitempty"`   // -1 usa o padrão
+	NeverDefault string   `json:"never_default,omitempty" yaml:"never_default,omitempty"` // yes ou no
+}
+
+// DesiredWiFi é um perfil Wi-Fi que deve estar salvo no host. As senhas ficam
+// em arquivos separados, para que o arquivo de estado possa ser versionado.
+type DesiredWiFi struct {
+	SSID         string      `json:"ssid" yaml:"ssid"`
+	Device       string      `json:"device,omitempty" yaml:"device,omitempty"`     // Vazio usa o primeiro dispositivo Wi-Fi
+	Security     string      `json:"security,omitempty" yaml:"security,omitempty"` // backend.Security*; vazio deduz como em "wifi connect"
+	PasswordFile string      `json:"password_file,omitempty" yaml:"password_file,omitempty"`
+	Hidden       bool        `json:"hidden,omitempty" yaml:"hidden,omitempty"`
+	Autoconnect  *bool       `json:"autoconnect,omitempty" yaml:"autoconnect,omitempty"` // Padrão: sim
+	Priority     *int        `json:"priority,omitempty" yaml:"priority,omitempty"`
+	EAP          *DesiredEAP `json:"eap,omitempty" yaml:"eap,omitempty"`
+}
+
+// DesiredEAP é a autenticação 802.1X de uma rede WPA-Enterprise
+type DesiredEAP struct {
+	Method                 string `json:"method" yaml:"method"` // peap, ttls ou tls
+	Identity               string `json:"identity" yaml:"identity"`
+	AnonymousIdentity      string `json:"anonymous_identity,omitempty" yaml:"anonymous_identity,omitempty"`
+	Phase2                 string `json:"phase2,omitempty" yaml:"phase2,omitempty"`
+	CACert                 string `json:"ca_cert,omitempty" yaml:"ca_cert,omitempty"`
+	ClientCert             string `json:"client_cert,omitempty" yaml:"client_cert,omitempty"`
+	PrivateKey             string `json:"private_key,omitempty" yaml:"private_key,omitempty"`
+	PasswordFile           string `json:"password_file,omitempty" yaml:"password_file,omitempty"`
+	PrivateKeyPasswordFile string `json:"private_key_password_file,omitempty" yaml:"private_key_password_file,omitempty"`
+}
+
+// Tipos de item do estado desejado
+const (
+	DriftInterface = "interface"
+	DriftWiFi      = "wifi"
+)
+
+// Drift é a diferença entre um item do estado desejado e o host
+type Drift struct {
+	Kind     string           `json:"kind" yaml:"kind"`         // DriftInterface ou DriftWiFi
+	Name     string           `json:"name" yaml:"name"`         // Interface ou SSID
+	Profile  string           `json:"profile" yaml:"profile"`   // Perfil alterado ou criado
+	Create   bool             `json:"create" yaml:"create"`     // O perfil não existe
+	Activate bool             `json:"activate" yaml:"activate"` // O perfil não está ativo na interface
+	Changes  []backend.Change `json:"changes" yaml:"changes"`   // Só as propriedades que mudam, com os segredos mascarados
+
+	id       string           // Perfil alterado, ou nome do perfil criado
+	device   string           // Dispositivo do perfil criado
+	settings backend.Settings // Propriedades que mudam, com os segredos
+	previous backend.Settings // Propriedades do perfil antes da alteração
+}
+
+// InSync informa se o item já está no estado desejado
+func (d Drift) InSync() bool {
+	return !d.Create && !d.Activate && len(d.Changes) == 0
+}
+
+// LoadDesiredState lê o arquivo de estado desejado (YAML ou JSON). Campos
+// desconhecidos são recusados, para que erros de digitação não passem
+// despercebidos.
+func LoadDesiredState(path string) (DesiredState, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return DesiredState{}, fmt.Errorf("erro ao abrir o estado desejado: %w", err)
+	}
+	defer file.Close()
+
+	var state DesiredState
+	dec := yaml.NewDecoder(file)
+	dec.KnownFields(true)
+	if err := dec.Decode(&state); err != nil {
+		return DesiredState{}, fmt.Errorf("%w: estado desejado ilegível: %v", ErrInvalidConfig, err)
+	}
+
+	switch {
+	case state.Version < 1:
+		return DesiredState{}, fmt.Errorf("%w: estado desejado sem versão", ErrInvalidConfig)
+	case state.Version > DesiredStateVersion:
+		return DesiredState{}, fmt.Errorf("%w: versão %d do estado desejado não suportada (máximo %d)",
+			ErrInvalidConfig, state.Version, DesiredStateVersion)
+	}
+
+	seen := map[string]bool{}
+	for _, iface := range state.Interfaces {
+		if iface.Name == "" {
+			return DesiredState{}, fmt.Errorf("%w: interface sem nome no estado desejado", ErrInvalidConfig)
+		}
+		if seen[iface.Name] {
+			return DesiredState{}, fmt.Errorf("%w: interface %s repetida no estado desejado", ErrInvalidConfig, iface.Name)
+		}
+		seen[iface.Name] = true
+	}
+	for _, wifi := range state.WiFi {
+		if wifi.SSID == "" || len(wifi.SSID) > maxSSIDLength {
+			return DesiredState{}, fmt.Errorf("%w: SSID inválido no estado desejado: %q", ErrInvalidConfig, wifi.SSID)
+		}
+	}
+	return state, nil
+}
+
+// networkConfig converte a interface na configuração usada pelo formulário,
+// para passar pela mesma validação
+func (d DesiredInterface) networkConfig() NetworkConfig {
+	cfg := NetworkConfig{Interface: d.Name, Profile: d.Profile}
+	if ip := d.IPv4; ip != nil {
+		cfg.IPv4Mode = ip.Method
+		cfg.IPv4Gateway = ip.Gateway
+		cfg.IPv4DNS = ip.DNS
+		cfg.IPv4Routing = ip.routingConfig()
+		if len(ip.Addresses) > 0 {
+			cfg.IPv4Address, cfg.IPv4Netmask, _ = strings.Cut(ip.Addresses[0], "/")
+		}
+	}
+	if ip := d.IPv6; ip != nil {
+		cfg.IPv6Mode = ip.Method
+		cfg.IPv6Gateway = ip.Gateway
+		cfg.IPv6DNS = ip.DNS
+		cfg.IPv6Routing = ip.routingConfig()
+		if len(ip.Addresses) > 0 {
+			cfg.IPv6Address, cfg.IPv6Prefix, _ = strings.Cut(ip.Addresses[0], "/")
+		}
+	}
+	return cfg
+}
+
+// routingConfig converte as opções avançadas; listas omitidas ficam vazias
+// (e não nulas), para que as atuais sejam removidas
+func (ip DesiredIP) routingConfig() RoutingConfig {
+	rc := RoutingConfig{
+		Routes:       nonNil(ip.Routes),
+		RoutingRules: nonNil(ip.RoutingRules),
+		DNSSearch:    nonNil(ip.DNSSearch),
+		RouteMetric:  ip.RouteMetric,
+		NeverDefault: ip.NeverDefault,
+	}
+	if ip.Method == "manual" {
+		rc.Addresses = []string{}
+		if len(ip.Addresses) > 1 {
+			rc.Addresses = ip.Addresses[1:]
+		}
+	}
+	return rc
+}
+
+// settings valida a interface e retorna as propriedades pretendidas do perfil.
+// No modo auto, endereços e gateway fixos são removidos e o DNS informado é
+// usado junto com o recebido por DHCP.
+func (d DesiredInterface) settings() (backend.Settings, error) {
+	settings, err := BuildNetworkSettings(d.networkConfig())
+	if err != nil {
+		return nil, fmt.Errorf("interface %s: %w", d.Name, err)
+	}
+
+	for family, ip := range map[string]*DesiredIP{"ipv4": d.IPv4, "ipv6": d.IPv6} {
+		if ip == nil || ip.Method != "auto" {
+			continue
+		}
+		if len(ip.Addresses) > 0 || ip.Gateway != "" {
+			return nil, fmt.Errorf("%w: interface %s: endereços e gateway %s exigem o modo manual",
+				ErrInvalidConfig, d.Name, family)
+		}
+		for _, server := range ip.DNS {
+			if (family == "ipv4" && !validateIPv4(server)) || (family == "ipv6" && !validateIPv6(server)) {
+				return nil, fmt.Errorf("%w: interface %s: DNS inválido: %s", ErrInvalidConfig, d.Name, server)
+			}
+		}
+		settings[family+".addresses"] = ""
+		settings[family+".gateway"] = ""
+		settings[family+".dns"] = joinNonEmpty(ip.DNS)
+	}
+	return settings, nil
+}
+
+// settings valida a rede e retorna as propriedades pretendidas do perfil,
+// lendo as senhas dos arquivos indicados
+func (w DesiredWiFi) settings() (backend.Settings, error) {
+	cred := WiFiCredentials{Security: w.Security}
+	var err error
+	if cred.Password, err = readSecretFile(w.PasswordFile); err != nil {
+		return nil, err
+	}
+	if e := w.EAP; e != nil {
+		cred.EAP = EAPConfig{
+			Method:            e.Method,
+			Identity:          e.Identity,
+			AnonymousIdentity: e.AnonymousIdentity,
+			Phase2:            e.Phase2,
+			CACert:            e.CACert,
+			ClientCert:        e.ClientCert,
+			PrivateKey:        e.PrivateKey,
+		}
+		if cred.EAP.Password, err = readSecretFile(e.PasswordFile); err != nil {
+			return nil, err
+		}
+		if cred.EAP.PrivateKeyPassword, err = readSecretFile(e.PrivateKeyPasswordFile); err != nil {
+			return nil, err
+		}
+	}
+
+	settings, err := cred.settings()
+	if err != nil {
+		return nil, fmt.Errorf("rede %s: %w", w.SSID, err)
+	}
+	settings["802-11-wireless.ssid"] = w.SSID
+	settings["802-11-wireless.hidden"] = yesNo(w.Hidden)
+	settings["connection.autoconnect"] = yesNo(w.Autoconnect == nil || *w.Autoconnect)
+	if w.Priority != nil {
+		if *w.Priority < MinAutoconnectPriority || *w.Priority > MaxAutoconnectPriority {
+			return nil, fmt.Errorf("%w: rede %s: prioridade deve estar entre %d e %d", ErrInvalidConfig,
+				w.SSID, MinAutoconnectPriority, MaxAutoconnectPriority)
+		}
+		settings["connection.autoconnect-priority"] = strconv.Itoa(*w.Priority)
+	}
+	return settings, nil
+}
+
+// readSecretFile lê uma senha da primeira linha do arquivo; sem arquivo, a
+// senha fica vazia
+func readSecretFile(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("erro ao ler a senha: %w", err)
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	return strings.TrimSuffix(line, "\r"), nil
+}
+
+// CheckDesiredState compara o estado desejado com os perfis e as conexões
+// ativas do host, sem alterar nada. Cada item resulta em um Drift, vazio se
+// o item já está no estado desejado.
+func CheckDesiredState(state DesiredState) ([]Drift, error) {
+	b := backend.Default()
+	drifts := []Drift{}
+
+	for _, iface := range state.Interfaces {
+		drift, err := interfaceDrift(b, iface)
+		if err != nil {
+			return nil, err
+		}
+		drifts = append(drifts, drift)
+	}
+	for _, wifi := range state.WiFi {
+		drift, err := wifiDrift(b, wifi)
+		if err != nil {
+			return nil, err
+		}
+		drifts = append(drifts, drift)
+	}
+	return drifts, nil
+}
+
+// interfaceDrift compara a configuração pretendida com o perfil da interface
+// e verifica se ele é o perfil ativo no dispositivo
+func interfaceDrift(b backend.Backend, iface DesiredInterface) (Drift, error) {
+	settings, err := iface.settings()
+	if err != nil {
+		return Drift{}, err
+	}
+	id, create, err := profileForConfig(b, iface.networkConfig())
+	if err != nil {
+		return Drift{}, err
+	}
+
+	drift := Drift{Kind: DriftInterface, Name: iface.Name, Profile: id, Create: create, id: id, device: iface.Name}
+	current := backend.Settings{}
+	if !create {
+		p, err := b.Profile(id)
+		if err != nil {
+			return Drift{}, fmt.Errorf("perfil %s: %w", id, err)
+		}
+		drift.Profile, drift.id = p.Name, p.ID()
+		current = p.Settings
+
+		dev, err := findDevice(b, iface.Name)
+		if err != nil {
+			return Drift{}, err
+		}
+		drift.Activate = dev.Connection != p.Name
+	} else {
+		drift.Activate = true
+	}
+	drift.previous = current
+	drift.settings, drift.Changes = changedSettings(current, settings)
+	if len(drift.settings) > 0 {
+		// Alterações só valem depois da reativação
+		drift.Activate = true
+	}
+	return drift, nil
+}
+
+// wifiDrift compara a rede pretendida com o perfil salvo do SSID, incluindo
+// as senhas. Perfis Wi-Fi não são ativados: a conexão automática os ativa
+// quando a rede estiver ao alcance.
+func wifiDrift(b backend.Backend, wifi DesiredWiFi) (Drift, error) {
+	settings, err := wifi.settings()
+	if err != nil {
+		return Drift{}, err
+	}
+
+	drift := Drift{Kind: DriftWiFi, Name: wifi.SSID, Profile: wifi.SSID, id: wifi.SSID, device: wifi.Device}
+	current := backend.Settings{}
+	p, err := backend.SavedWiFiProfile(b, wifi.SSID)
+	switch {
+	case errors.Is(err, backend.ErrNotFound):
+		drift.Create = true
+		if drift.device == "" {
+			drift.device, _ = backend.WiFiDevice(b)
+		}
+	case err != nil:
+		return Drift{}, err
+	default:
+		if p, err = backend.ProfileWithSecrets(b, p.ID()); err != nil {
+			return Drift{}, fmt.Errorf("perfil %s: %w", p.Name, err)
+		}
+		drift.Profile, drift.id = p.Name, p.ID()
+		current = p.Settings
+		if wifi.Device != "" {
+			settings["connection.interface-name"] = wifi.Device
+		}
+	}
+	drift.previous = current
+	drift.settings, drift.Changes = changedSettings(current, settings)
+	return drift, nil
+}
+
+// changedSettings retorna as propriedades de after que diferem de current e
+// as alterações correspondentes, com os segredos mascarados. Listas são
+// comparadas sem considerar o separador (vírgula ou espaço).
+func changedSettings(current, after backend.Settings) (backend.Settings, []backend.Change) {
+	changed := backend.Settings{}
+	changes := []backend.Change{}
+	masked, maskedCurrent := after.Masked(), current.Masked()
+	for _, key := range after.Keys() {
+		if strings.Join(splitValues(current[key]), ",") == strings.Join(splitValues(after[key]), ",") {
+			continue
+		}
+		if key == "802-11-wireless.hidden" && current[key] == "" && after[key] == "no" {
+			continue // Ausente vale "no"
+		}
+		changed[key] = after[key]
+		changes = append(changes, backend.Change{Key: key, Before: maskedCurrent[key], After: masked[key]})
+	}
+	return changed, changes
+}
+
+// Reconcile aplica as diferenças encontradas por CheckDesiredState: cria os
+// perfis que faltam, altera só as propriedades que mudam e reativa as
+// interfaces. Itens já no estado desejado não são tocados. Para no primeiro
+// erro e retorna quantos itens foram alterados.
+func Reconcile(drifts []Drift) (int, error) {
+	b := backend.Default()
+	applied := 0
+	for _, drift := range drifts {
+		if drift.InSync() {
+			continue
+		}
+		var err error
+		switch drift.Kind {
+		case DriftWiFi:
+			err = reconcileWiFi(b, drift)
+		default:
+			err = reconcileInterface(b, drift)
+		}
+		if err != nil {
+			return applied, fmt.Errorf("%s %s: %w", drift.Kind, drift.Name, err)
+		}
+		logger.LogInfo("Estado desejado aplicado: %s %s (%d propriedades)", drift.Kind, drift.Name, len(drift.Changes))
+		applied++
+	}
+	return applied, nil
+}
+
+// RecordReconcile registra no histórico cada item alterado por Reconcile, com
+// as propriedades antes e depois. applied é a quantidade de itens concluídos;
+// com err, o item seguinte é registrado como falha.
+func RecordReconcile(path string, drifts []Drift, applied int, via string, err error) {
+	done := 0
+	for _, drift := range drifts {
+		if drift.InSync() {
+			continue
+		}
+		var itemErr error
+		if done == applied {
+			if err == nil {
+				break
+			}
+			itemErr = err
+		}
+		after := drift.previous.Clone()
+		for key, value := range drift.settings {
+			after[key] = value
+		}
+		diff := history.Diff(drift.previous, after)
+		action := history.Action{
+			Action:     "reconcile",
+			Interface:  drift.device,
+			Profile:    drift.id,
+			Details:    fmt.Sprintf("Estado desejado %s: %s %s (%s)", path, drift.Kind, drift.Name, outcomeText(itemErr)),
+			Diff:       diff,
+			ModifiedBy: via,
+		}.WithResult(itemErr)
+		// Só perfis que já existiam podem voltar ao estado anterior
+		if itemErr == nil && !drift.Create {
+			action.Snapshot = history.Snapshot(drift.previous, diff)
+		}
+		history.Record(action)
+		if itemErr != nil {
+			break
+		}
+		done++
+	}
+}
+
+// reconcileInterface cria o perfil, se preciso, altera as propriedades e
+// ativa o perfil na interface
+func reconcileInterface(b backend.Backend, drift Drift) error {
+	id := drift.id
+	if drift.Create {
+		var err error
+		if id, err = createProfile(b, drift.device, drift.id); err != nil {
+			return err
+		}
+	}
+	if len(drift.settings) > 0 {
+		if err := b.ModifyProfile(id, drift.settings); err != nil {
+			if drift.Create {
+				b.DeleteProfile(id)
+			}
+			return fmt.Errorf("erro ao configurar a conexão: %w", err)
+		}
+	}
+	if err := b.Activate(id); err != nil {
+		return fmt.Errorf("erro ao ativar a conexão: %w", err)
+	}
+	return nil
+}
+
+// reconcileWiFi cria ou altera o perfil salvo da rede. Como o perfil não é
+// ativado, as senhas precisam estar gravadas nele ao final; elas são
+// conferidas lendo o perfil com os segredos.
+func reconcileWiFi(b backend.Backend, drift Drift) error {
+	if !drift.Create {
+		if err := b.ModifyProfile(drift.id, drift.settings); err != nil {
+			return err
+		}
+		return verifySecrets(b, drift.id, drift.settings)
+	}
+	settings := backend.Settings{}
+	for key, value := range drift.settings {
+		if value != "" {
+			settings[key] = value
+		}
+	}
+	p, err := b.AddProfile(backend.Profile{Name: drift.Name, Type: backend.WiFiType, Device: drift.device, Settings: settings})
+	if err != nil {
+		return err
+	}
+	return verifySecrets(b, p.ID(), settings)
+}
+
+// ShowDesiredState compara o estado desejado de DefaultDesiredStateFile com o
+// host e mostra as diferenças. A tela só consulta: as alterações são
+// aplicadas pelo comando "reconcile".
+func ShowDesiredState(app *tview.Application) {
+	textView := tview.NewTextView()
+	textView.SetDynamicColors(true)
+	textView.SetWordWrap(true)
+	textView.SetScrollable(true)
+	textView.SetBackgroundColor(backgroundColor)
+	textView.SetBorder(true).
+		SetTitle(" 📋 " + i18n.T("desired_title") + " 📋 ").
+		SetTitleAlign(tview.AlignCenter).
+		SetTitleColor(titleColor).
+		SetBorderColor(borderColor)
+
+	refresh := func() {
+		textView.SetText(desiredStateText(DefaultDesiredStateFile))
+		textView.ScrollToBeginning()
+	}
+	refresh()
+
+	buttons := tview.NewForm()
+	buttons.SetButtonsAlign(tview.AlignCenter)
+	buttons.SetBackgroundColor(backgroundColor)
+	buttons.SetButtonBackgroundColor(buttonBgColor)
+	buttons.SetButtonTextColor(buttonTextColor)
+	buttons.AddButton(i18n.T("desired_check"), refresh)
+	buttons.AddButton(i18n.T("network_back"), func() {
+		app.Stop() // Retorna ao menu principal
+	})
+
+	helpText := tview.NewTextView()
+	helpText.SetTextAlign(tview.AlignCenter)
+	helpText.SetDynamicColors(true)
+	helpText.SetText("[yellow]" + i18n.T("desired_help") + " • " + i18n.T("press_esc_return") + "[white]")
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(textView, 0, 1, false).
+		AddItem(buttons, 3, 0, true).
+		AddItem(helpText, 1, 0, false)
+
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyUp, tcell.KeyDown, tcell.KeyPgUp, tcell.KeyPgDn:
+			// Rola o relatório sem tirar o foco dos botões
+			textView.InputHandler()(event, func(tview.Primitive) {})
+			return nil
+		}
+		return event
+	})
+
+	app.SetRoot(flex, true).SetFocus(buttons)
+}
+
+// desiredStateText descreve, com cores, cada item do estado desejado e as
+// propriedades que diferem do host
+func desiredStateText(path string) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "[yellow]%s[white] %s\n", i18n.T("bundle_file")+":", tview.Escape(path))
+
+	state, err := LoadDesiredState(path)
+	var drifts []Drift
+	if err == nil {
+		drifts, err = CheckDesiredState(state)
+	}
+	if err != nil {
+		fmt.Fprintf(&text, "\n[red]%s[white]\n", tview.Escape(err.Error()))
+		return text.String()
+	}
+	if len(drifts) == 0 {
+		fmt.Fprintf(&text, "\n[gray]%s[white]\n", i18n.T("desired_empty"))
+	}
+
+	for _, drift := range drifts {
+		fmt.Fprintf(&text, "\n[yellow]%s %s[white] (%s) ", drift.Kind, tview.Escape(drift.Name), tview.Escape(drift.Profile))
+		if drift.InSync() {
+			fmt.Fprintf(&text, "[green]%s[white]\n", i18n.T("desired_in_sync"))
+			continue
+		}
+		fmt.Fprintf(&text, "[red]%s[white]\n", i18n.T("desired_drift"))
+		if drift.Create {
+			fmt.Fprintf(&text, "  [aqua]%s[white]\n", i18n.T("network_profile_created"))
+		}
+		for _, change := range drift.Changes {
+			fmt.Fprintf(&text, "  %-32s [red]%s[white] → [green]%s[white]\n",
+				change.Key, tview.Escape(orNone(change.Before)), tview.Escape(orNone(change.After)))
+		}
+		if drift.Activate {
+			fmt.Fprintf(&text, "  [aqua]%s[white]\n", i18n.T("desired_activate"))
+		}
+	}
+	return text.String()
+}
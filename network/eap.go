@@ -0,0 +1,175 @@
+package network
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rivo/tview"
+
+	"networkmanager-tui/backend"
+	"networkmanager-tui/i18n"
+)
+
+// EAPNone remove a autenticação 802.1X do perfil
+const EAPNone = "none"
+
+// Métodos EAP aceitos na autenticação 802.1X
+var EAPMethods = []string{"peap", "ttls", "tls"}
+
+// Métodos da segunda fase (phase2) do PEAP e do TTLS
+var Phase2Methods = []string{"mschapv2", "gtc", "pap", "chap", "md5"}
+
+// EAPConfig descreve a autenticação 802.1X (WPA-Enterprise no Wi-Fi ou porta
+// cabeada autenticada). Method vazio mantém a configuração atual do perfil;
+// senhas vazias mantêm as senhas salvas.
+type EAPConfig struct {
+	Method             string // peap, ttls, tls ou none
+	Identity           string
+	AnonymousIdentity  string
+	CACert             string // Caminhos dos arquivos PEM
+	ClientCert         string
+	PrivateKey         string
+	PrivateKeyPassword string
+	Phase2             string // Segunda fase do PEAP e do TTLS (padrão mschapv2)
+	Password           string
+}
+
+// Settings valida a configuração e a converte nas propriedades 802-1x.*
+func (c EAPConfig) Settings() (backend.Settings, error) {
+	switch c.Method {
+	case "":
+		return backend.Settings{}, nil
+	case EAPNone:
+		return backend.Settings{
+			"802-1x.eap": "", "802-1x.identity": "", "802-1x.anonymous-identity": "",
+			"802-1x.ca-cert": "", "802-1x.client-cert": "", "802-1x.private-key": "",
+			"802-1x.private-key-password": "", "802-1x.phase2-auth": "", "802-1x.password": "",
+		}, nil
+	}
+	if !contains(EAPMethods, c.Method) {
+		return nil, fmt.Errorf("%w: método EAP desconhecido: %s", ErrInvalidConfig, c.Method)
+	}
+	if c.Identity == "" {
+		return nil, fmt.Errorf("%w: a autenticação 802.1X exige a identidade", ErrInvalidConfig)
+	}
+
+	settings := backend.Settings{
+		"802-1x.eap":                c.Method,
+		"802-1x.identity":           c.Identity,
+		"802-1x.anonymous-identity": c.AnonymousIdentity,
+		"802-1x.ca-cert":            c.CACert,
+		"802-1x.client-cert":        c.ClientCert,
+		"802-1x.private-key":        c.PrivateKey,
+	}
+	if c.Method == "tls" {
+		if c.ClientCert == "" || c.PrivateKey == "" {
+			return nil, fmt.Errorf("%w: EAP-TLS exige o certificado do cliente e a chave privada", ErrInvalidConfig)
+		}
+		settings["802-1x.phase2-auth"] = ""
+	} else {
+		phase2 := c.Phase2
+		if phase2 == "" {
+			phase2 = "mschapv2"
+		}
+		if !contains(Phase2Methods, phase2) {
+			return nil, fmt.Errorf("%w: método de segunda fase desconhecido: %s", ErrInvalidConfig, phase2)
+		}
+		settings["802-1x.phase2-auth"] = phase2
+	}
+
+	for _, path := range []string{c.CACert, c.ClientCert, c.PrivateKey} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("%w: arquivo não encontrado: %s", ErrInvalidConfig, path)
+		}
+	}
+
+	if c.Password != "" {
+		settings["802-1x.password"] = c.Password
+	}
+	if c.PrivateKeyPassword != "" {
+		settings["802-1x.private-key-password"] = c.PrivateKeyPassword
+	}
+	return settings, nil
+}
+
+// eapConfigFromSettings lê a autenticação 802.1X do perfil, sem as senhas.
+// Perfis sem 802.1X resultam em Method vazio.
+func eapConfigFromSettings(settings backend.Settings) EAPConfig {
+	// Perfis com vários métodos são editados pelo primeiro
+	var method string
+	if methods := splitValues(settings["802-1x.eap"]); len(methods) > 0 {
+		method = methods[0]
+	}
+	return EAPConfig{
+		Method:            method,
+		Identity:          settings["802-1x.identity"],
+		AnonymousIdentity: settings["802-1x.anonymous-identity"],
+		CACert:            settings["802-1x.ca-cert"],
+		ClientCert:        settings["802-1x.client-cert"],
+		PrivateKey:        settings["802-1x.private-key"],
+		Phase2:            settings["802-1x.phase2-auth"],
+	}
+}
+
+// Mostra o formulário da autenticação 802.1X. Com optional, a primeira opção
+// do método desabilita o 802.1X (portas cabeadas); ok é chamada depois que eap
+// é atualizado com o que foi digitado.
+func showEAPForm(app *tview.Application, title string, eap *EAPConfig, optional bool, ok, cancel func()) {
+	form := newForm(title)
+
+	methods := EAPMethods
+	if optional {
+		methods = append([]string{i18n.T("eap_disabled")}, EAPMethods...)
+	}
+	method := indexOf(methods, eap.Method)
+	if method < 0 {
+		method = 0
+	}
+	phase2 := indexOf(Phase2Methods, eap.Phase2)
+	if phase2 < 0 {
+		phase2 = 0
+	}
+
+	form.AddDropDown(i18n.T("eap_method"), methods, method, nil)
+	form.AddInputField(i18n.T("eap_identity"), eap.Identity, 40, nil, nil)
+	form.AddInputField(i18n.T("eap_anonymous_identity"), eap.AnonymousIdentity, 40, nil, nil)
+	form.AddInputField(i18n.T("eap_ca_cert"), eap.CACert, 50, nil, nil)
+	form.AddInputField(i18n.T("eap_client_cert"), eap.ClientCert, 50, nil, nil)
+	form.AddInputField(i18n.T("eap_private_key"), eap.PrivateKey, 50, nil, nil)
+	form.AddPasswordField(i18n.T("eap_private_key_password"), eap.PrivateKeyPassword, 40, '*', nil)
+	form.AddDropDown(i18n.T("eap_phase2"), Phase2Methods, phase2, nil)
+	form.AddPasswordField(i18n.T("eap_password"), eap.Password, 40, '*', nil)
+
+	form.AddButton("OK", func() {
+		text := func(label string) string {
+			return form.GetFormItemByLabel(i18n.T(label)).(*tview.InputField).GetText()
+		}
+		_, method := form.GetFormItemByLabel(i18n.T("eap_method")).(*tview.DropDown).GetCurrentOption()
+		_, phase2 := form.GetFormItemByLabel(i18n.T("eap_phase2")).(*tview.DropDown).GetCurrentOption()
+		if !contains(EAPMethods, method) {
+			// Desabilitar só altera o perfil se ele tinha 802.1X
+			method = ""
+			if eap.Method != "" {
+				method = EAPNone
+			}
+		}
+		*eap = EAPConfig{
+			Method:             method,
+			Identity:           text("eap_identity"),
+			AnonymousIdentity:  text("eap_anonymous_identity"),
+			CACert:             text("eap_ca_cert"),
+			ClientCert:         text("eap_client_cert"),
+			PrivateKey:         text("eap_private_key"),
+			PrivateKeyPassword: text("eap_private_key_password"),
+			Phase2:             phase2,
+			Password:           text("eap_password"),
+		}
+		ok()
+	})
+	form.AddButton(i18n.T("network_cancel"), cancel)
+
+	showWizardStep(app, form, i18n.T("eap_help"))
+}
@@ -0,0 +1,240 @@
+package network
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"networkmanager-tui/history"
+	"networkmanager-tui/i18n"
+)
+
+// Quantidade máxima de ações mostradas na tela do histórico
+const historyScreenLimit = 500
+
+// ShowHistory mostra o histórico de auditoria com filtros por período,
+// usuário, tipo de ação e interface. Enter em uma linha mostra os detalhes e
+// as alterações da ação.
+func ShowHistory(app *tview.Application) {
+	table := tview.NewTable()
+	table.SetBorders(false)
+	table.SetBorder(true)
+	table.SetBorderColor(borderColor)
+	table.SetTitle(" 📜 " + i18n.T("history_title") + " 📜 ")
+	table.SetTitleColor(titleColor)
+	table.SetTitleAlign(tview.AlignCenter)
+	table.SetSelectable(true, false)
+	table.SetFixed(1, 0)
+	table.SetBackgroundColor(backgroundColor)
+
+	headers := []string{
+		i18n.T("history_time"),
+		i18n.T("history_user"),
+		i18n.T("history_action"),
+		i18n.T("history_interface"),
+		i18n.T("history_via"),
+		i18n.T("history_details"),
+	}
+	for col, header := range headers {
+		table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(headerColor).
+			SetSelectable(false))
+	}
+
+	filters := tview.NewForm()
+	filters.SetHorizontal(true)
+	filters.SetBackgroundColor(backgroundColor)
+	filters.SetFieldBackgroundColor(fieldBgColor)
+	filters.SetFieldTextColor(fieldTextColor)
+	filters.SetLabelColor(labelColor)
+	filters.SetButtonBackgroundColor(buttonBgColor)
+	filters.SetButtonTextColor(buttonTextColor)
+	filters.AddInputField(i18n.T("history_since"), "7d", 17, nil, nil)
+	filters.AddInputField(i18n.T("history_until"), "", 17, nil, nil)
+	filters.AddInputField(i18n.T("history_user"), "", 10, nil, nil)
+	filters.AddInputField(i18n.T("history_action"), "", 16, nil, nil)
+	filters.AddInputField(i18n.T("history_interface"), "", 10, nil, nil)
+
+	text := func(i int) string {
+		return strings.TrimSpace(filters.GetFormItem(i).(*tview.InputField).GetText())
+	}
+
+	var actions []history.Action
+	refresh := func() {
+		filter, err := historyFilter(text(0), text(1), text(2), text(3), text(4))
+		if err == nil {
+			actions, err = history.Query(filter)
+		}
+		if err != nil {
+			actions = nil
+		}
+		fillHistoryTable(table, actions, err)
+	}
+
+	var flex *tview.Flex
+	filters.AddButton(i18n.T("history_filter"), func() {
+		refresh()
+		app.SetFocus(table)
+	})
+	filters.AddButton(i18n.T("network_back"), func() {
+		app.Stop() // Retorna ao menu principal
+	})
+
+	table.SetSelectedFunc(func(row, column int) {
+		if row < 1 || row > len(actions) {
+			return
+		}
+		// A tabela mostra a ação mais recente primeiro
+		action := actions[len(actions)-row]
+		back := func() {
+			app.SetRoot(flex, true).SetFocus(table)
+		}
+		buttons := []string{"OK"}
+		if action.Undoable() {
+			buttons = append(buttons, i18n.T("history_undo"))
+		}
+		modal := tview.NewModal().
+			SetText(historyActionText(action)).
+			AddButtons(buttons).
+			SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+				if buttonIndex == 1 {
+					showUndo(app, action, back)
+					return
+				}
+				back()
+			})
+		modal.SetBorder(true).
+			SetTitle(" " + action.Action + " ").
+			SetTitleAlign(tview.AlignCenter).
+			SetTitleColor(titleColor).
+			SetBorderColor(borderColor).
+			SetBackgroundColor(backgroundColor)
+		app.SetRoot(modal, true)
+	})
+
+	helpText := tview.NewTextView()
+	helpText.SetTextAlign(tview.AlignCenter)
+	helpText.SetDynamicColors(true)
+	helpText.SetText("[yellow]" + i18n.T("history_help") + " " + i18n.T("press_esc_return") + "[white]")
+
+	flex = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(filters, 3, 0, false).
+		AddItem(table, 0, 1, true).
+		AddItem(helpText, 2, 0, false)
+
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyTab && table.HasFocus():
+			app.SetFocus(filters)
+			return nil
+		case event.Key() == tcell.KeyBacktab && filters.HasFocus():
+			app.SetFocus(table)
+			return nil
+		}
+		return event
+	})
+
+	refresh()
+	app.SetRoot(flex, true).SetFocus(table)
+}
+
+// historyFilter monta o filtro da consulta a partir dos campos da tela
+func historyFilter(since, until, user, action, iface string) (history.Filter, error) {
+	filter := history.Filter{User: user, Action: action, Interface: iface, Limit: historyScreenLimit}
+	now := time.Now()
+	var err error
+	if since != "" {
+		if filter.Since, err = history.ParseSince(since, now); err != nil {
+			return filter, err
+		}
+	}
+	if until != "" {
+		if filter.Until, err = history.ParseUntil(until, now); err != nil {
+			return filter, err
+		}
+	}
+	return filter, nil
+}
+
+// fillHistoryTable preenche a tabela com as ações, da mais recente para a
+// mais antiga
+func fillHistoryTable(table *tview.Table, actions []history.Action, err error) {
+	for r := table.GetRowCount() - 1; r > 0; r-- {
+		table.RemoveRow(r)
+	}
+
+	if err != nil {
+		table.SetCell(1, 0, tview.NewTableCell(err.Error()).
+			SetTextColor(errorColor).
+			SetSelectable(false))
+		return
+	}
+	if len(actions) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell(i18n.T("history_empty")).
+			SetTextColor(infoColor).
+			SetSelectable(false))
+		return
+	}
+
+	for i := range actions {
+		a := actions[len(actions)-1-i]
+		details, detailsColor := a.Details, fieldTextColor
+		if a.Outcome == history.OutcomeFailure || strings.Contains(details, "falha:") {
+			detailsColor = errorColor
+		}
+		cells := []struct {
+			text  string
+			color tcell.Color
+		}{
+			{a.Timestamp.Format("02/01/2006 15:04:05"), fieldTextColor},
+			{a.UserID, fieldTextColor},
+			{a.Action, labelColor},
+			{orDash(a.Interface), fieldTextColor},
+			{a.ModifiedBy, fieldTextColor},
+			{orDash(details), detailsColor},
+		}
+		for col, cell := range cells {
+			table.SetCell(i+1, col, tview.NewTableCell(cell.text).
+				SetTextColor(cell.color).
+				SetExpansion(col/5))
+		}
+	}
+	table.Select(1, 0)
+}
+
+// historyActionText descreve a ação para a janela de detalhes
+func historyActionText(a history.Action) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s\n", i18n.T("history_time"), a.Timestamp.Format("02/01/2006 15:04:05"))
+	fmt.Fprintf(&b, "%s: %s (%s)\n", i18n.T("history_user"), a.UserID, a.ModifiedBy)
+	if origin := a.Origin(); origin != "" {
+		fmt.Fprintf(&b, "%s: %s\n", i18n.T("history_origin"), origin)
+	}
+	if a.Interface != "" {
+		fmt.Fprintf(&b, "%s: %s\n", i18n.T("history_interface"), a.Interface)
+	}
+	if a.Profile != "" {
+		fmt.Fprintf(&b, "%s: %s\n", i18n.T("history_profile"), a.Profile)
+	}
+	if a.Outcome != "" {
+		fmt.Fprintf(&b, "%s: %s\n", i18n.T("history_outcome"), i18n.T("history_outcome_"+a.Outcome))
+	}
+	if a.Error != "" {
+		fmt.Fprintf(&b, "%s: %s\n", i18n.T("error_title"), a.Error)
+	}
+	fmt.Fprintf(&b, "\n%s\n", orDash(a.Details))
+	if len(a.Diff) > 0 {
+		fmt.Fprintf(&b, "\n%s:\n", i18n.T("history_changes"))
+		for _, change := range a.Diff {
+			fmt.Fprintf(&b, "%s: %s → %s\n", change.Key, orNone(change.Before), orNone(change.After))
+		}
+	}
+	if a.Changes != "" {
+		fmt.Fprintf(&b, "\n%s:\n%s\n", i18n.T("history_changes"), a.Changes)
+	}
+	return b.String()
+}
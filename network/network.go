@@ -2,15 +2,22 @@ package network
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+	"net"
 	"os/exec"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"networkmanager-tui/backend"
+	"networkmanager-tui/history"
 	"networkmanager-tui/i18n"
-	"os"
+	"networkmanager-tui/logger"
+	"networkmanager-tui/safeapply"
 )
 
 // Definição de cores utilizadas na interface - Paleta melhorada
@@ -24,7 +31,9 @@ func runCommandWithTimeout(name string, args ...string) ([]byte, error) {
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, name, args...)
+	start := time.Now()
 	out, err := cmd.Output()
+	logger.Command(name, args, time.Since(start), err)
 	if ctx.Err() == context.DeadlineExceeded {
 		return nil, fmt.Errorf("timeout ao executar comando %s", name)
 	}
@@ -112,36 +121,19 @@ func validateIPv6Prefix(prefix string) bool {
 	return match
 }
 
-// Função que obtém as conexões de rede disponíveis usando o comando `nmcli`
+// Função que obtém os nomes dos dispositivos de rede disponíveis
 func GetNetworkConnections() ([]string, error) {
-	cmd := exec.Command("nmcli", "device", "status")
-	out, err := cmd.Output()
+	devices, err := backend.Default().Devices()
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("erro ao executar nmcli (código %d): %s", exitErr.ExitCode(), string(exitErr.Stderr))
-		}
 		return nil, fmt.Errorf("erro ao obter conexões de rede: %w", err)
 	}
-	if len(out) == 0 {
-		return nil, fmt.Errorf("nenhuma saída do comando nmcli")
-	}
 
-	// Processa a saída do comando, extraindo os nomes das interfaces de rede
-	lines := strings.Split(string(out), "\n")
 	var interfaces []string
-	for i, line := range lines {
-		if i == 0 { // Pula o cabeçalho
-			continue
-		}
-		if len(line) > 0 {
-			fields := strings.Fields(line)
-			if len(fields) > 0 {
-				interfaces = append(interfaces, fields[0])
-			}
-		}
+	for _, dev := range devices {
+		interfaces = append(interfaces, dev.Name)
 	}
 	if len(interfaces) == 0 {
-		// Fallback para interfaces comuns se o nmcli não retornar nada
+		// Fallback para interfaces comuns se o backend não retornar nada
 		return []string{"eth0", "wlan0"}, nil
 	}
 	return interfaces, nil
@@ -149,101 +141,70 @@ func GetNetworkConnections() ([]string, error) {
 
 // Função para obter o nome da conexão ativa
 func GetActiveConnection() (string, error) {
-	cmd := exec.Command("nmcli", "-t", "-f", "NAME", "connection", "show", "--active")
-	output, err := cmd.Output()
+	profiles, err := backend.Default().Profiles()
 	if err != nil {
 		return "", fmt.Errorf("erro ao obter a conexão ativa: %w", err)
 	}
 
-	// Retorna o nome da conexão ativa
-	return strings.TrimSpace(string(output)), nil
+	// Retorna os nomes das conexões ativas
+	var active []string
+	for _, p := range profiles {
+		if p.Active {
+			active = append(active, p.Name)
+		}
+	}
+	return strings.Join(active, "\n"), nil
 }
 
 // Estrutura para armazenar informações detalhadas de uma conexão de rede
 type NetworkConnectionInfo struct {
-	Name      string // Nome da conexão
-	Type      string // Tipo de conexão (wifi, ethernet, etc)
-	Device    string // Dispositivo associado
-	State     string // Estado da conexão (conectado, desconectado, etc)
-	IPv4      string // Endereço IPv4
-	IPv6      string // Endereço IPv6
-	MAC       string // Endereço MAC
-	Gateway   string // Gateway padrão
-	DNS       string // Servidores DNS
+	Name     string   `json:"name" yaml:"name"`         // Nome da conexão
+	Type     string   `json:"type" yaml:"type"`         // Tipo de conexão (wifi, ethernet, etc)
+	Device   string   `json:"device" yaml:"device"`     // Dispositivo associado
+	State    string   `json:"state" yaml:"state"`       // Estado da conexão (conectado, desconectado, etc)
+	IPv4     []string `json:"ipv4" yaml:"ipv4"`         // Endereços IPv4 em notação CIDR
+	IPv6     []string `json:"ipv6" yaml:"ipv6"`         // Endereços IPv6 em notação CIDR
+	MAC      string   `json:"mac" yaml:"mac"`           // Endereço MAC
+	Gateway  string   `json:"gateway" yaml:"gateway"`   // Gateway padrão IPv4
+	Gateway6 string   `json:"gateway6" yaml:"gateway6"` // Gateway padrão IPv6
+	DNS      []string `json:"dns" yaml:"dns"`           // Servidores DNS
 }
 
 // Obtém informações detalhadas das conexões de rede ativas
 func GetNetworkConnectionsInfo() ([]NetworkConnectionInfo, error) {
-	// Obtém o estado dos dispositivos
-	cmd := exec.Command("nmcli", "-t", "device", "status")
-	devOutput, err := cmd.Output()
+	devices, err := backend.Default().Devices()
 	if err != nil {
 		return nil, fmt.Errorf("erro ao obter status dos dispositivos: %w", err)
 	}
 
-	// Processa a saída
-	lines := strings.Split(string(devOutput), "\n")
-	var connections []NetworkConnectionInfo
-
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
-
-		fields := strings.Split(line, ":")
-		if len(fields) >= 4 {
-			connInfo := NetworkConnectionInfo{
-				Device: fields[0],
-				Type:   fields[1],
-				State:  fields[2],
-				Name:   fields[3],
-			}
-
-			// Se o dispositivo estiver conectado, obtém mais informações
-			if connInfo.State == "connected" {
-				// Obtém informações de IP para esta conexão
-				ipCmd := exec.Command("nmcli", "-t", "device", "show", connInfo.Device)
-				ipOutput, err := ipCmd.Output()
-				if err == nil {
-					ipLines := strings.Split(string(ipOutput), "\n")
-					for _, ipLine := range ipLines {
-						if ipLine == "" {
-							continue
-						}
-
-						ipFields := strings.Split(ipLine, ":")
-						if len(ipFields) >= 2 {
-							key := ipFields[0]
-							value := ipFields[1]
-
-							switch {
-							case strings.Contains(key, "IP4.ADDRESS"):
-								connInfo.IPv4 = value
-							case strings.Contains(key, "IP6.ADDRESS"):
-								connInfo.IPv6 = value
-							case strings.Contains(key, "GENERAL.HWADDR"):
-								connInfo.MAC = value
-							case strings.Contains(key, "IP4.GATEWAY"):
-								connInfo.Gateway = value
-							case strings.Contains(key, "IP4.DNS"):
-								if connInfo.DNS == "" {
-									connInfo.DNS = value
-								} else {
-									connInfo.DNS += ", " + value
-								}
-							}
-						}
-					}
-				}
-			}
-
-			connections = append(connections, connInfo)
-		}
+	connections := []NetworkConnectionInfo{}
+	for _, dev := range devices {
+		connections = append(connections, NetworkConnectionInfo{
+			Device:   dev.Name,
+			Type:     dev.Type,
+			State:    dev.State,
+			Name:     dev.Connection,
+			IPv4:     nonNil(dev.IPv4),
+			IPv6:     nonNil(dev.IPv6),
+			MAC:      dev.MAC,
+			Gateway:  dev.Gateway,
+			Gateway6: dev.Gateway6,
+			DNS:      nonNil(dev.DNS),
+		})
 	}
 
 	return connections, nil
 }
 
+// nonNil troca listas nulas por vazias, para que a saída JSON/YAML traga
+// listas vazias em vez de null
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
 // Exibe uma mensagem de erro/sucesso com cores apropriadas
 func showMessage(app *tview.Application, title, message string) {
 	modal := tview.NewModal().
@@ -276,9 +237,6 @@ func showMessage(app *tview.Application, title, message string) {
 
 // Função que exibe o status atual das conexões de rede
 func ShowNetworkStatus(app *tview.Application) *tview.Flex {
-	// Obtém informações detalhadas das conexões
-	connections, err := GetNetworkConnectionsInfo()
-
 	// Flex container principal
 	flex := tview.NewFlex().SetDirection(tview.FlexRow)
 
@@ -316,77 +274,85 @@ func ShowNetworkStatus(app *tview.Application) *tview.Flex {
 		table.SetCell(0, col, cell)
 	}
 
+	// Preenche a tabela com os dados atuais
+	connections, err := GetNetworkConnectionsInfo()
+	fillStatusTable(table, connections, err)
+
+	flex.AddItem(table, 0, 1, true)
+
+	// Adiciona botões de ação
+	buttonsForm := tview.NewForm()
+	buttonsForm.SetBackgroundColor(backgroundColor)
+
+	buttonsForm.AddButton(i18n.T("network_back"), func() {
+		StopNetworkStatus()
+		app.Stop() // Retorna ao menu principal
+	})
+
+	// Adicionando texto de ajuda
+	helpText := tview.NewTextView()
+	helpText.SetTextAlign(tview.AlignCenter)
+	helpText.SetDynamicColors(true)
+	helpText.SetText("[green]● " + i18n.T("network_live_updates") + " [yellow]• Tab: Navegar • Enter: Selecionar • " + i18n.T("press_esc_return") + "[white]")
+
+	// Configurando ordem de foco
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyTab || event.Key() == tcell.KeyRight {
+			// Se estiver na tabela, move para os botões
+			if table.HasFocus() {
+				app.SetFocus(buttonsForm)
+				return nil
+			}
+		}
+		if event.Key() == tcell.KeyBacktab || event.Key() == tcell.KeyLeft {
+			// Se estiver nos botões, volta para a tabela
+			if buttonsForm.HasFocus() {
+				app.SetFocus(table)
+				return nil
+			}
+		}
+		return event
+	})
+
+	flex.AddItem(buttonsForm, 3, 0, true) // Mudado para true para permitir foco
+	flex.AddItem(helpText, 1, 0, false)
+
+	// Atualiza a tabela sempre que o estado da rede mudar
+	watchNetworkStatus(app, table)
+
+	return flex
+}
+
+// fillStatusTable substitui as linhas de dados da tabela de status, mantendo
+// o cabeçalho, o dispositivo selecionado e a posição de rolagem
+func fillStatusTable(table *tview.Table, connections []NetworkConnectionInfo, err error) {
+	// Guarda o dispositivo selecionado para restaurar a seleção depois
+	selected := ""
+	if row, _ := table.GetSelection(); row > 0 && row < table.GetRowCount() {
+		selected = table.GetCell(row, 0).Text
+	}
+	rowOffset, colOffset := table.GetOffset()
+
+	for row := table.GetRowCount() - 1; row > 0; row-- {
+		table.RemoveRow(row)
+	}
+
 	// Se não conseguiu obter os dados, mostra mensagem de erro
 	if err != nil {
-		// Mostra mensagem na tabela
 		errorCell := tview.NewTableCell(i18n.T("error_network_info") + ": " + err.Error()).
 			SetTextColor(errorColor).
 			SetAlign(tview.AlignCenter).
 			SetSelectable(false).
 			SetExpansion(1)
 		table.SetCell(1, 0, errorCell)
-		table.SetCell(1, 1, tview.NewTableCell("").SetSelectable(false))
-		table.SetCell(1, 2, tview.NewTableCell("").SetSelectable(false))
-		table.SetCell(1, 3, tview.NewTableCell("").SetSelectable(false))
-		table.SetCell(1, 4, tview.NewTableCell("").SetSelectable(false))
-		table.SetCell(1, 5, tview.NewTableCell("").SetSelectable(false))
-		table.SetCell(1, 6, tview.NewTableCell("").SetSelectable(false))
-		table.SetCell(1, 7, tview.NewTableCell("").SetSelectable(false))
-
-		// Em desenvolvimento simulamos dados para testes
-		if os.Getenv("DEV_MODE") == "true" {
-			// Gera dados simulados para testes
-			connections = []NetworkConnectionInfo{
-				{
-					Device:  "eth0",
-					Type:    "ethernet",
-					State:   "connected",
-					Name:    "Ethernet Connection",
-					IPv4:    "192.168.1.100/24",
-					IPv6:    "fe80::1234:5678:abcd:ef12/64",
-					Gateway: "192.168.1.1",
-					DNS:     "8.8.8.8, 8.8.4.4",
-				},
-				{
-					Device:  "wlan0",
-					Type:    "wifi",
-					State:   "disconnected",
-					Name:    "Wi-Fi Network",
-				},
-				{
-					Device:  "tun0",
-					Type:    "tun",
-					State:   "connected",
-					Name:    "VPN Connection",
-					IPv4:    "10.8.0.2/24",
-					Gateway: "10.8.0.1",
-					DNS:     "10.8.0.1",
-				},
-			}
-		} else {
-			// Em caso de erro e não estando em modo dev, mantem a tela com a mensagem de erro
-			flex.AddItem(table, 0, 1, true)
-
-			// Adiciona botões de ação
-			buttonsForm := tview.NewForm()
-			buttonsForm.SetBackgroundColor(backgroundColor)
-
-			buttonsForm.AddButton(i18n.T("network_back"), func() {
-				app.Stop() // Retorna ao menu principal
-			})
-
-			buttonsForm.AddButton(i18n.T("network_refresh"), func() {
-				// Recria a tela com dados atualizados
-				app.SetRoot(ShowNetworkStatus(app), true)
-			})
-
-			flex.AddItem(buttonsForm, 3, 0, false)
-
-			return flex
+		for col := 1; col < table.GetColumnCount(); col++ {
+			table.SetCell(1, col, tview.NewTableCell("").SetSelectable(false))
 		}
+		return
 	}
 
 	// Preenche a tabela com os dados obtidos
+	selectedRow := 1
 	for row, conn := range connections {
 		// Define a cor baseada no estado da conexão
 		var stateColor tcell.Color
@@ -408,10 +374,10 @@ func ShowNetworkStatus(app *tview.Application) *tview.Flex {
 			{conn.Type, fieldTextColor},
 			{conn.State, stateColor},
 			{conn.Name, fieldTextColor},
-			{conn.IPv4, fieldTextColor},
-			{conn.IPv6, fieldTextColor},
+			{strings.Join(conn.IPv4, ", "), fieldTextColor},
+			{strings.Join(conn.IPv6, ", "), fieldTextColor},
 			{conn.Gateway, fieldTextColor},
-			{conn.DNS, fieldTextColor},
+			{strings.Join(conn.DNS, ", "), fieldTextColor},
 		}
 
 		// Adiciona os dados à tabela
@@ -421,52 +387,94 @@ func ShowNetworkStatus(app *tview.Application) *tview.Flex {
 				SetAlign(tview.AlignLeft)
 			table.SetCell(row+1, col, cell)
 		}
+
+		if conn.Device == selected {
+			selectedRow = row + 1
+		}
 	}
 
-	flex.AddItem(table, 0, 1, true)
+	table.Select(selectedRow, 0)
+	table.SetOffset(rowOffset, colOffset)
+}
 
-	// Adiciona botões de ação
-	buttonsForm := tview.NewForm()
-	buttonsForm.SetBackgroundColor(backgroundColor)
+// Intervalo de consulta para backends que não avisam mudanças
+const statusPollInterval = 5 * time.Second
 
-	buttonsForm.AddButton(i18n.T("network_back"), func() {
-		app.Stop() // Retorna ao menu principal
-	})
+// Tempo de espera para agrupar rajadas de eventos em uma só atualização
+const statusDebounce = 300 * time.Millisecond
 
-	buttonsForm.AddButton(i18n.T("network_refresh"), func() {
-		// Recria a tela com dados atualizados
-		app.SetRoot(ShowNetworkStatus(app), true)
-	})
+var (
+	statusMu   sync.Mutex
+	statusStop chan struct{}
+)
 
-	// Adicionando texto de ajuda
-	helpText := tview.NewTextView()
-	helpText.SetTextAlign(tview.AlignCenter)
-	helpText.SetDynamicColors(true)
-	helpText.SetText("[yellow]Tab: Navegar • Enter: Selecionar • " + i18n.T("press_esc_return") + "[white]")
+// StopNetworkStatus interrompe a atualização automática da tela de status
+func StopNetworkStatus() {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	if statusStop != nil {
+		close(statusStop)
+		statusStop = nil
+	}
+}
 
-	// Configurando ordem de foco
-	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyTab || event.Key() == tcell.KeyRight {
-			// Se estiver na tabela, move para os botões
-			if table.HasFocus() {
-				app.SetFocus(buttonsForm)
-				return nil
-			}
-		}
-		if event.Key() == tcell.KeyBacktab || event.Key() == tcell.KeyLeft {
-			// Se estiver nos botões, volta para a tabela
-			if buttonsForm.HasFocus() {
-				app.SetFocus(table)
-				return nil
-			}
+// watchNetworkStatus recarrega a tabela de status a cada mudança avisada pelo
+// backend. Backends sem suporte a eventos são consultados periodicamente.
+func watchNetworkStatus(app *tview.Application, table *tview.Table) {
+	StopNetworkStatus()
+
+	stop := make(chan struct{})
+	statusMu.Lock()
+	statusStop = stop
+	statusMu.Unlock()
+
+	var events <-chan struct{}
+	if w, ok := backend.Default().(backend.Watcher); ok {
+		if ch, err := w.Watch(stop); err == nil {
+			events = ch
 		}
-		return event
-	})
+	}
 
-	flex.AddItem(buttonsForm, 3, 0, true) // Mudado para true para permitir foco
-	flex.AddItem(helpText, 1, 0, false)
+	go func() {
+		var ticker *time.Ticker
+		var tick <-chan time.Time
+		if events == nil {
+			ticker = time.NewTicker(statusPollInterval)
+			tick = ticker.C
+		}
+		defer func() {
+			if ticker != nil {
+				ticker.Stop()
+			}
+		}()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case _, ok := <-events:
+				if !ok {
+					// O monitor do backend terminou; passa a consultar periodicamente
+					events = nil
+					ticker = time.NewTicker(statusPollInterval)
+					tick = ticker.C
+					continue
+				}
+				time.Sleep(statusDebounce)
+			case <-tick:
+			}
 
-	return flex
+			connections, err := GetNetworkConnectionsInfo()
+			app.QueueUpdateDraw(func() {
+				select {
+				case <-stop:
+					// A tela já foi fechada
+				default:
+					fillStatusTable(table, connections, err)
+				}
+			})
+		}
+	}()
 }
 
 // Função que configura a rede a partir de uma interface TUI
@@ -498,6 +506,9 @@ func ConfigureNetwork(app *tview.Application) *tview.Flex {
 	// Adiciona a opção de selecionar a interface de rede ao formulário
 	form.AddDropDown(i18n.T("network_interface"), interfaces, 0, nil)
 
+	// Perfil de conexão do dispositivo a alterar (ou criar)
+	form.AddDropDown(i18n.T("network_profile"), nil, 0, nil)
+
 	// === Configuração IPv4 ===
 	form.AddTextView("", "=== "+i18n.T("network_ipv4_config")+" ===", 20, 1, true, false)
 
@@ -529,12 +540,13 @@ func ConfigureNetwork(app *tview.Application) *tview.Flex {
 		}
 	})
 
-	// Campos para configuração manual de IPv4 com valores padrão
-	form.AddInputField(i18n.T("network_ipv4_address"), DefaultIP, 20, nil, nil)
-	form.AddInputField(i18n.T("network_ipv4_netmask"), DefaultNetmask, 20, nil, nil)
-	form.AddInputField(i18n.T("network_ipv4_gateway"), DefaultGateway, 20, nil, nil)
-	form.AddInputField(i18n.T("network_ipv4_dns1"), DefaultDNS1, 20, nil, nil)
-	form.AddInputField(i18n.T("network_ipv4_dns2"), DefaultDNS2, 20, nil, nil)
+	// Campos para configuração manual de IPv4, preenchidos com o perfil atual
+	// da interface; os valores padrão aparecem apenas como exemplo
+	form.AddInputField(i18n.T("network_ipv4_address"), "", 20, nil, nil)
+	form.AddInputField(i18n.T("network_ipv4_netmask"), "", 20, nil, nil)
+	form.AddInputField(i18n.T("network_ipv4_gateway"), "", 20, nil, nil)
+	form.AddInputField(i18n.T("network_ipv4_dns1"), "", 20, nil, nil)
+	form.AddInputField(i18n.T("network_ipv4_dns2"), "", 20, nil, nil)
 
 	// Obtém referências aos campos de entrada IPv4
 	ipInput = form.GetFormItemByLabel(i18n.T("network_ipv4_address")).(*tview.InputField)
@@ -583,12 +595,12 @@ func ConfigureNetwork(app *tview.Application) *tview.Flex {
 		}
 	})
 
-	// Campos para configuração manual de IPv6 com valores padrão
-	form.AddInputField(i18n.T("network_ipv6_address"), DefaultIPv6, 40, nil, nil)
-	form.AddInputField(i18n.T("network_ipv6_prefix"), DefaultIPv6Prefix, 20, nil, nil)
-	form.AddInputField(i18n.T("network_ipv6_gateway"), DefaultIPv6Gateway, 40, nil, nil)
-	form.AddInputField(i18n.T("network_ipv6_dns1"), DefaultIPv6DNS1, 40, nil, nil)
-	form.AddInputField(i18n.T("network_ipv6_dns2"), DefaultIPv6DNS2, 40, nil, nil)
+	// Campos para configuração manual de IPv6, preenchidos com o perfil atual
+	form.AddInputField(i18n.T("network_ipv6_address"), "", 40, nil, nil)
+	form.AddInputField(i18n.T("network_ipv6_prefix"), "", 20, nil, nil)
+	form.AddInputField(i18n.T("network_ipv6_gateway"), "", 40, nil, nil)
+	form.AddInputField(i18n.T("network_ipv6_dns1"), "", 40, nil, nil)
+	form.AddInputField(i18n.T("network_ipv6_dns2"), "", 40, nil, nil)
 
 	// Obtém referências aos campos de entrada IPv6
 	ipv6Input = form.GetFormItemByLabel(i18n.T("network_ipv6_address")).(*tview.InputField)
@@ -604,20 +616,120 @@ func ConfigureNetwork(app *tview.Application) *tview.Flex {
 	ipv6DNS1Input.SetDisabled(true)
 	ipv6DNS2Input.SetDisabled(true)
 
-	// Verificamos se estamos no modo de desenvolvimento
-	if os.Getenv("DEV_MODE") == "true" {
+	// Valores padrão como exemplo nos campos vazios
+	ipInput.SetPlaceholder(DefaultIP)
+	netmaskInput.SetPlaceholder(DefaultNetmask)
+	gatewayInput.SetPlaceholder(DefaultGateway)
+	dns1Input.SetPlaceholder(DefaultDNS1)
+	dns2Input.SetPlaceholder(DefaultDNS2)
+	ipv6Input.SetPlaceholder(DefaultIPv6)
+	ipv6PrefixInput.SetPlaceholder(DefaultIPv6Prefix)
+	ipv6GatewayInput.SetPlaceholder(DefaultIPv6Gateway)
+	ipv6DNS1Input.SetPlaceholder(DefaultIPv6DNS1)
+	ipv6DNS2Input.SetPlaceholder(DefaultIPv6DNS2)
+
+	// Ao escolher uma interface, lista os perfis do dispositivo; ao escolher um
+	// perfil, carrega a sua configuração atual. Escolher de novo a mesma opção
+	// não descarta o que foi digitado.
+	interfaceDropDown := form.GetFormItemByLabel(i18n.T("network_interface")).(*tview.DropDown)
+	profileDropDown := form.GetFormItemByLabel(i18n.T("network_profile")).(*tview.DropDown)
+	var profileIDs []string
+	var selectedInterface, selectedProfile string
+	var ipv4Routing, ipv6Routing RoutingConfig // Editadas nas telas de opções avançadas
+	var eap EAPConfig                          // Editada na tela de autenticação 802.1X
+
+	selectProfile := func(option string, index int) {
+		if index < 0 || index >= len(profileIDs) || profileIDs[index] == selectedProfile {
+			return
+		}
+		selectedProfile = profileIDs[index]
 
+		cfg, err := CurrentNetworkConfig(selectedInterface, selectedProfile)
+		if err != nil {
+			logger.LogError("Erro ao carregar configuração de %s: %v", selectedProfile, err)
+			return
+		}
+		fillNetworkForm(form, cfg)
+		ipv4Routing, ipv6Routing = cfg.IPv4Routing, cfg.IPv6Routing
+		eap = cfg.EAP
+	}
+
+	interfaceDropDown.SetSelectedFunc(func(option string, index int) {
+		if option == selectedInterface {
+			return
+		}
+		selectedInterface = option
+		selectedProfile = ""
+
+		profiles, err := InterfaceProfiles(option)
+		if err != nil {
+			logger.LogError("Erro ao listar perfis de %s: %v", option, err)
+		}
+
+		var labels []string
+		profileIDs = nil
+		for _, p := range profiles {
+			label := p.Name
+			if p.UUID != "" {
+				label = fmt.Sprintf("%s (%s)", p.Name, p.UUID)
+			}
+			labels = append(labels, label)
+			profileIDs = append(profileIDs, p.ID())
+		}
+
+		// A última opção cria um perfil novo para o dispositivo
+		name := NewProfileName(option)
+		labels = append(labels, fmt.Sprintf(i18n.T("network_profile_new"), name))
+		profileIDs = append(profileIDs, name)
+
+		profileDropDown.SetOptions(labels, selectProfile)
+		profileDropDown.SetCurrentOption(0)
+	})
+	if current, _ := interfaceDropDown.GetCurrentOption(); current >= 0 {
+		interfaceDropDown.SetCurrentOption(current)
 	}
 
+	// === Aplicação segura ===
+	form.AddTextView("", "=== "+i18n.T("network_safe_apply")+" ===", 20, 1, true, false)
+	form.AddInputField(i18n.T("network_confirm_timeout"), strconv.Itoa(int(safeapply.DefaultTimeout.Seconds())), 10, tview.InputFieldInteger, nil)
+	form.AddInputField(i18n.T("network_check_host"), "", 40, nil, nil)
+
 	// Botões
+	// A tela de configuração, para onde a pré-visualização volta
+	var flex *tview.Flex
+
 	form.AddButton(i18n.T("network_save"), func() {
-		// Aqui implementaria a lógica para salvar as configurações
-		if err := applyNetworkSettings(form); err != nil {
-			showMessage(app, i18n.T("error_title"), err.Error())
-			return
+		cfg, err := networkConfigFromForm(form)
+		cfg.Profile = selectedProfile
+		cfg.IPv4Routing, cfg.IPv6Routing = ipv4Routing, ipv6Routing
+		cfg.EAP = eap
+		if err == nil {
+			var preview Preview
+			if preview, err = PreviewNetworkConfig(cfg); err == nil {
+				showPreview(app, preview, func() {
+					if err := applyNetworkSettings(app, form, cfg, preview); err != nil {
+						showMessage(app, i18n.T("error_title"), err.Error())
+					}
+				}, func() {
+					app.SetRoot(flex, true).SetFocus(form)
+				})
+				return
+			}
 		}
-		showMessage(app, i18n.T("success_title"), i18n.T("success_message"))
+		showMessage(app, i18n.T("error_title"), err.Error())
+	})
 
+	backToForm := func() {
+		app.SetRoot(flex, true).SetFocus(form)
+	}
+	form.AddButton(i18n.T("network_ipv4_advanced"), func() {
+		showRoutingForm(app, i18n.T("network_ipv4_advanced"), &ipv4Routing, backToForm)
+	})
+	form.AddButton(i18n.T("network_ipv6_advanced"), func() {
+		showRoutingForm(app, i18n.T("network_ipv6_advanced"), &ipv6Routing, backToForm)
+	})
+	form.AddButton(i18n.T("network_8021x"), func() {
+		showEAPForm(app, i18n.T("network_8021x"), &eap, true, backToForm, backToForm)
 	})
 
 	form.AddButton(i18n.T("network_cancel"), func() {
@@ -632,7 +744,7 @@ func ConfigureNetwork(app *tview.Application) *tview.Flex {
 	helpText.SetText("[yellow]" + i18n.T("press_esc_return") + "[white]")
 
 	// Criando um flex para adicionar o texto de ajuda abaixo do formulário
-	flex := tview.NewFlex().
+	flex = tview.NewFlex().
 		SetDirection(tview.FlexRow).
 		AddItem(form, 0, 1, true).
 		AddItem(helpText, 1, 0, false)
@@ -640,120 +752,826 @@ func ConfigureNetwork(app *tview.Application) *tview.Flex {
 	return flex
 }
 
+// Mostra o editor das opções avançadas de uma família de endereços: endereços
+// adicionais, rotas estáticas, regras de roteamento, domínios de busca DNS,
+// métrica e never-default. As listas têm um item por linha; a validação
+// acontece ao salvar a configuração.
+func showRoutingForm(app *tview.Application, title string, rc *RoutingConfig, back func()) {
+	form := newForm(title)
+
+	form.AddTextArea(i18n.T("network_extra_addresses"), strings.Join(rc.Addresses, "\n"), 50, 3, 0, nil)
+	form.AddTextArea(i18n.T("network_routes"), strings.Join(rc.Routes, "\n"), 50, 4, 0, nil)
+	form.AddTextArea(i18n.T("network_routing_rules"), strings.Join(rc.RoutingRules, "\n"), 50, 3, 0, nil)
+	form.AddInputField(i18n.T("network_dns_search"), strings.Join(rc.DNSSearch, ","), 50, nil, nil)
+	form.AddInputField(i18n.T("network_route_metric"), rc.RouteMetric, 10, tview.InputFieldInteger, nil)
+	form.AddCheckbox(i18n.T("network_never_default"), rc.NeverDefault == "yes", nil)
+
+	form.AddButton("OK", func() {
+		lines := func(label string) []string {
+			text := form.GetFormItemByLabel(i18n.T(label)).(*tview.TextArea).GetText()
+			return splitLines(text)
+		}
+		rc.Addresses = lines("network_extra_addresses")
+		rc.Routes = lines("network_routes")
+		rc.RoutingRules = lines("network_routing_rules")
+		rc.DNSSearch = splitValues(form.GetFormItemByLabel(i18n.T("network_dns_search")).(*tview.InputField).GetText())
+		rc.RouteMetric = strings.TrimSpace(form.GetFormItemByLabel(i18n.T("network_route_metric")).(*tview.InputField).GetText())
+		rc.NeverDefault = "no"
+		if form.GetFormItemByLabel(i18n.T("network_never_default")).(*tview.Checkbox).IsChecked() {
+			rc.NeverDefault = "yes"
+		}
+		back()
+	})
+	form.AddButton(i18n.T("network_cancel"), back)
+
+	helpText := tview.NewTextView()
+	helpText.SetDynamicColors(true)
+	helpText.SetText("[yellow]" + i18n.T("network_routing_help") + "[white]")
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(form, 0, 1, true).
+		AddItem(helpText, 3, 0, false)
+
+	app.SetRoot(flex, true).SetFocus(form)
+}
+
+// newForm cria um formulário com as cores das telas de configuração
+func newForm(title string) *tview.Form {
+	form := tview.NewForm()
+	form.SetBorder(true).
+		SetTitle(" " + title + " ").
+		SetTitleAlign(tview.AlignCenter).
+		SetTitleColor(titleColor).
+		SetBorderColor(borderColor).
+		SetBackgroundColor(backgroundColor).
+		SetBorderPadding(1, 1, 2, 2)
+	form.SetFieldBackgroundColor(fieldBgColor)
+	form.SetFieldTextColor(fieldTextColor)
+	form.SetLabelColor(labelColor)
+	form.SetButtonBackgroundColor(buttonBgColor)
+	form.SetButtonTextColor(buttonTextColor)
+	return form
+}
+
+// splitLines separa um item por linha, ignorando linhas vazias
+func splitLines(text string) []string {
+	items := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			items = append(items, line)
+		}
+	}
+	return items
+}
+
+// Mostra as propriedades que mudam e os comandos que serão executados. Aplicar
+// chama apply; Voltar chama back, retornando ao formulário sem perder os dados.
+func showPreview(app *tview.Application, preview Preview, apply, back func()) {
+	textView := tview.NewTextView()
+	textView.SetDynamicColors(true)
+	textView.SetWordWrap(true)
+	textView.SetScrollable(true)
+	textView.SetBackgroundColor(backgroundColor)
+	textView.SetBorder(true).
+		SetTitle(" " + i18n.T("network_preview") + " ").
+		SetTitleAlign(tview.AlignCenter).
+		SetTitleColor(titleColor).
+		SetBorderColor(titleColor)
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "[yellow]%s[white] %s", i18n.T("network_preview_profile"), tview.Escape(preview.ProfileID))
+	if preview.Create {
+		fmt.Fprintf(&text, " [green]%s[white]", i18n.T("network_profile_created"))
+	}
+	text.WriteString("\n\n")
+	for _, change := range preview.Changes {
+		if change.Changed() {
+			fmt.Fprintf(&text, "[white]%-16s [red]%s[white] → [green]%s[white]\n",
+				change.Key, tview.Escape(orNone(change.Before)), tview.Escape(orNone(change.After)))
+		} else {
+			fmt.Fprintf(&text, "[gray]%-16s %s[white]\n", change.Key, tview.Escape(orNone(change.After)))
+		}
+	}
+
+	fmt.Fprintf(&text, "\n[yellow]%s[white]\n", i18n.T("network_preview_commands"))
+	if len(preview.Commands) == 0 {
+		fmt.Fprintf(&text, "[gray]%s[white]\n", i18n.T("network_preview_no_commands"))
+	}
+	for _, command := range preview.Commands {
+		fmt.Fprintf(&text, "[aqua]$[white] %s\n", tview.Escape(command))
+	}
+	textView.SetText(text.String())
+
+	buttons := tview.NewForm()
+	buttons.SetButtonsAlign(tview.AlignCenter)
+	buttons.SetBackgroundColor(backgroundColor)
+	buttons.AddButton(i18n.T("network_preview_apply"), apply)
+	buttons.AddButton(i18n.T("network_preview_back"), back)
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(textView, 0, 1, false).
+		AddItem(buttons, 3, 0, true)
+
+	app.SetRoot(flex, true).SetFocus(buttons)
+}
+
+// orNone representa valores vazios na pré-visualização
+func orNone(value string) string {
+	if value == "" {
+		return "--"
+	}
+	return value
+}
+
+
+// ErrInvalidConfig indica que os parâmetros de rede informados são inválidos
+var ErrInvalidConfig = errors.New("configuração inválida")
+
+// NetworkConfig descreve as configurações IP a aplicar em uma interface. Um
+// modo vazio mantém a configuração atual daquela família de endereços.
+type NetworkConfig struct {
+	Interface string
+	Profile   string // UUID ou nome do perfil; vazio usa o perfil do dispositivo. Se não existir, é criado.
+
+	IPv4Mode    string // auto ou manual
+	IPv4Address string
+	IPv4Netmask string // Prefixo CIDR (24) ou máscara (255.255.255.0)
+	IPv4Gateway string
+	IPv4DNS     []string
+	IPv4Routing RoutingConfig
+
+	IPv6Mode    string // auto, manual ou disabled
+	IPv6Address string
+	IPv6Prefix  string
+	IPv6Gateway string
+	IPv6DNS     []string
+	IPv6Routing RoutingConfig
+
+	EAP EAPConfig // Autenticação 802.1X
+}
+
+// Função para aplicar as configurações de rede baseadas nas opções selecionadas.
+// Com prazo de confirmação, usa a aplicação segura e mostra a contagem regressiva.
+// A alteração é registrada no histórico com as propriedades da pré-visualização.
+func applyNetworkSettings(app *tview.Application, form *tview.Form, cfg NetworkConfig, preview Preview) error {
+	timeoutText := form.GetFormItemByLabel(i18n.T("network_confirm_timeout")).(*tview.InputField).GetText()
+	checkHost := form.GetFormItemByLabel(i18n.T("network_check_host")).(*tview.InputField).GetText()
+	timeout, _ := strconv.Atoi(timeoutText)
+
+	// Sem prazo, aplica diretamente
+	if timeout <= 0 {
+		err := ApplyNetworkConfig(cfg)
+		RecordNetworkConfig(cfg, preview, "tui", false, err)
+		if err != nil {
+			return err
+		}
+		showMessage(app, i18n.T("success_title"), i18n.T("success_message"))
+		return nil
+	}
+
+	pending, err := ApplyNetworkConfigConfirmed(cfg, safeapply.Options{
+		Timeout:   time.Duration(timeout) * time.Second,
+		CheckHost: strings.TrimSpace(checkHost),
+	})
+	RecordNetworkConfig(cfg, preview, "tui", true, err)
+	if err != nil {
+		return err
+	}
+	showConfirmDialog(app, pending)
+	return nil
+}
+
+// RecordNetworkConfig registra no histórico a configuração aplicada na
+// interface, com o perfil e as propriedades alteradas segundo a
+// pré-visualização feita antes de aplicar. confirmed indica a aplicação
+// segura, que fica pendente até a confirmação.
+func RecordNetworkConfig(cfg NetworkConfig, preview Preview, via string, confirmed bool, err error) {
+	outcome := "sucesso"
+	if err != nil {
+		outcome = "falha: " + err.Error()
+	} else if confirmed {
+		outcome = "aguardando confirmação"
+	}
+
+	var diff []backend.Change
+	for _, change := range preview.Changes {
+		if change.Changed() {
+			diff = append(diff, change)
+		}
+	}
+	action := history.Action{
+		Action:     "network_configure",
+		Interface:  cfg.Interface,
+		Profile:    preview.ProfileID,
+		Details:    fmt.Sprintf("Interface %s (%s)", cfg.Interface, outcome),
+		Diff:       diff,
+		ModifiedBy: via,
+	}.WithResult(err)
+	// Só alterações aplicadas em perfis que já existiam podem ser desfeitas
+	if err == nil && !preview.Create {
+		action.Snapshot = history.Snapshot(preview.previous, diff)
+	}
+	if err == nil && confirmed {
+		action.Outcome = history.OutcomePending
+	}
+	history.Record(action)
+}
+
+// RecordConfirm registra no histórico a confirmação de uma alteração feita
+// com a aplicação segura, que passa a ser definitiva
+func RecordConfirm(pending *safeapply.Pending, via string) {
+	history.Record(history.Action{
+		Action:     "network_confirm",
+		Interface:  pending.Interface,
+		Profile:    pending.ProfileID,
+		Details:    fmt.Sprintf("Interface %s confirmada", pending.Interface),
+		Diff:       history.Diff(pending.Previous, pending.Applied),
+		ModifiedBy: via,
+	}.WithResult(nil))
+}
+
+// Mostra a contagem regressiva da aplicação segura. Sem confirmação dentro do
+// prazo, as configurações anteriores são restauradas pela guarda, mesmo que a
+// interface seja fechada.
+func showConfirmDialog(app *tview.Application, pending *safeapply.Pending) {
+	modal := tview.NewModal().
+		AddButtons([]string{i18n.T("safe_apply_confirm"), i18n.T("safe_apply_rollback")})
+
+	modal.SetBorder(true).
+		SetTitle(" " + i18n.T("network_safe_apply") + " ").
+		SetTitleAlign(tview.AlignCenter).
+		SetTitleColor(titleColor).
+		SetBorderColor(titleColor).
+		SetBackgroundColor(backgroundColor)
+
+	updateText := func() {
+		modal.SetText(fmt.Sprintf(i18n.T("safe_apply_countdown"),
+			pending.Interface, int(pending.Remaining().Round(time.Second).Seconds())))
+	}
+	updateText()
+
+	done := make(chan struct{})
+	var once sync.Once
+	finish := func() { once.Do(func() { close(done) }) }
+
+	modal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+		finish()
+		if buttonIndex == 0 {
+			if err := pending.Confirm(); err != nil {
+				showMessage(app, i18n.T("error_title"), err.Error())
+				return
+			}
+			RecordConfirm(pending, "tui")
+			showMessage(app, i18n.T("success_title"), i18n.T("safe_apply_confirmed"))
+			return
+		}
+		if err := pending.Rollback(); err != nil && err != safeapply.ErrRolledBack {
+			showMessage(app, i18n.T("error_title"), err.Error())
+			return
+		}
+		showMessage(app, i18n.T("success_title"), i18n.T("safe_apply_rolled_back"))
+	})
+
+	// Atualiza a contagem e detecta a restauração feita pela guarda
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			if pending.RolledBack() {
+				finish()
+				app.QueueUpdateDraw(func() {
+					showMessage(app, i18n.T("error_title"), i18n.T("safe_apply_rolled_back"))
+				})
+				return
+			}
+			app.QueueUpdateDraw(updateText)
+		}
+	}()
+
+	app.SetRoot(modal, true)
+}
+
+// fillNetworkForm preenche o formulário com a configuração informada. Os
+// modos são definidos por último, para que os campos manuais sejam habilitados
+// ou desabilitados de acordo.
+func fillNetworkForm(form *tview.Form, cfg NetworkConfig) {
+	setText := func(label, text string) {
+		form.GetFormItemByLabel(i18n.T(label)).(*tview.InputField).SetText(text)
+	}
+	dns := func(servers []string, i int) string {
+		if i < len(servers) {
+			return servers[i]
+		}
+		return ""
+	}
+
+	setText("network_ipv4_address", cfg.IPv4Address)
+	setText("network_ipv4_netmask", cfg.IPv4Netmask)
+	setText("network_ipv4_gateway", cfg.IPv4Gateway)
+	setText("network_ipv4_dns1", dns(cfg.IPv4DNS, 0))
+	setText("network_ipv4_dns2", dns(cfg.IPv4DNS, 1))
+	setText("network_ipv6_address", cfg.IPv6Address)
+	setText("network_ipv6_prefix", cfg.IPv6Prefix)
+	setText("network_ipv6_gateway", cfg.IPv6Gateway)
+	setText("network_ipv6_dns1", dns(cfg.IPv6DNS, 0))
+	setText("network_ipv6_dns2", dns(cfg.IPv6DNS, 1))
+
+	ipv4Mode := 0 // Auto
+	if cfg.IPv4Mode == "manual" {
+		ipv4Mode = 1
+	}
+	ipv6Mode := 0 // Auto
+	switch cfg.IPv6Mode {
+	case "manual":
+		ipv6Mode = 1
+	case "disabled", "ignore":
+		ipv6Mode = 2
+	}
+	form.GetFormItemByLabel(i18n.T("network_ipv4_mode")).(*tview.DropDown).SetCurrentOption(ipv4Mode)
+	form.GetFormItemByLabel(i18n.T("network_ipv6_mode")).(*tview.DropDown).SetCurrentOption(ipv6Mode)
+}
+
+// networkConfigFromForm lê a configuração informada no formulário
+func networkConfigFromForm(form *tview.Form) (NetworkConfig, error) {
+	interfaceIndex, _ := form.GetFormItemByLabel(i18n.T("network_interface")).(*tview.DropDown).GetCurrentOption()
+	interfaces, err := GetNetworkConnections()
+	if err != nil {
+		return NetworkConfig{}, fmt.Errorf("erro ao obter interfaces: %w", err)
+	}
+	if interfaceIndex >= len(interfaces) {
+		return NetworkConfig{}, fmt.Errorf("interface selecionada inválida")
+	}
+
+	cfg := NetworkConfig{Interface: interfaces[interfaceIndex]}
+
+	// Obtém os modos IPv4 e IPv6
+	ipv4Mode, _ := form.GetFormItemByLabel(i18n.T("network_ipv4_mode")).(*tview.DropDown).GetCurrentOption()
+	ipv6Mode, _ := form.GetFormItemByLabel(i18n.T("network_ipv6_mode")).(*tview.DropDown).GetCurrentOption()
+
+	// Configura IPv4
+	if ipv4Mode == 1 { // Manual
+		cfg.IPv4Mode = "manual"
+		cfg.IPv4Address = form.GetFormItemByLabel(i18n.T("network_ipv4_address")).(*tview.InputField).GetText()
+		cfg.IPv4Netmask = form.GetFormItemByLabel(i18n.T("network_ipv4_netmask")).(*tview.InputField).GetText()
+		cfg.IPv4Gateway = form.GetFormItemByLabel(i18n.T("network_ipv4_gateway")).(*tview.InputField).GetText()
+		cfg.IPv4DNS = []string{
+			form.GetFormItemByLabel(i18n.T("network_ipv4_dns1")).(*tview.InputField).GetText(),
+			form.GetFormItemByLabel(i18n.T("network_ipv4_dns2")).(*tview.InputField).GetText(),
+		}
+	} else {
+		// Modo automático (DHCP)
+		cfg.IPv4Mode = "auto"
+	}
+
+	// Configura IPv6
+	if ipv6Mode == 1 { // Manual
+		cfg.IPv6Mode = "manual"
+		cfg.IPv6Address = form.GetFormItemByLabel(i18n.T("network_ipv6_address")).(*tview.InputField).GetText()
+		cfg.IPv6Prefix = form.GetFormItemByLabel(i18n.T("network_ipv6_prefix")).(*tview.InputField).GetText()
+		cfg.IPv6Gateway = form.GetFormItemByLabel(i18n.T("network_ipv6_gateway")).(*tview.InputField).GetText()
+		cfg.IPv6DNS = []string{
+			form.GetFormItemByLabel(i18n.T("network_ipv6_dns1")).(*tview.InputField).GetText(),
+			form.GetFormItemByLabel(i18n.T("network_ipv6_dns2")).(*tview.InputField).GetText(),
+		}
+	} else if ipv6Mode == 2 { // Desabilitado
+		cfg.IPv6Mode = "disabled"
+	} else { // Automático
+		cfg.IPv6Mode = "auto"
+	}
+
+	return cfg, nil
+}
+
+// BuildNetworkSettings valida a configuração e a converte nas propriedades do
+// perfil de conexão
+func BuildNetworkSettings(cfg NetworkConfig) (backend.Settings, error) {
+	settings := backend.Settings{}
+	var primary4, primary6 string // Endereços principais do modo manual
+
+	switch cfg.IPv4Mode {
+	case "manual":
+		if !validateIPv4(cfg.IPv4Address) {
+			return nil, fmt.Errorf("%w: endereço IPv4 inválido: %s", ErrInvalidConfig, cfg.IPv4Address)
+		}
+		if !validateNetmask(cfg.IPv4Netmask) {
+			return nil, fmt.Errorf("%w: máscara de rede inválida: %s", ErrInvalidConfig, cfg.IPv4Netmask)
+		}
+		if cfg.IPv4Gateway != "" && !validateIPv4(cfg.IPv4Gateway) {
+			return nil, fmt.Errorf("%w: gateway IPv4 inválido: %s", ErrInvalidConfig, cfg.IPv4Gateway)
+		}
+
+		settings["ipv4.method"] = "manual"
+		primary4 = fmt.Sprintf("%s/%s", cfg.IPv4Address, netmaskToPrefix(cfg.IPv4Netmask))
+		settings["ipv4.gateway"] = cfg.IPv4Gateway
+		settings["ipv4.dns"] = joinNonEmpty(cfg.IPv4DNS)
+	case "auto":
+		settings["ipv4.method"] = "auto"
+	case "":
+		// Mantém a configuração IPv4 atual
+	default:
+		return nil, fmt.Errorf("%w: modo IPv4 desconhecido: %s", ErrInvalidConfig, cfg.IPv4Mode)
+	}
+	if err := addRoutingSettings(settings, "ipv4", cfg.IPv4Routing, primary4); err != nil {
+		return nil, err
+	}
+
+	switch cfg.IPv6Mode {
+	case "manual":
+		if !validateIPv6(cfg.IPv6Address) {
+			return nil, fmt.Errorf("%w: endereço IPv6 inválido: %s", ErrInvalidConfig, cfg.IPv6Address)
+		}
+		if !validateIPv6Prefix(cfg.IPv6Prefix) {
+			return nil, fmt.Errorf("%w: prefixo IPv6 inválido: %s", ErrInvalidConfig, cfg.IPv6Prefix)
+		}
+		if cfg.IPv6Gateway != "" && !validateIPv6(cfg.IPv6Gateway) {
+			return nil, fmt.Errorf("%w: gateway IPv6 inválido: %s", ErrInvalidConfig, cfg.IPv6Gateway)
+		}
+
+		settings["ipv6.method"] = "manual"
+		primary6 = fmt.Sprintf("%s/%s", cfg.IPv6Address, cfg.IPv6Prefix)
+		settings["ipv6.gateway"] = cfg.IPv6Gateway
+		settings["ipv6.dns"] = joinNonEmpty(cfg.IPv6DNS)
+	case "disabled":
+		// Limpa todas as configurações IPv6 antes de desabilitar
+		settings["ipv6.method"] = "disabled"
+		settings["ipv6.addresses"] = ""
+		settings["ipv6.gateway"] = ""
+		settings["ipv6.dns"] = ""
+		settings["ipv6.routes"] = ""
+		settings["ipv6.routing-rules"] = ""
+		settings["ipv6.dns-search"] = ""
+	case "auto":
+		settings["ipv6.method"] = "auto"
+	case "":
+		// Mantém a configuração IPv6 atual
+	default:
+		return nil, fmt.Errorf("%w: modo IPv6 desconhecido: %s", ErrInvalidConfig, cfg.IPv6Mode)
+	}
+	if cfg.IPv6Mode != "disabled" {
+		if err := addRoutingSettings(settings, "ipv6", cfg.IPv6Routing, primary6); err != nil {
+			return nil, err
+		}
+	}
+
+	eap, err := cfg.EAP.Settings()
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range eap {
+		settings[key] = value
+	}
+
+	if len(settings) == 0 {
+		return nil, fmt.Errorf("%w: nenhuma alteração informada", ErrInvalidConfig)
+	}
+	return settings, nil
+}
+
+// ApplyNetworkConfig valida e aplica a configuração na interface, reativando a
+// conexão em seguida. É usada tanto pelo formulário quanto pela linha de comando.
+func ApplyNetworkConfig(cfg NetworkConfig) error {
+	settings, err := BuildNetworkSettings(cfg)
+	if err != nil {
+		return err
+	}
+
+	b := backend.Default()
+	profileID, create, err := profileForConfig(b, cfg)
+	if err != nil {
+		return err
+	}
+	if create {
+		if profileID, err = createProfile(b, cfg.Interface, profileID); err != nil {
+			return err
+		}
+	} else if err := keepExtraAddresses(b, profileID, cfg, settings); err != nil {
+		return err
+	}
+
+	err = b.ModifyProfile(profileID, settings)
+	if err != nil {
+		err = fmt.Errorf("erro ao configurar a conexão: %w", err)
+	} else if err = b.Activate(profileID); err != nil {
+		// Reativa a conexão para aplicar todas as mudanças
+		err = fmt.Errorf("erro ao reativar conexão: %w", err)
+	}
+	if err != nil {
+		if create {
+			// Não deixa para trás um perfil criado só para esta alteração
+			b.DeleteProfile(profileID)
+		}
+		return err
+	}
+
+	// Verifica se a interface está ativa
+	if _, err := GetActiveConnection(); err != nil {
+		return fmt.Errorf("erro ao verificar status da conexão após ativação: %w", err)
+	}
+
+	return nil
+}
+
+// ApplyNetworkConfigConfirmed aplica a configuração no modo de aplicação
+// segura: as configurações anteriores voltam sozinhas se a alteração não for
+// confirmada com Pending.Confirm dentro do prazo ou se o teste de alcance falhar
+func ApplyNetworkConfigConfirmed(cfg NetworkConfig, opts safeapply.Options) (*safeapply.Pending, error) {
+	settings, err := BuildNetworkSettings(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if opts.CheckHost == "" {
+		opts.CheckHost = DefaultCheckHost(cfg)
+	}
 
-// Função para aplicar as configurações de rede baseadas nas opções selecionadas
-func applyNetworkSettings(form *tview.Form) error {
-    interfaceIndex, _ := form.GetFormItemByLabel(i18n.T("network_interface")).(*tview.DropDown).GetCurrentOption()
-    interfaces, err := GetNetworkConnections()
-    if err != nil {
-        return fmt.Errorf("erro ao obter interfaces: %w", err)
-    }
-    if interfaceIndex >= len(interfaces) {
-        return fmt.Errorf("interface selecionada inválida")
-    }
-    interfaceName := interfaces[interfaceIndex]
-
-    // Obtém os modos IPv4 e IPv6
-    ipv4Mode, _ := form.GetFormItemByLabel(i18n.T("network_ipv4_mode")).(*tview.DropDown).GetCurrentOption()
-    ipv6Mode, _ := form.GetFormItemByLabel(i18n.T("network_ipv6_mode")).(*tview.DropDown).GetCurrentOption()
-
-    // Configura IPv4
-    if ipv4Mode == 1 { // Manual
-        ip := form.GetFormItemByLabel(i18n.T("network_ipv4_address")).(*tview.InputField).GetText()
-        netmask := form.GetFormItemByLabel(i18n.T("network_ipv4_netmask")).(*tview.InputField).GetText()
-        gateway := form.GetFormItemByLabel(i18n.T("network_ipv4_gateway")).(*tview.InputField).GetText()
-        dns1 := form.GetFormItemByLabel(i18n.T("network_ipv4_dns1")).(*tview.InputField).GetText()
-        dns2 := form.GetFormItemByLabel(i18n.T("network_ipv4_dns2")).(*tview.InputField).GetText()
-
-        if !validateIPv4(ip) {
-            return fmt.Errorf("endereço IPv4 inválido: %s", ip)
-        }
-
-        if !validateNetmask(netmask) {
-            return fmt.Errorf("máscara de rede inválida: %s", netmask)
-        }
-
-        cmd := exec.Command("nmcli", "connection", "modify", interfaceName,
-            "ipv4.method", "manual",
-            "ipv4.addresses", fmt.Sprintf("%s/%s", ip, netmask),
-            "ipv4.gateway", gateway,
-            "ipv4.dns", fmt.Sprintf("%s,%s", dns1, dns2),
-            "ipv4.netmask", netmask)
-
-        if err := cmd.Run(); err != nil {
-            return fmt.Errorf("erro ao configurar IPv4: %w", err)
-        }
-    } else {
-        // Modo automático (DHCP)
-        cmd := exec.Command("nmcli", "connection", "modify", interfaceName, "ipv4.method", "auto")
-        if err := cmd.Run(); err != nil {
-            return fmt.Errorf("erro ao configurar DHCP IPv4: %w", err)
-        }
-    }
-
-    // Configura IPv6
-    if ipv6Mode == 1 { // Manual
-        ipv6 := form.GetFormItemByLabel(i18n.T("network_ipv6_address")).(*tview.InputField).GetText()
-        prefix := form.GetFormItemByLabel(i18n.T("network_ipv6_prefix")).(*tview.InputField).GetText()
-        gateway6 := form.GetFormItemByLabel(i18n.T("network_ipv6_gateway")).(*tview.InputField).GetText()
-        dns61 := form.GetFormItemByLabel(i18n.T("network_ipv6_dns1")).(*tview.InputField).GetText()
-        dns62 := form.GetFormItemByLabel(i18n.T("network_ipv6_dns2")).(*tview.InputField).GetText()
-
-        if !validateIPv6(ipv6) {
-            return fmt.Errorf("endereço IPv6 inválido: %s", ipv6)
-        }
-
-        if !validateIPv6Prefix(prefix) {
-            return fmt.Errorf("prefixo IPv6 inválido: %s", prefix)
-        }
-
-        cmd := exec.Command("nmcli", "connection", "modify", interfaceName,
-            "ipv6.method", "manual",
-            "ipv6.addresses", fmt.Sprintf("%s/%s", ipv6, prefix),
-            "ipv6.gateway", gateway6,
-            "ipv6.dns", fmt.Sprintf("%s,%s", dns61, dns62))
-
-        if err := cmd.Run(); err != nil {
-            return fmt.Errorf("erro ao configurar IPv6: %w", err)
-        }
-    } else if ipv6Mode == 2 { // Desabilitado
-        // Primeiro, limpa todas as configurações IPv6
-        cleanCmd := exec.Command("nmcli", "connection", "modify", interfaceName,
-            "ipv6.addresses", "",
-            "ipv6.gateway", "",
-            "ipv6.dns", "")
-        
-        if output, err := cleanCmd.CombinedOutput(); err != nil {
-            return fmt.Errorf("erro ao limpar configurações IPv6: %v (saída: %s)", err, string(output))
-        }
-        
-        // Depois, desabilita o IPv6
-        disableCmd := exec.Command("nmcli", "connection", "modify", interfaceName,
-            "ipv6.method", "disabled")
-            
-        if output, err := disableCmd.CombinedOutput(); err != nil {
-            if exitErr, ok := err.(*exec.ExitError); ok {
-                return fmt.Errorf("erro ao desabilitar IPv6 (código %d): %s", exitErr.ExitCode(), string(output))
-            }
-            return fmt.Errorf("erro ao desabilitar IPv6: %v (saída: %s)", err, string(output))
-        }
-    } else { // Automático
-        cmd := exec.Command("nmcli", "connection", "modify", interfaceName, "ipv6.method", "auto")
-        if err := cmd.Run(); err != nil {
-            return fmt.Errorf("erro ao configurar DHCP IPv6: %w", err)
-        }
-    }
-
-    // Reativa a conexão para aplicar todas as mudanças
-    activateCmd := exec.Command("nmcli", "connection", "up", interfaceName)
-    if output, err := activateCmd.CombinedOutput(); err != nil {
-        return fmt.Errorf("erro ao reativar conexão: %v (saída: %s)", err, string(output))
-    }
-
-    // Verifica se a interface está ativa
-    if _, err := GetActiveConnection(); err != nil {
-        return fmt.Errorf("erro ao verificar status da conexão após ativação: %w", err)
-    }
-
-    return nil
-}
\ No newline at end of file
+	b := backend.Default()
+	profileID, create, err := profileForConfig(b, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if create {
+		if profileID, err = createProfile(b, cfg.Interface, profileID); err != nil {
+			return nil, err
+		}
+		opts.Created = true
+	} else if err := keepExtraAddresses(b, profileID, cfg, settings); err != nil {
+		return nil, err
+	}
+	return safeapply.Apply(b, profileID, cfg.Interface, settings, opts)
+}
+
+// CurrentNetworkConfig lê a configuração IP atual do perfil (vazio: o perfil
+// do dispositivo). Só o primeiro endereço de cada família é considerado. Um
+// perfil que ainda não existe resulta na configuração automática.
+func CurrentNetworkConfig(iface, profile string) (NetworkConfig, error) {
+	b := backend.Default()
+	profileID, create, err := profileForConfig(b, NetworkConfig{Interface: iface, Profile: profile})
+	if err != nil {
+		return NetworkConfig{}, err
+	}
+	if create {
+		empty := routingConfigFromSettings(backend.Settings{}, "")
+		return NetworkConfig{Interface: iface, Profile: profileID, IPv4Mode: "auto", IPv6Mode: "auto",
+			IPv4Routing: empty, IPv6Routing: empty}, nil
+	}
+	current, err := b.Profile(profileID)
+	if err != nil {
+		return NetworkConfig{}, fmt.Errorf("perfil %s: %w", profileID, err)
+	}
+
+	settings := current.Settings
+	cfg := NetworkConfig{
+		Interface:   iface,
+		Profile:     current.ID(),
+		IPv4Mode:    settings["ipv4.method"],
+		IPv4Gateway: settings["ipv4.gateway"],
+		IPv4DNS:     splitValues(settings["ipv4.dns"]),
+		IPv6Mode:    settings["ipv6.method"],
+		IPv6Gateway: settings["ipv6.gateway"],
+		IPv6DNS:     splitValues(settings["ipv6.dns"]),
+		IPv4Routing: routingConfigFromSettings(settings, "ipv4"),
+		IPv6Routing: routingConfigFromSettings(settings, "ipv6"),
+		EAP:         eapConfigFromSettings(settings),
+	}
+	if addresses := splitValues(settings["ipv4.addresses"]); len(addresses) > 0 {
+		cfg.IPv4Address, cfg.IPv4Netmask, _ = strings.Cut(addresses[0], "/")
+	}
+	if addresses := splitValues(settings["ipv6.addresses"]); len(addresses) > 0 {
+		cfg.IPv6Address, cfg.IPv6Prefix, _ = strings.Cut(addresses[0], "/")
+	}
+	return cfg, nil
+}
+
+// splitValues separa listas de propriedades, que o nmcli separa por vírgula e
+// outros backends por espaço
+func splitValues(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == ';'
+	})
+}
+
+// Propriedades sempre exibidas na pré-visualização, mesmo sem alteração
+var previewKeys = []string{
+	"ipv4.method", "ipv4.addresses", "ipv4.gateway", "ipv4.dns",
+	"ipv6.method", "ipv6.addresses", "ipv6.gateway", "ipv6.dns",
+}
+
+// Preview descreve o efeito de uma configuração antes de aplicá-la
+type Preview struct {
+	ProfileID string           `json:"profile" yaml:"profile"`
+	Create    bool             `json:"create" yaml:"create"` // O perfil será criado
+	Changes   []backend.Change `json:"changes" yaml:"changes"`
+	Commands  []string         `json:"commands" yaml:"commands"`
+
+	previous backend.Settings // Valores atuais, sem máscara, guardados no histórico
+}
+
+// PreviewNetworkConfig valida a configuração e compara cada propriedade com o
+// perfil atual, sem alterar nada. Os comandos só são listados quando o backend
+// sabe descrevê-los (backend.Planner).
+func PreviewNetworkConfig(cfg NetworkConfig) (Preview, error) {
+	settings, err := BuildNetworkSettings(cfg)
+	if err != nil {
+		return Preview{}, err
+	}
+
+	b := backend.Default()
+	profileID, create, err := profileForConfig(b, cfg)
+	if err != nil {
+		return Preview{}, err
+	}
+
+	current := backend.Profile{Name: profileID, Settings: backend.Settings{}}
+	if !create {
+		if current, err = b.Profile(profileID); err != nil {
+			return Preview{}, fmt.Errorf("perfil %s: %w", profileID, err)
+		}
+		if err := keepExtraAddresses(b, profileID, cfg, settings); err != nil {
+			return Preview{}, err
+		}
+	}
+
+	// Propriedades não alteradas mantêm o valor atual
+	after := settings.Clone()
+	for _, key := range previewKeys {
+		if _, ok := after[key]; !ok {
+			after[key] = current.Settings[key]
+		}
+	}
+
+	preview := Preview{
+		ProfileID: current.ID(),
+		Create:    create,
+		Changes:   backend.DiffSettings(current.Settings.Masked(), after.Masked()),
+		Commands:  []string{},
+		previous:  current.Settings,
+	}
+	if planner, ok := b.(backend.Planner); ok {
+		if create {
+			newProfile, err := profileToCreate(b, cfg.Interface, profileID)
+			if err != nil {
+				return Preview{}, err
+			}
+			preview.Commands = append(preview.Commands, planner.PlanAdd(newProfile)...)
+		}
+		preview.Commands = append(preview.Commands, planner.Plan(current.ID(), settings)...)
+	}
+	return preview, nil
+}
+
+// DefaultCheckHost retorna o host usado no teste de alcance quando nenhum é
+// informado: o gateway configurado manualmente, se houver
+func DefaultCheckHost(cfg NetworkConfig) string {
+	if cfg.IPv4Mode == "manual" && cfg.IPv4Gateway != "" {
+		return cfg.IPv4Gateway
+	}
+	if cfg.IPv6Mode == "manual" && cfg.IPv6Gateway != "" {
+		return cfg.IPv6Gateway
+	}
+	return ""
+}
+
+// InterfaceProfiles lista os perfis que podem ser usados na interface, com o
+// perfil ativo primeiro
+func InterfaceProfiles(iface string) ([]backend.Profile, error) {
+	b := backend.Default()
+	dev, err := findDevice(b, iface)
+	if err != nil {
+		return nil, err
+	}
+	return backend.ProfilesForDevice(b, dev)
+}
+
+// NewProfileName sugere um nome ainda não usado para um novo perfil da
+// interface: o próprio nome da interface ou, se já existir, com um sufixo
+func NewProfileName(iface string) string {
+	b := backend.Default()
+	name := iface
+	for i := 2; ; i++ {
+		if _, err := b.Profile(name); err != nil {
+			return name
+		}
+		name = fmt.Sprintf("%s-%d", iface, i)
+	}
+}
+
+// profileForConfig retorna o perfil a alterar: o informado em cfg.Profile ou o
+// primeiro perfil do dispositivo. create indica que o perfil ainda não existe
+// e deve ser criado com o nome retornado.
+func profileForConfig(b backend.Backend, cfg NetworkConfig) (id string, create bool, err error) {
+	if cfg.Profile != "" {
+		_, err := b.Profile(cfg.Profile)
+		switch {
+		case err == nil:
+			return cfg.Profile, false, nil
+		case errors.Is(err, backend.ErrNotFound):
+			return cfg.Profile, true, nil
+		default:
+			return "", false, fmt.Errorf("perfil %s: %w", cfg.Profile, err)
+		}
+	}
+
+	dev, err := findDevice(b, cfg.Interface)
+	if err != nil {
+		return "", false, err
+	}
+	profiles, err := backend.ProfilesForDevice(b, dev)
+	if err != nil {
+		return "", false, fmt.Errorf("erro ao listar perfis de %s: %w", cfg.Interface, err)
+	}
+	if len(profiles) > 0 {
+		return profiles[0].ID(), false, nil
+	}
+	return NewProfileName(cfg.Interface), true, nil
+}
+
+// profileToCreate descreve o perfil criado para a interface quando ela não
+// tem nenhum
+func profileToCreate(b backend.Backend, iface, name string) (backend.Profile, error) {
+	dev, err := findDevice(b, iface)
+	if err != nil {
+		return backend.Profile{}, err
+	}
+	return backend.Profile{
+		Name:     name,
+		Type:     backend.ProfileType(dev.Type),
+		Device:   dev.Name,
+		Settings: backend.Settings{},
+	}, nil
+}
+
+// createProfile cria o perfil da interface e retorna o seu identificador
+func createProfile(b backend.Backend, iface, name string) (string, error) {
+	p, err := profileToCreate(b, iface, name)
+	if err != nil {
+		return "", err
+	}
+	created, err := b.AddProfile(p)
+	if err != nil {
+		return "", err
+	}
+	logger.LogInfo("Perfil %s criado para %s", created.Name, iface)
+	return created.ID(), nil
+}
+
+// findDevice procura o dispositivo pelo nome
+func findDevice(b backend.Backend, iface string) (backend.Device, error) {
+	devices, err := b.Devices()
+	if err != nil {
+		return backend.Device{}, fmt.Errorf("erro ao listar dispositivos: %w", err)
+	}
+	for _, dev := range devices {
+		if dev.Name == iface {
+			return dev, nil
+		}
+	}
+	return backend.Device{}, fmt.Errorf("interface %s: %w", iface, backend.ErrNotFound)
+}
+
+// netmaskToPrefix converte uma máscara no formato xxx.xxx.xxx.xxx para o
+// prefixo CIDR; prefixos já numéricos são retornados sem alteração
+func netmaskToPrefix(netmask string) string {
+	if !strings.Contains(netmask, ".") {
+		return netmask
+	}
+	mask := net.ParseIP(netmask).To4()
+	if mask == nil {
+		return netmask
+	}
+	ones, bits := net.IPv4Mask(mask[0], mask[1], mask[2], mask[3]).Size()
+	if bits == 0 {
+		// Máscara não contígua, deixa a validação do backend recusar
+		return netmask
+	}
+	return strconv.Itoa(ones)
+}
+
+// joinNonEmpty junta os valores preenchidos separados por vírgula
+func joinNonEmpty(values []string) string {
+	var filled []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			filled = append(filled, v)
+		}
+	}
+	return strings.Join(filled, ",")
+}
+
+// Ping testa a conectividade com o host informado e retorna a saída do comando
+func Ping(target string, count int) (string, error) {
+	if count <= 0 {
+		count = 4
+	}
+	args := []string{"-c", strconv.Itoa(count), target}
+	start := time.Now()
+	out, err := exec.Command("ping", args...).CombinedOutput()
+	logger.Command("ping", args, time.Since(start), err)
+	return string(out), err
+}
@@ -0,0 +1,513 @@
+package network
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"networkmanager-tui/backend"
+	"networkmanager-tui/history"
+	"networkmanager-tui/i18n"
+	"networkmanager-tui/logger"
+)
+
+// Limites de connection.autoconnect-priority aceitos pelo NetworkManager
+const (
+	MinAutoconnectPriority = -999
+	MaxAutoconnectPriority = 999
+)
+
+// SavedProfile resume um perfil salvo para o gerenciador de perfis
+type SavedProfile struct {
+	Name        string    `json:"name" yaml:"name"`
+	UUID        string    `json:"uuid" yaml:"uuid"`
+	Type        string    `json:"type" yaml:"type"`
+	Device      string    `json:"device" yaml:"device"`
+	Active      bool      `json:"active" yaml:"active"`
+	Autoconnect bool      `json:"autoconnect" yaml:"autoconnect"`
+	Priority    int       `json:"priority" yaml:"priority"`   // connection.autoconnect-priority
+	LastUsed    time.Time `json:"last_used" yaml:"last_used"` // Zero se nunca foi ativado
+}
+
+// SavedProfiles lista todos os perfis salvos, ativos ou não: primeiro os
+// ativos, depois do uso mais recente para o mais antigo
+func SavedProfiles() ([]SavedProfile, error) {
+	b := backend.Default()
+	profiles, err := b.Profiles()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar perfis: %w", err)
+	}
+
+	saved := []SavedProfile{}
+	for _, p := range profiles {
+		// A lista não traz as propriedades da conexão automática nem o último uso
+		full, err := b.Profile(p.ID())
+		if err != nil {
+			logger.LogError("Erro ao ler perfil %s: %v", p.Name, err)
+			full = p
+		}
+		device := p.Device
+		if device == "" {
+			device = full.Settings["connection.interface-name"]
+		}
+		saved = append(saved, SavedProfile{
+			Name:        p.Name,
+			UUID:        p.UUID,
+			Type:        p.Type,
+			Device:      device,
+			Active:      p.Active,
+			Autoconnect: full.Autoconnect(),
+			Priority:    full.Priority(),
+			LastUsed:    full.LastUsed(),
+		})
+	}
+
+	sort.SliceStable(saved, func(i, j int) bool {
+		if saved[i].Active != saved[j].Active {
+			return saved[i].Active
+		}
+		return saved[i].LastUsed.After(saved[j].LastUsed)
+	})
+	return saved, nil
+}
+
+// checkNewProfileName valida o nome de um perfil criado ou renomeado, que não
+// pode repetir o de outro perfil
+func checkNewProfileName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%w: o nome do perfil é obrigatório", ErrInvalidConfig)
+	}
+	if _, err := backend.Default().Profile(name); err == nil {
+		return fmt.Errorf("%w: já existe um perfil com o nome %s", ErrInvalidConfig, name)
+	}
+	return nil
+}
+
+// CloneProfile copia o perfil, com os segredos, para um novo perfil com
+// outro nome. A cópia não é ativada.
+func CloneProfile(id, name string) (backend.Profile, error) {
+	if err := checkNewProfileName(name); err != nil {
+		return backend.Profile{}, err
+	}
+	p, err := backend.CloneProfile(backend.Default(), id, name)
+	if err != nil {
+		return backend.Profile{}, err
+	}
+	logger.LogInfo("Perfil %s copiado para %s", id, name)
+	return p, nil
+}
+
+// RenameProfile altera o nome (connection.id) do perfil
+func RenameProfile(id, name string) error {
+	if err := checkNewProfileName(name); err != nil {
+		return err
+	}
+	if err := backend.Default().ModifyProfile(id, backend.Settings{"connection.id": name}); err != nil {
+		return err
+	}
+	logger.LogInfo("Perfil %s renomeado para %s", id, name)
+	return nil
+}
+
+// DeleteProfile remove o perfil; se ele estiver ativo, a conexão é encerrada
+func DeleteProfile(id string) error {
+	if err := backend.Default().DeleteProfile(id); err != nil {
+		return err
+	}
+	logger.LogInfo("Perfil %s removido", id)
+	return nil
+}
+
+// SetProfileAutoconnect liga ou desliga a ativação automática do perfil
+func SetProfileAutoconnect(id string, on bool) error {
+	value := "no"
+	if on {
+		value = "yes"
+	}
+	if err := backend.Default().ModifyProfile(id, backend.Settings{"connection.autoconnect": value}); err != nil {
+		return err
+	}
+	logger.LogInfo("Conexão automática do perfil %s: %s", id, value)
+	return nil
+}
+
+// SetProfilePriority altera a prioridade da ativação automática do perfil
+func SetProfilePriority(id string, priority int) error {
+	if priority < MinAutoconnectPriority || priority > MaxAutoconnectPriority {
+		return fmt.Errorf("%w: prioridade deve estar entre %d e %d", ErrInvalidConfig,
+			MinAutoconnectPriority, MaxAutoconnectPriority)
+	}
+	settings := backend.Settings{"connection.autoconnect-priority": strconv.Itoa(priority)}
+	if err := backend.Default().ModifyProfile(id, settings); err != nil {
+		return err
+	}
+	logger.LogInfo("Prioridade do perfil %s: %d", id, priority)
+	return nil
+}
+
+// verifySecrets confere, lendo o perfil com os segredos, se as senhas e
+// chaves de settings foram gravadas nele
+func verifySecrets(b backend.Backend, id string, settings backend.Settings) error {
+	var keys []string
+	for _, key := range settings.Keys() {
+		if settings[key] != "" && backend.IsSecret(key) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	p, err := backend.ProfileWithSecrets(b, id)
+	if err != nil {
+		return fmt.Errorf("erro ao conferir os segredos: %w", err)
+	}
+	for _, key := range keys {
+		if p.Settings[key] != settings[key] {
+			return fmt.Errorf("o segredo %s não foi gravado no perfil", key)
+		}
+	}
+	return nil
+}
+
+// RecordProfileAction registra no histórico uma ação sobre o perfil (renomear,
+// remover, conexão automática e prioridade), com as propriedades de before e
+// as lidas do backend depois da ação. Um perfil removido aparece com todas as
+// propriedades apagadas. Ações bem-sucedidas guardam os valores anteriores,
+// para que possam ser desfeitas; a remoção guarda o perfil inteiro, que é
+// criado de novo ao desfazer.
+func RecordProfileAction(action string, before backend.Profile, details, via string, err error) {
+	settings := before.Settings
+	if action == actionProfileDelete {
+		settings = deletedSettings(before)
+	}
+	after := backend.Settings{}
+	if p, perr := backend.Default().Profile(before.ID()); perr == nil {
+		after = p.Settings
+	}
+	diff := history.Diff(settings, after)
+	record := history.Action{
+		Action:     action,
+		Interface:  before.Device,
+		Profile:    before.ID(),
+		Details:    fmt.Sprintf("%s (%s)", details, outcomeText(err)),
+		Diff:       diff,
+		ModifiedBy: via,
+	}.WithResult(err)
+	if err == nil {
+		record.Snapshot = history.Snapshot(settings, diff)
+	}
+	history.Record(record)
+}
+
+// RecordProfileClone registra no histórico a cópia do perfil source, com as
+// propriedades que diferem na cópia
+func RecordProfileClone(source, clone backend.Profile, name, via string, err error) {
+	action := history.Action{
+		Action:     "profile_clone",
+		Interface:  source.Device,
+		Profile:    name,
+		Details:    fmt.Sprintf("Perfil %s copiado para %s (%s)", source.Name, name, outcomeText(err)),
+		ModifiedBy: via,
+	}.WithResult(err)
+	if err == nil {
+		action.Profile = clone.ID()
+		action.Diff = history.Diff(source.Settings, clone.Settings)
+	}
+	history.Record(action)
+}
+
+// profileBefore lê o perfil antes de uma ação da tela, para o histórico
+func profileBefore(p SavedProfile) backend.Profile {
+	if current, err := backend.Default().Profile(p.UUID); err == nil {
+		return current
+	}
+	return backend.Profile{UUID: p.UUID, Name: p.Name, Type: p.Type, Device: p.Device}
+}
+
+// LastUsedText descreve a última ativação do perfil
+func LastUsedText(t time.Time) string {
+	if t.IsZero() {
+		return i18n.T("profiles_never")
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+// ShowProfiles mostra todos os perfis salvos, com tipo, dispositivo, conexão
+// automática, prioridade e último uso, e as ações de copiar, renomear,
+// remover, ligar ou desligar a conexão automática e alterar a prioridade
+func ShowProfiles(app *tview.Application) {
+	table := tview.NewTable()
+	table.SetBorders(true)
+	table.SetBorderColor(borderColor)
+	table.SetTitle(" 🗂️ " + i18n.T("profiles_title") + " 🗂️ ")
+	table.SetTitleColor(titleColor)
+	table.SetTitleAlign(tview.AlignCenter)
+	table.SetSelectable(true, false)
+	table.SetFixed(1, 0)
+	table.SetBackgroundColor(backgroundColor)
+
+	headers := []string{
+		i18n.T("network_name"),
+		i18n.T("network_type"),
+		i18n.T("network_device"),
+		i18n.T("network_state"),
+		i18n.T("profiles_autoconnect"),
+		i18n.T("profiles_priority"),
+		i18n.T("profiles_last_used"),
+	}
+	for col, header := range headers {
+		table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(headerColor).
+			SetAlign(tview.AlignCenter).
+			SetSelectable(false))
+	}
+
+	var profiles []SavedProfile
+	refresh := func() {
+		var err error
+		profiles, err = SavedProfiles()
+		fillProfilesTable(table, profiles, err)
+	}
+	refresh()
+
+	selected := func() (SavedProfile, bool) {
+		row, _ := table.GetSelection()
+		if row < 1 || row > len(profiles) {
+			return SavedProfile{}, false
+		}
+		return profiles[row-1], true
+	}
+
+	var flex *tview.Flex
+	back := func() {
+		refresh()
+		app.SetRoot(flex, true).SetFocus(table)
+	}
+
+	// done volta à lista depois de a ação ser registrada no histórico; erros
+	// encerram a tela com a mensagem, como nas demais telas
+	done := func(err error) {
+		if err != nil {
+			showMessage(app, i18n.T("error_title"), err.Error())
+			return
+		}
+		back()
+	}
+
+	buttons := tview.NewForm()
+	buttons.SetBackgroundColor(backgroundColor)
+	buttons.SetButtonBackgroundColor(buttonBgColor)
+	buttons.SetButtonTextColor(buttonTextColor)
+
+	buttons.AddButton(i18n.T("profiles_clone"), func() {
+		p, ok := selected()
+		if !ok {
+			return
+		}
+		showProfileNameForm(app, i18n.T("profiles_clone")+": "+p.Name, p.Name+" (2)", func(name string) {
+			source := profileBefore(p)
+			clone, err := CloneProfile(p.UUID, name)
+			RecordProfileClone(source, clone, name, "tui", err)
+			done(err)
+		}, back)
+	})
+	buttons.AddButton(i18n.T("profiles_rename"), func() {
+		p, ok := selected()
+		if !ok {
+			return
+		}
+		showProfileNameForm(app, i18n.T("profiles_rename")+": "+p.Name, p.Name, func(name string) {
+			before := profileBefore(p)
+			err := RenameProfile(p.UUID, name)
+			RecordProfileAction("profile_rename", before, fmt.Sprintf("Perfil %s renomeado para %s", p.Name, name), "tui", err)
+			done(err)
+		}, back)
+	})
+	buttons.AddButton(i18n.T("profiles_delete"), func() {
+		p, ok := selected()
+		if !ok {
+			return
+		}
+		message := fmt.Sprintf(i18n.T("profiles_delete_confirm"), p.Name)
+		if p.Active {
+			message += "\n\n" + i18n.T("profiles_delete_active")
+		}
+		modal := tview.NewModal().
+			SetText(message).
+			AddButtons([]string{i18n.T("profiles_delete"), i18n.T("network_cancel")}).
+			SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+				if buttonIndex != 0 {
+					back()
+					return
+				}
+				before := profileBefore(p)
+				err := DeleteProfile(p.UUID)
+				RecordProfileAction("profile_delete", before, fmt.Sprintf("Perfil %s (%s, %s) removido", p.Name, p.Type, p.UUID), "tui", err)
+				done(err)
+			})
+		modal.SetBorder(true).
+			SetTitle(" " + i18n.T("profiles_delete") + " ").
+			SetTitleAlign(tview.AlignCenter).
+			SetTitleColor(errorColor).
+			SetBorderColor(errorColor).
+			SetBackgroundColor(backgroundColor)
+		app.SetRoot(modal, true)
+	})
+	buttons.AddButton(i18n.T("profiles_autoconnect"), func() {
+		p, ok := selected()
+		if !ok {
+			return
+		}
+		before := profileBefore(p)
+		err := SetProfileAutoconnect(p.UUID, !p.Autoconnect)
+		RecordProfileAction("profile_autoconnect", before, fmt.Sprintf("Perfil %s", p.Name), "tui", err)
+		done(err)
+	})
+	buttons.AddButton(i18n.T("profiles_priority"), func() {
+		p, ok := selected()
+		if !ok {
+			return
+		}
+		form := newForm(i18n.T("profiles_priority") + ": " + p.Name)
+		form.AddInputField(i18n.T("profiles_priority"), strconv.Itoa(p.Priority), 6, func(text string, last rune) bool {
+			return (last == '-' && len(text) == 1) || (last >= '0' && last <= '9')
+		}, nil)
+		form.AddButton("OK", func() {
+			text := form.GetFormItem(0).(*tview.InputField).GetText()
+			before := profileBefore(p)
+			priority, err := strconv.Atoi(text)
+			if err != nil {
+				err = fmt.Errorf("%w: prioridade inválida: %s", ErrInvalidConfig, text)
+			} else {
+				err = SetProfilePriority(p.UUID, priority)
+			}
+			RecordProfileAction("profile_priority", before, fmt.Sprintf("Perfil %s", p.Name), "tui", err)
+			done(err)
+		})
+		form.AddButton(i18n.T("network_cancel"), back)
+		showWizardStep(app, form, i18n.T("profiles_priority_help"))
+	})
+	buttons.AddButton(i18n.T("profiles_export"), func() {
+		p, ok := selected()
+		if !ok {
+			return
+		}
+		showExportForm(app, p.Name, func(path string, bundle Bundle, err error) {
+			RecordExport(path, bundle, "tui", err)
+			done(err)
+		}, back)
+	})
+	buttons.AddButton(i18n.T("profiles_import"), func() {
+		showImportForm(app, func(path string, steps []ImportStep, imported int, err error) {
+			RecordImport(path, steps, imported, "tui", err)
+			done(err)
+		}, back)
+	})
+	buttons.AddButton(i18n.T("network_back"), func() {
+		app.Stop() // Retorna ao menu principal
+	})
+
+	helpText := tview.NewTextView()
+	helpText.SetTextAlign(tview.AlignCenter)
+	helpText.SetDynamicColors(true)
+	helpText.SetText("[yellow]Tab: " + i18n.T("vpn_actions") + " • " + i18n.T("press_esc_return") + "[white]")
+
+	flex = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(buttons, 3, 0, false).
+		AddItem(helpText, 1, 0, false)
+
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyTab && table.HasFocus():
+			app.SetFocus(buttons)
+			return nil
+		case event.Key() == tcell.KeyBacktab && buttons.HasFocus():
+			app.SetFocus(table)
+			return nil
+		}
+		return event
+	})
+
+	app.SetRoot(flex, true).SetFocus(table)
+}
+
+// fillProfilesTable preenche a tabela com os perfis, mantendo a linha
+// selecionada
+func fillProfilesTable(table *tview.Table, profiles []SavedProfile, err error) {
+	row, _ := table.GetSelection()
+	for r := table.GetRowCount() - 1; r > 0; r-- {
+		table.RemoveRow(r)
+	}
+
+	if err != nil {
+		table.SetCell(1, 0, tview.NewTableCell(err.Error()).
+			SetTextColor(errorColor).
+			SetSelectable(false))
+		return
+	}
+	if len(profiles) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell(i18n.T("profiles_empty")).
+			SetTextColor(infoColor).
+			SetSelectable(false))
+		return
+	}
+
+	for i, p := range profiles {
+		state, stateColor := i18n.T("vpn_inactive"), fieldTextColor
+		if p.Active {
+			state, stateColor = i18n.T("vpn_active"), successColor
+		}
+		autoconnect, autoconnectColor := i18n.T("profiles_no"), errorColor
+		if p.Autoconnect {
+			autoconnect, autoconnectColor = i18n.T("profiles_yes"), successColor
+		}
+
+		cells := []struct {
+			text  string
+			color tcell.Color
+		}{
+			{p.Name, fieldTextColor},
+			{p.Type, fieldTextColor},
+			{orDash(p.Device), fieldTextColor},
+			{state, stateColor},
+			{autoconnect, autoconnectColor},
+			{strconv.Itoa(p.Priority), fieldTextColor},
+			{LastUsedText(p.LastUsed), fieldTextColor},
+		}
+		for col, cell := range cells {
+			table.SetCell(i+1, col, tview.NewTableCell(cell.text).SetTextColor(cell.color))
+		}
+	}
+
+	if row < 1 || row > len(profiles) {
+		row = 1
+	}
+	table.Select(row, 0)
+}
+
+// Pede o nome do perfil copiado ou renomeado
+func showProfileNameForm(app *tview.Application, title, name string, ok func(name string), cancel func()) {
+	form := newForm(title)
+	form.AddInputField(i18n.T("network_name"), name, 40, nil, nil)
+	form.AddButton("OK", func() {
+		ok(strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText()))
+	})
+	form.AddButton(i18n.T("network_cancel"), cancel)
+
+	showWizardStep(app, form, i18n.T("profiles_name_help"))
+}
+
+// yesNo converte o booleano no valor usado pelo NetworkManager
+func yesNo(on bool) string {
+	if on {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,225 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"networkmanager-tui/backend"
+)
+
+// RoutingConfig reúne as opções avançadas de uma família de endereços. Listas
+// nulas e textos vazios mantêm o valor atual do perfil; uma lista vazia, mas
+// não nula, remove todos os itens.
+type RoutingConfig struct {
+	Addresses    []string // Endereços adicionais em CIDR (apenas no modo manual)
+	Routes       []string // Rotas estáticas: "destino/prefixo [próximo-salto] [métrica] [table=N] [src=IP] [onlink=true] [mtu=N]..."
+	RoutingRules []string // Regras: "priority N [from X] [to Y] [iif nome] [oif nome] [fwmark M] table T"
+	DNSSearch    []string // Domínios de busca DNS
+	RouteMetric  string   // Métrica das rotas do perfil (-1 usa o padrão)
+	NeverDefault string   // yes: nunca usar o perfil para a rota padrão; no: usar
+}
+
+// Palavras-chave aceitas nas regras de roteamento
+var ruleKeywords = map[string]bool{
+	"priority": true, "from": true, "to": true, "table": true,
+	"iif": true, "oif": true, "fwmark": true,
+}
+
+// Nome de domínio aceito na busca DNS
+var domainRegex = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?)*\.?$`)
+
+// addRoutingSettings valida as opções avançadas da família e as grava em
+// settings. primary é o endereço principal do modo manual (vazio nos demais
+// modos, em que os endereços adicionais não se aplicam). Com Addresses nulo,
+// só o principal é gravado; keepExtraAddresses acrescenta depois os
+// adicionais que o perfil já tem.
+func addRoutingSettings(settings backend.Settings, family string, rc RoutingConfig, primary string) error {
+	v6 := family == "ipv6"
+
+	if primary == "" && len(rc.Addresses) > 0 {
+		return fmt.Errorf("%w: endereços adicionais exigem o modo manual com endereço principal", ErrInvalidConfig)
+	}
+	if primary != "" {
+		for _, addr := range rc.Addresses {
+			if !validCIDR(addr, v6) {
+				return fmt.Errorf("%w: endereço adicional inválido: %s", ErrInvalidConfig, addr)
+			}
+		}
+		settings[family+".addresses"] = joinNonEmpty(append([]string{primary}, rc.Addresses...))
+	}
+
+	if rc.Routes != nil {
+		for _, route := range rc.Routes {
+			if err := validateRoute(route, v6); err != nil {
+				return err
+			}
+		}
+		settings[family+".routes"] = joinNonEmpty(rc.Routes)
+	}
+
+	if rc.RoutingRules != nil {
+		for _, rule := range rc.RoutingRules {
+			if err := validateRoutingRule(rule, v6); err != nil {
+				return err
+			}
+		}
+		settings[family+".routing-rules"] = joinNonEmpty(rc.RoutingRules)
+	}
+
+	if rc.DNSSearch != nil {
+		for _, domain := range rc.DNSSearch {
+			if !domainRegex.MatchString(domain) {
+				return fmt.Errorf("%w: domínio de busca inválido: %s", ErrInvalidConfig, domain)
+			}
+		}
+		settings[family+".dns-search"] = joinNonEmpty(rc.DNSSearch)
+	}
+
+	if rc.RouteMetric != "" {
+		if n, err := strconv.Atoi(rc.RouteMetric); err != nil || n < -1 {
+			return fmt.Errorf("%w: métrica inválida: %s", ErrInvalidConfig, rc.RouteMetric)
+		}
+		settings[family+".route-metric"] = rc.RouteMetric
+	}
+
+	switch rc.NeverDefault {
+	case "":
+	case "yes", "no":
+		settings[family+".never-default"] = rc.NeverDefault
+	default:
+		return fmt.Errorf("%w: never-default deve ser yes ou no: %s", ErrInvalidConfig, rc.NeverDefault)
+	}
+	return nil
+}
+
+// keepExtraAddresses mantém os endereços adicionais do perfil profileID nas
+// famílias do modo manual em que cfg não os informa (Addresses nulo), já que
+// BuildNetworkSettings grava só o endereço principal
+func keepExtraAddresses(b backend.Backend, profileID string, cfg NetworkConfig, settings backend.Settings) error {
+	var current backend.Settings
+	for family, rc := range map[string]RoutingConfig{"ipv4": cfg.IPv4Routing, "ipv6": cfg.IPv6Routing} {
+		primary := settings[family+".addresses"]
+		if rc.Addresses != nil || primary == "" || settings[family+".method"] != "manual" {
+			continue
+		}
+		if current == nil {
+			p, err := b.Profile(profileID)
+			if err != nil {
+				return fmt.Errorf("perfil %s: %w", profileID, err)
+			}
+			current = p.Settings
+		}
+		extras := routingConfigFromSettings(current, family).Addresses
+		settings[family+".addresses"] = joinNonEmpty(append([]string{primary}, extras...))
+	}
+	return nil
+}
+
+// routingConfigFromSettings lê as opções avançadas da família no perfil.
+// Os endereços adicionais são os que seguem o principal.
+func routingConfigFromSettings(settings backend.Settings, family string) RoutingConfig {
+	rc := RoutingConfig{
+		Addresses:    []string{},
+		Routes:       splitItems(settings[family+".routes"]),
+		RoutingRules: splitItems(settings[family+".routing-rules"]),
+		DNSSearch:    splitValues(settings[family+".dns-search"]),
+		RouteMetric:  settings[family+".route-metric"],
+		NeverDefault: settings[family+".never-default"],
+	}
+	if addresses := splitValues(settings[family+".addresses"]); len(addresses) > 1 {
+		rc.Addresses = addresses[1:]
+	}
+	return rc
+}
+
+// validateRoute verifica uma rota no formato do nmcli
+func validateRoute(route string, v6 bool) error {
+	fields := strings.Fields(route)
+	if len(fields) == 0 || !(validCIDR(fields[0], v6) || validIP(fields[0], v6)) {
+		return fmt.Errorf("%w: destino da rota inválido: %s", ErrInvalidConfig, route)
+	}
+
+	for _, field := range fields[1:] {
+		key, value, isAttr := strings.Cut(field, "=")
+		switch {
+		case isAttr:
+			// Os mesmos atributos (table, src, onlink, mtu...) que os backends gravam
+			if _, err := backend.RouteAttribute(key, value); err != nil {
+				return fmt.Errorf("%w: %v: %s", ErrInvalidConfig, err, route)
+			}
+		case validIP(field, v6):
+		default:
+			if _, err := strconv.ParseUint(field, 10, 32); err != nil {
+				return fmt.Errorf("%w: métrica ou próximo salto inválido na rota: %s", ErrInvalidConfig, route)
+			}
+		}
+	}
+	return nil
+}
+
+// validateRoutingRule verifica uma regra de roteamento no formato do nmcli
+func validateRoutingRule(rule string, v6 bool) error {
+	fields := strings.Fields(rule)
+	hasPriority := false
+
+	for i := 0; i < len(fields); i++ {
+		keyword := fields[i]
+		if keyword == "not" {
+			continue
+		}
+		if !ruleKeywords[keyword] || i+1 >= len(fields) {
+			return fmt.Errorf("%w: regra de roteamento inválida: %s", ErrInvalidConfig, rule)
+		}
+		i++
+		value := fields[i]
+
+		switch keyword {
+		case "priority", "table":
+			if _, err := strconv.ParseUint(value, 10, 32); err != nil {
+				return fmt.Errorf("%w: %s inválido na regra: %s", ErrInvalidConfig, keyword, rule)
+			}
+			hasPriority = hasPriority || keyword == "priority"
+		case "from", "to":
+			if !validCIDR(value, v6) && !validIP(value, v6) {
+				return fmt.Errorf("%w: endereço inválido na regra: %s", ErrInvalidConfig, rule)
+			}
+		}
+	}
+
+	if !hasPriority {
+		return fmt.Errorf("%w: a regra precisa de prioridade (priority N): %s", ErrInvalidConfig, rule)
+	}
+	return nil
+}
+
+// validIP verifica se o endereço é da família informada
+func validIP(value string, v6 bool) bool {
+	if v6 {
+		return validateIPv6(value)
+	}
+	return validateIPv4(value)
+}
+
+// validCIDR verifica se o prefixo CIDR é da família informada
+func validCIDR(value string, v6 bool) bool {
+	ip, _, err := net.ParseCIDR(value)
+	if err != nil {
+		return false
+	}
+	return (ip.To4() == nil) == v6
+}
+
+// splitItems separa listas cujos itens contêm espaços (rotas e regras), que
+// o NetworkManager separa apenas por vírgula
+func splitItems(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
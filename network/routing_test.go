@@ -0,0 +1,75 @@
+package network
+
+import (
+	"testing"
+
+	"networkmanager-tui/backend"
+)
+
+// fakeProfile usa um backend em memória e retorna o perfil da interface
+func fakeProfile(t *testing.T, iface string) (*backend.Fake, backend.Profile) {
+	t.Helper()
+	f := backend.NewFake()
+	backend.SetDefault(f)
+	profiles, err := InterfaceProfiles(iface)
+	if err != nil || len(profiles) == 0 {
+		t.Fatalf("perfil de %s: %v", iface, err)
+	}
+	return f, profiles[0]
+}
+
+func TestConfigureKeepsExtraAddresses(t *testing.T) {
+	f, p := fakeProfile(t, "eth0")
+	if err := f.ModifyProfile(p.ID(), backend.Settings{"ipv4.addresses": "192.168.1.100/24,10.0.0.5/24,10.0.1.5/24"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Sem --extra-address, os endereços adicionais continuam
+	cfg := NetworkConfig{Interface: "eth0", IPv4Mode: "manual", IPv4Address: "192.168.1.50", IPv4Netmask: "255.255.255.0"}
+	preview, err := PreviewNetworkConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, change := range preview.Changes {
+		if change.Key == "ipv4.addresses" && change.After != "192.168.1.50/24,10.0.0.5/24,10.0.1.5/24" {
+			t.Errorf("pré-visualização de ipv4.addresses = %q", change.After)
+		}
+	}
+	if err := ApplyNetworkConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := profileSetting(t, f, p.ID(), "ipv4.addresses"); got != "192.168.1.50/24,10.0.0.5/24,10.0.1.5/24" {
+		t.Errorf("ipv4.addresses = %q", got)
+	}
+
+	// Uma lista vazia remove os adicionais
+	cfg.IPv4Routing.Addresses = []string{}
+	if err := ApplyNetworkConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := profileSetting(t, f, p.ID(), "ipv4.addresses"); got != "192.168.1.50/24" {
+		t.Errorf("ipv4.addresses depois de remover os adicionais = %q", got)
+	}
+}
+
+// profileSetting lê uma propriedade do perfil
+func profileSetting(t *testing.T, b backend.Backend, id, key string) string {
+	t.Helper()
+	p, err := b.Profile(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.Settings[key]
+}
+
+func TestValidateRouteMatchesBackends(t *testing.T) {
+	// A mesma rota dos testes do backend D-Bus e do nmcli
+	if err := validateRoute("10.20.0.0/16 192.168.1.254 50 table=100 mtu=1400 onlink=true src=192.168.1.100", false); err != nil {
+		t.Errorf("rota válida recusada: %v", err)
+	}
+	for _, invalid := range []string{"10.0.0.0/8 foo=1", "10.0.0.0/8 mtu=x", "10.0.0.0/8 onlink=talvez", "10.0.0.0/8 src=abc"} {
+		if err := validateRoute(invalid, false); err == nil {
+			t.Errorf("validateRoute(%q) sem erro", invalid)
+		}
+	}
+}
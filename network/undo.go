@@ -0,0 +1,245 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+
+	"networkmanager-tui/backend"
+	"networkmanager-tui/history"
+	"networkmanager-tui/i18n"
+	"networkmanager-tui/logger"
+)
+
+// A remoção de um perfil é desfeita criando o perfil de novo
+const actionProfileDelete = "profile_delete"
+
+// UndoPlan descreve como uma alteração registrada no histórico será desfeita:
+// o perfil volta aos valores anteriores à alteração ou, se foi removido, é
+// criado de novo (Preview.Create)
+type UndoPlan struct {
+	Action  history.Action `json:"action" yaml:"action"`
+	Preview Preview        `json:"preview" yaml:"preview"`
+	Skipped []string       `json:"skipped" yaml:"skipped"` // Propriedades alteradas que não são restauradas (senhas)
+
+	active bool // O perfil está ativo e precisa ser reativado
+}
+
+// PlanUndo compara o perfil atual com o estado guardado antes da alteração,
+// sem alterar nada
+func PlanUndo(action history.Action) (UndoPlan, error) {
+	if !action.Undoable() {
+		return UndoPlan{}, fmt.Errorf("%w: a ação %s (%s) não guardou o estado anterior do perfil e não pode ser desfeita",
+			ErrInvalidConfig, action.ID, action.Action)
+	}
+
+	b := backend.Default()
+	current, err := b.Profile(action.Profile)
+	if action.Action == actionProfileDelete {
+		if errors.Is(err, backend.ErrNotFound) {
+			return planRecreate(b, action)
+		}
+		if err == nil {
+			return UndoPlan{}, fmt.Errorf("%w: o perfil %s removido pela ação %s já existe de novo",
+				ErrInvalidConfig, current.Name, action.ID)
+		}
+	}
+	if err != nil {
+		return UndoPlan{}, fmt.Errorf("perfil %s: %w", action.Profile, err)
+	}
+
+	plan := UndoPlan{
+		Action: action,
+		Preview: Preview{
+			ProfileID: current.ID(),
+			Changes:   backend.DiffSettings(current.Settings.Masked(), action.Snapshot.Masked()),
+			Commands:  []string{},
+			previous:  current.Settings,
+		},
+		Skipped: []string{},
+		active:  current.Active,
+	}
+	if planner, ok := b.(backend.Planner); ok {
+		plan.Preview.Commands = planner.Plan(current.ID(), action.Snapshot)
+	}
+	plan.Skipped = skippedKeys(action)
+	return plan, nil
+}
+
+// planRecreate descreve a criação do perfil removido, com as propriedades
+// guardadas na remoção
+func planRecreate(b backend.Backend, action history.Action) (UndoPlan, error) {
+	p := deletedProfile(action.Snapshot)
+	if p.Name == "" || p.Type == "" {
+		return UndoPlan{}, fmt.Errorf("%w: a ação %s não guardou o nome e o tipo do perfil removido",
+			ErrInvalidConfig, action.ID)
+	}
+	plan := UndoPlan{
+		Action: action,
+		Preview: Preview{
+			ProfileID: action.Profile,
+			Create:    true,
+			Changes:   backend.DiffSettings(backend.Settings{}, action.Snapshot.Masked()),
+			Commands:  []string{},
+		},
+		Skipped: skippedKeys(action),
+	}
+	if planner, ok := b.(backend.Planner); ok {
+		plan.Preview.Commands = planner.PlanAdd(p)
+	}
+	return plan, nil
+}
+
+// skippedKeys lista as propriedades alteradas pela ação que não foram
+// guardadas (senhas e chaves)
+func skippedKeys(action history.Action) []string {
+	skipped := []string{}
+	for _, change := range action.Diff {
+		if _, ok := action.Snapshot[change.Key]; !ok {
+			skipped = append(skipped, change.Key)
+		}
+	}
+	return skipped
+}
+
+// deletedSettings retorna as propriedades do perfil que será removido, com o
+// nome, o identificador, o tipo e o dispositivo, para criá-lo de novo ao
+// desfazer a remoção
+func deletedSettings(p backend.Profile) backend.Settings {
+	settings := p.Settings.Clone()
+	for key, value := range map[string]string{
+		"connection.id":             p.Name,
+		"connection.uuid":           p.UUID,
+		"connection.type":           p.Type,
+		"connection.interface-name": p.Device,
+	} {
+		if settings[key] == "" && value != "" {
+			settings[key] = value
+		}
+	}
+	return settings
+}
+
+// deletedProfile monta o perfil removido a partir das propriedades guardadas
+// por deletedSettings. Propriedades vazias ficam com o padrão do backend.
+func deletedProfile(snapshot backend.Settings) backend.Profile {
+	p := backend.Profile{
+		Name:     snapshot["connection.id"],
+		Type:     snapshot["connection.type"],
+		Device:   snapshot["connection.interface-name"],
+		Settings: backend.Settings{},
+	}
+	for key, value := range snapshot {
+		switch key {
+		case "connection.id", "connection.type", "connection.interface-name", "connection.timestamp":
+			continue
+		}
+		if value != "" {
+			p.Settings[key] = value
+		}
+	}
+	return p
+}
+
+// keepPresharedKeys devolve as chaves compartilhadas, que o histórico não
+// guarda, aos peers restaurados que continuam no perfil
+func keepPresharedKeys(id string, settings backend.Settings) (backend.Settings, error) {
+	peers, err := backend.ParseWireGuardPeers(settings["wireguard.peers"])
+	if err != nil {
+		return nil, err
+	}
+	psk, err := presharedKeys(id)
+	if err != nil {
+		return nil, err
+	}
+	for i := range peers {
+		if peers[i].PresharedKey == "" {
+			peers[i].PresharedKey = psk[peers[i].PublicKey]
+		}
+	}
+	settings = settings.Clone()
+	settings["wireguard.peers"] = backend.FormatWireGuardPeers(peers)
+	return settings, nil
+}
+
+// Undo restaura as propriedades guardadas e reativa o perfil, se estiver
+// ativo; um perfil removido é criado de novo
+func Undo(plan UndoPlan) error {
+	b := backend.Default()
+	if plan.Preview.Create {
+		p, err := b.AddProfile(deletedProfile(plan.Action.Snapshot))
+		if err != nil {
+			return fmt.Errorf("erro ao recriar perfil: %w", err)
+		}
+		logger.LogInfo("Remoção %s desfeita: perfil %s criado de novo", plan.Action.ID, p.Name)
+		return nil
+	}
+
+	settings := plan.Action.Snapshot
+	if settings["wireguard.peers"] != "" {
+		var err error
+		if settings, err = keepPresharedKeys(plan.Preview.ProfileID, settings); err != nil {
+			return fmt.Errorf("erro ao ler os peers do perfil: %w", err)
+		}
+	}
+	if err := b.ModifyProfile(plan.Preview.ProfileID, settings); err != nil {
+		return fmt.Errorf("erro ao restaurar perfil: %w", err)
+	}
+	if plan.active {
+		if err := b.Activate(plan.Preview.ProfileID); err != nil {
+			return fmt.Errorf("erro ao reativar conexão: %w", err)
+		}
+	}
+	logger.LogInfo("Alteração %s desfeita no perfil %s", plan.Action.ID, plan.Preview.ProfileID)
+	return nil
+}
+
+// RecordUndo registra no histórico a ação que desfez outra. Ela também guarda
+// o estado anterior, e pode ser desfeita.
+func RecordUndo(plan UndoPlan, via string, err error) {
+	var diff []backend.Change
+	for _, change := range plan.Preview.Changes {
+		if change.Changed() {
+			diff = append(diff, change)
+		}
+	}
+	action := history.Action{
+		Action:    "network_undo",
+		Interface: plan.Action.Interface,
+		Profile:   plan.Preview.ProfileID,
+		Details: fmt.Sprintf("Desfeita a ação %s (%s de %s): %s", plan.Action.ID, plan.Action.Action,
+			plan.Action.Timestamp.Format("02/01/2006 15:04:05"), outcomeText(err)),
+		Diff:       diff,
+		ModifiedBy: via,
+	}.WithResult(err)
+	// Desfazer a remoção cria o perfil, e isso não pode ser desfeito
+	if err == nil && !plan.Preview.Create {
+		action.Snapshot = history.Snapshot(plan.Preview.previous, diff)
+	}
+	history.Record(action)
+}
+
+// showUndo mostra as propriedades que voltam ao valor anterior e desfaz a
+// alteração ao confirmar; back volta ao histórico
+func showUndo(app *tview.Application, action history.Action, back func()) {
+	plan, err := PlanUndo(action)
+	if err != nil {
+		showMessage(app, i18n.T("error_title"), err.Error())
+		return
+	}
+	showPreview(app, plan.Preview, func() {
+		err := Undo(plan)
+		RecordUndo(plan, "tui", err)
+		if err != nil {
+			showMessage(app, i18n.T("error_title"), err.Error())
+			return
+		}
+		message := i18n.T("history_undone")
+		if len(plan.Skipped) > 0 {
+			message += "\n\n" + fmt.Sprintf(i18n.T("history_undo_skipped"), strings.Join(plan.Skipped, ", "))
+		}
+		showMessage(app, i18n.T("success_title"), message)
+	}, back)
+}
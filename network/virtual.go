@@ -0,0 +1,597 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rivo/tview"
+
+	"networkmanager-tui/backend"
+	"networkmanager-tui/history"
+	"networkmanager-tui/i18n"
+	"networkmanager-tui/logger"
+)
+
+// Tipos de interface virtual
+const (
+	VirtualVLAN   = "vlan"
+	VirtualBond   = "bond"
+	VirtualBridge = "bridge"
+	VirtualTeam   = "team"
+)
+
+// VirtualKinds lista os tipos de interface virtual na ordem do assistente
+var VirtualKinds = []string{VirtualVLAN, VirtualBond, VirtualBridge, VirtualTeam}
+
+// BondModes lista os modos de bond aceitos pelo kernel
+var BondModes = []string{"balance-rr", "active-backup", "balance-xor", "broadcast", "802.3ad", "balance-tlb", "balance-alb"}
+
+// TeamRunners lista os runners do teamd
+var TeamRunners = []string{"roundrobin", "activebackup", "loadbalance", "broadcast", "lacp"}
+
+// Nome de interface aceito pelo kernel (até 15 caracteres)
+var ifaceNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,14}$`)
+
+// VirtualInterfaceConfig descreve uma interface virtual e as suas portas
+type VirtualInterfaceConfig struct {
+	Kind string // vlan, bond, bridge ou team
+	Name string // Nome da interface criada (ex.: bond0, br0, eth0.100)
+
+	Parent string // VLAN: dispositivo pai
+	VLANID int    // VLAN: identificador 802.1Q (1 a 4094)
+
+	Ports []string // Bond, bridge e team: dispositivos incluídos como portas
+
+	BondMode    string // Bond: modo (ex.: active-backup, 802.3ad)
+	BondMiimon  int    // Bond: intervalo de monitoramento do link em ms (0 desativa)
+	BondPrimary string // Bond: porta preferida no modo active-backup
+
+	BridgeSTP bool // Bridge: habilita o Spanning Tree Protocol
+
+	TeamRunner string // Team: runner do teamd (ex.: activebackup, lacp)
+
+	IP NetworkConfig // Configuração IP da interface criada; Interface é ignorado
+}
+
+// virtualInventory reúne os dispositivos e perfis consultados na validação
+// das interfaces virtuais
+type virtualInventory struct {
+	ports   []string        // Placas ethernet: portas de bond, bridge e team
+	parents []string        // Placas ethernet e bonds: pais de VLAN
+	taken   map[string]bool // Nomes de dispositivos e de perfis já em uso
+}
+
+// loadVirtualInventory lista os dispositivos e perfis do backend. Um bond
+// declarado em um perfil ainda sem dispositivo também pode ser pai de VLAN.
+func loadVirtualInventory() (virtualInventory, error) {
+	b := backend.Default()
+	devices, err := b.Devices()
+	if err != nil {
+		return virtualInventory{}, fmt.Errorf("erro ao listar dispositivos: %w", err)
+	}
+	profiles, err := b.Profiles()
+	if err != nil {
+		return virtualInventory{}, fmt.Errorf("erro ao listar perfis: %w", err)
+	}
+
+	inv := virtualInventory{ports: []string{}, parents: []string{}, taken: map[string]bool{}}
+	isParent := map[string]bool{}
+	addParent := func(name string) {
+		if name != "" && !isParent[name] {
+			isParent[name] = true
+			inv.parents = append(inv.parents, name)
+		}
+	}
+	for _, dev := range devices {
+		inv.taken[dev.Name] = true
+		switch dev.Type {
+		case "ethernet":
+			inv.ports = append(inv.ports, dev.Name)
+			addParent(dev.Name)
+		case VirtualBond:
+			addParent(dev.Name)
+		}
+	}
+	for _, p := range profiles {
+		inv.taken[p.Name] = true
+		if p.Device != "" {
+			inv.taken[p.Device] = true
+		}
+		if p.Type == VirtualBond {
+			addParent(p.Device)
+		}
+	}
+	return inv, nil
+}
+
+// declare acrescenta ao inventário uma interface que será criada na mesma
+// etapa, para que as seguintes possam usá-la como pai
+func (inv *virtualInventory) declare(cfg VirtualInterfaceConfig, master backend.Profile, ports []backend.Profile) {
+	inv.taken[cfg.Name] = true
+	for _, p := range append([]backend.Profile{master}, ports...) {
+		inv.taken[p.Name] = true
+	}
+	if cfg.Kind == VirtualBond {
+		inv.parents = append(inv.parents, cfg.Name)
+	}
+}
+
+// PortCandidates lista os dispositivos que podem ser porta de bond, bridge e
+// team: as placas ethernet
+func PortCandidates() ([]string, error) {
+	inv, err := loadVirtualInventory()
+	if err != nil {
+		return nil, err
+	}
+	return inv.ports, nil
+}
+
+// ParentCandidates lista os dispositivos que podem ser pai de uma VLAN: as
+// placas ethernet e os bonds, existentes ou declarados em um perfil
+func ParentCandidates() ([]string, error) {
+	inv, err := loadVirtualInventory()
+	if err != nil {
+		return nil, err
+	}
+	return inv.parents, nil
+}
+
+// BuildVirtualProfiles valida a configuração e monta o perfil da interface
+// virtual (master) e os perfis das suas portas
+func BuildVirtualProfiles(cfg VirtualInterfaceConfig) (backend.Profile, []backend.Profile, error) {
+	inv, err := loadVirtualInventory()
+	if err != nil {
+		return backend.Profile{}, nil, err
+	}
+	return buildVirtualProfiles(cfg, inv)
+}
+
+// buildVirtualProfiles valida a configuração contra o inventário: o nome não
+// pode estar em uso por dispositivo ou perfil, e o pai e as portas precisam
+// estar entre os candidatos
+func buildVirtualProfiles(cfg VirtualInterfaceConfig, inv virtualInventory) (backend.Profile, []backend.Profile, error) {
+	if !ifaceNameRegex.MatchString(cfg.Name) {
+		return backend.Profile{}, nil, fmt.Errorf("%w: nome de interface inválido: %q", ErrInvalidConfig, cfg.Name)
+	}
+	if inv.taken[cfg.Name] {
+		return backend.Profile{}, nil, fmt.Errorf("%w: já existe dispositivo ou perfil chamado %s", ErrInvalidConfig, cfg.Name)
+	}
+
+	master := backend.Profile{
+		Name:     cfg.Name,
+		Type:     cfg.Kind,
+		Device:   cfg.Name,
+		Settings: backend.Settings{},
+	}
+
+	switch cfg.Kind {
+	case VirtualVLAN:
+		if !contains(inv.parents, cfg.Parent) {
+			return backend.Profile{}, nil, fmt.Errorf("%w: dispositivo pai inválido: %q", ErrInvalidConfig, cfg.Parent)
+		}
+		if cfg.VLANID < 1 || cfg.VLANID > 4094 {
+			return backend.Profile{}, nil, fmt.Errorf("%w: o ID da VLAN deve estar entre 1 e 4094: %d", ErrInvalidConfig, cfg.VLANID)
+		}
+		master.Settings["vlan.parent"] = cfg.Parent
+		master.Settings["vlan.id"] = strconv.Itoa(cfg.VLANID)
+
+	case VirtualBond:
+		if !contains(BondModes, cfg.BondMode) {
+			return backend.Profile{}, nil, fmt.Errorf("%w: modo de bond desconhecido: %q", ErrInvalidConfig, cfg.BondMode)
+		}
+		if cfg.BondMiimon < 0 {
+			return backend.Profile{}, nil, fmt.Errorf("%w: miimon inválido: %d", ErrInvalidConfig, cfg.BondMiimon)
+		}
+		options := []string{"mode=" + cfg.BondMode, "miimon=" + strconv.Itoa(cfg.BondMiimon)}
+		if cfg.BondPrimary != "" {
+			if !contains(cfg.Ports, cfg.BondPrimary) {
+				return backend.Profile{}, nil, fmt.Errorf("%w: a porta primária %s não está entre as portas", ErrInvalidConfig, cfg.BondPrimary)
+			}
+			options = append(options, "primary="+cfg.BondPrimary)
+		}
+		master.Settings["bond.options"] = strings.Join(options, ",")
+
+	case VirtualBridge:
+		master.Settings["bridge.stp"] = "no"
+		if cfg.BridgeSTP {
+			master.Settings["bridge.stp"] = "yes"
+		}
+
+	case VirtualTeam:
+		if !contains(TeamRunners, cfg.TeamRunner) {
+			return backend.Profile{}, nil, fmt.Errorf("%w: runner de team desconhecido: %q", ErrInvalidConfig, cfg.TeamRunner)
+		}
+		master.Settings["team.config"] = fmt.Sprintf(`{"runner": {"name": "%s"}}`, cfg.TeamRunner)
+
+	default:
+		return backend.Profile{}, nil, fmt.Errorf("%w: tipo de interface desconhecido: %q", ErrInvalidConfig, cfg.Kind)
+	}
+
+	// Configuração IP da interface criada; sem modo informado, usa DHCP/SLAAC
+	ip := cfg.IP
+	ip.Interface = cfg.Name
+	if ip.IPv4Mode == "" {
+		ip.IPv4Mode = "auto"
+	}
+	if ip.IPv6Mode == "" {
+		ip.IPv6Mode = "auto"
+	}
+	settings, err := BuildNetworkSettings(ip)
+	if err != nil {
+		return backend.Profile{}, nil, err
+	}
+	for key, value := range settings {
+		master.Settings[key] = value
+	}
+
+	var ports []backend.Profile
+	if cfg.Kind != VirtualVLAN {
+		if len(cfg.Ports) == 0 {
+			return backend.Profile{}, nil, fmt.Errorf("%w: escolha ao menos uma porta", ErrInvalidConfig)
+		}
+		seen := map[string]bool{}
+		for _, port := range cfg.Ports {
+			if !contains(inv.ports, port) || seen[port] {
+				return backend.Profile{}, nil, fmt.Errorf("%w: porta inválida ou repetida: %q", ErrInvalidConfig, port)
+			}
+			seen[port] = true
+			name := fmt.Sprintf("%s-port-%s", cfg.Name, port)
+			if inv.taken[name] {
+				return backend.Profile{}, nil, fmt.Errorf("%w: já existe perfil chamado %s", ErrInvalidConfig, name)
+			}
+			ports = append(ports, backend.Profile{
+				Name:   name,
+				Type:   backend.ProfileType("ethernet"),
+				Device: port,
+				Settings: backend.Settings{
+					"connection.master":     cfg.Name,
+					"connection.slave-type": cfg.Kind,
+				},
+			})
+		}
+	}
+	return master, ports, nil
+}
+
+// CreateVirtualInterface cria os perfis da interface virtual e das portas e
+// os ativa, a interface primeiro. Se algum perfil não puder ser criado, os já
+// criados são removidos. Retorna os nomes dos perfis criados.
+func CreateVirtualInterface(cfg VirtualInterfaceConfig) ([]string, error) {
+	return CreateVirtualInterfaces([]VirtualInterfaceConfig{cfg})
+}
+
+// CreateVirtualInterfaces cria várias interfaces virtuais de uma vez, na
+// ordem informada; uma VLAN pode ter como pai um bond declarado antes na
+// lista. Todas são validadas antes de qualquer perfil ser criado.
+func CreateVirtualInterfaces(cfgs []VirtualInterfaceConfig) ([]string, error) {
+	inv, err := loadVirtualInventory()
+	if err != nil {
+		return nil, err
+	}
+	var profiles []backend.Profile
+	for _, cfg := range cfgs {
+		master, ports, err := buildVirtualProfiles(cfg, inv)
+		if err != nil {
+			return nil, err
+		}
+		inv.declare(cfg, master, ports)
+		profiles = append(append(profiles, master), ports...)
+	}
+
+	b := backend.Default()
+	var created []backend.Profile
+	undo := func() {
+		for i := len(created) - 1; i >= 0; i-- {
+			b.DeleteProfile(created[i].ID())
+		}
+	}
+
+	for _, p := range profiles {
+		profile, err := b.AddProfile(p)
+		if err != nil {
+			undo()
+			return nil, fmt.Errorf("erro ao criar perfil %s: %w", p.Name, err)
+		}
+		created = append(created, profile)
+	}
+
+	names := make([]string, len(created))
+	for i, p := range created {
+		names[i] = p.Name
+	}
+	for _, p := range created {
+		if err := b.Activate(p.ID()); err != nil {
+			// Os perfis ficam salvos para correção; apenas a ativação falhou
+			return names, fmt.Errorf("perfis criados, mas erro ao ativar %s: %w", p.Name, err)
+		}
+	}
+
+	ifaces := make([]string, len(cfgs))
+	for i, cfg := range cfgs {
+		ifaces[i] = fmt.Sprintf("%s (%s)", cfg.Name, cfg.Kind)
+	}
+	logger.LogInfo("Interfaces %s criadas com os perfis %s", strings.Join(ifaces, ", "), strings.Join(names, ", "))
+	return names, nil
+}
+
+// RecordVirtualInterfaces registra no histórico a criação das interfaces
+// virtuais e dos perfis criados
+func RecordVirtualInterfaces(cfgs []VirtualInterfaceConfig, profiles []string, via string, err error) {
+	ifaces := make([]string, len(cfgs))
+	for i, cfg := range cfgs {
+		ifaces[i] = fmt.Sprintf("%s (%s)", cfg.Name, cfg.Kind)
+	}
+	details := "Interface " + strings.Join(ifaces, ", ")
+	if len(profiles) > 0 {
+		details += ": perfis " + strings.Join(profiles, ", ")
+	}
+	history.Record(history.Action{
+		Action:     "virtual_interface_create",
+		Interface:  cfgs[0].Name,
+		Details:    fmt.Sprintf("%s (%s)", details, outcomeText(err)),
+		ModifiedBy: via,
+	}.WithResult(err))
+}
+
+// ShowVirtualWizard abre o assistente de criação de interfaces virtuais, em
+// três etapas: tipo e nome, portas e opções do tipo, e a configuração IP da
+// interface criada. As portas não recebem configuração IP.
+func ShowVirtualWizard(app *tview.Application) {
+	cfg := VirtualInterfaceConfig{
+		Kind:       VirtualVLAN,
+		VLANID:     100,
+		BondMode:   "active-backup",
+		BondMiimon: 100,
+		TeamRunner: "activebackup",
+		IP:         NetworkConfig{IPv4Mode: "auto", IPv6Mode: "auto"},
+	}
+
+	inv, err := loadVirtualInventory()
+	if err == nil && len(inv.parents) == 0 {
+		err = errors.New(i18n.T("virtual_no_ports"))
+	}
+	if err != nil {
+		showMessage(app, i18n.T("error_title"), err.Error())
+		return
+	}
+	candidates, parents := inv.ports, inv.parents
+	if len(parents) > 0 {
+		cfg.Parent = parents[0]
+	}
+
+	var kindStep, optionsStep, ipStep func()
+
+	// Etapa 1: tipo e nome da interface
+	kindStep = func() {
+		form := newForm(fmt.Sprintf("%s (1/3)", i18n.T("virtual_title")))
+		form.AddDropDown(i18n.T("virtual_kind"), VirtualKinds, indexOf(VirtualKinds, cfg.Kind), nil)
+		form.AddInputField(i18n.T("virtual_name"), cfg.Name, 20, nil, nil)
+		name := form.GetFormItemByLabel(i18n.T("virtual_name")).(*tview.InputField)
+		name.SetPlaceholder(suggestedVirtualName(cfg))
+
+		form.GetFormItemByLabel(i18n.T("virtual_kind")).(*tview.DropDown).SetSelectedFunc(func(option string, index int) {
+			cfg.Kind = option
+			name.SetPlaceholder(suggestedVirtualName(cfg))
+		})
+
+		form.AddButton(i18n.T("virtual_next"), func() {
+			cfg.Name = strings.TrimSpace(name.GetText())
+			optionsStep()
+		})
+		form.AddButton(i18n.T("network_cancel"), func() {
+			app.Stop()
+		})
+		showWizardStep(app, form, "")
+	}
+
+	// Etapa 2: dispositivo pai (VLAN) ou portas e opções do tipo
+	optionsStep = func() {
+		if cfg.Name == "" {
+			cfg.Name = suggestedVirtualName(cfg)
+		}
+		form := newForm(fmt.Sprintf("%s: %s (2/3)", i18n.T("virtual_title"), cfg.Name))
+
+		var read func() error
+		switch cfg.Kind {
+		case VirtualVLAN:
+			form.AddDropDown(i18n.T("virtual_parent"), parents, indexOf(parents, cfg.Parent), nil)
+			form.AddInputField(i18n.T("virtual_vlan_id"), strconv.Itoa(cfg.VLANID), 10, tview.InputFieldInteger, nil)
+			read = func() error {
+				_, cfg.Parent = form.GetFormItemByLabel(i18n.T("virtual_parent")).(*tview.DropDown).GetCurrentOption()
+				id, err := strconv.Atoi(form.GetFormItemByLabel(i18n.T("virtual_vlan_id")).(*tview.InputField).GetText())
+				if err != nil || id < 1 || id > 4094 {
+					return fmt.Errorf("%w: o ID da VLAN deve estar entre 1 e 4094", ErrInvalidConfig)
+				}
+				cfg.VLANID = id
+				return nil
+			}
+
+		default:
+			form.AddTextView(i18n.T("virtual_ports"), "", 1, 1, true, false)
+			for _, port := range candidates {
+				form.AddCheckbox("  "+port, contains(cfg.Ports, port), nil)
+			}
+			readPorts := func() {
+				cfg.Ports = nil
+				for _, port := range candidates {
+					if form.GetFormItemByLabel("  " + port).(*tview.Checkbox).IsChecked() {
+						cfg.Ports = append(cfg.Ports, port)
+					}
+				}
+			}
+
+			switch cfg.Kind {
+			case VirtualBond:
+				primaries := append([]string{i18n.T("virtual_none")}, candidates...)
+				form.AddDropDown(i18n.T("virtual_bond_mode"), BondModes, indexOf(BondModes, cfg.BondMode), nil)
+				form.AddInputField(i18n.T("virtual_bond_miimon"), strconv.Itoa(cfg.BondMiimon), 10, tview.InputFieldInteger, nil)
+				form.AddDropDown(i18n.T("virtual_bond_primary"), primaries, indexOf(primaries, cfg.BondPrimary), nil)
+				read = func() error {
+					readPorts()
+					_, cfg.BondMode = form.GetFormItemByLabel(i18n.T("virtual_bond_mode")).(*tview.DropDown).GetCurrentOption()
+					miimon, err := strconv.Atoi(form.GetFormItemByLabel(i18n.T("virtual_bond_miimon")).(*tview.InputField).GetText())
+					if err != nil || miimon < 0 {
+						return fmt.Errorf("%w: miimon inválido", ErrInvalidConfig)
+					}
+					cfg.BondMiimon = miimon
+					index, primary := form.GetFormItemByLabel(i18n.T("virtual_bond_primary")).(*tview.DropDown).GetCurrentOption()
+					cfg.BondPrimary = ""
+					if index > 0 {
+						cfg.BondPrimary = primary
+					}
+					return nil
+				}
+			case VirtualBridge:
+				form.AddCheckbox(i18n.T("virtual_bridge_stp"), cfg.BridgeSTP, nil)
+				read = func() error {
+					readPorts()
+					cfg.BridgeSTP = form.GetFormItemByLabel(i18n.T("virtual_bridge_stp")).(*tview.Checkbox).IsChecked()
+					return nil
+				}
+			case VirtualTeam:
+				form.AddDropDown(i18n.T("virtual_team_runner"), TeamRunners, indexOf(TeamRunners, cfg.TeamRunner), nil)
+				read = func() error {
+					readPorts()
+					_, cfg.TeamRunner = form.GetFormItemByLabel(i18n.T("virtual_team_runner")).(*tview.DropDown).GetCurrentOption()
+					return nil
+				}
+			}
+		}
+
+		form.AddButton(i18n.T("network_back"), func() {
+			read()
+			kindStep()
+		})
+		form.AddButton(i18n.T("virtual_next"), func() {
+			if err := read(); err != nil {
+				showMessage(app, i18n.T("error_title"), err.Error())
+				return
+			}
+			ipStep()
+		})
+		showWizardStep(app, form, "")
+	}
+
+	// Etapa 3: configuração IP da interface criada
+	ipStep = func() {
+		form := newForm(fmt.Sprintf("%s: %s (3/3)", i18n.T("virtual_title"), cfg.Name))
+		ipv4Modes := []string{IPv4ModeAuto, IPv4ModeManual}
+		ipv6Modes := []string{IPv6ModeAuto, IPv6ModeManual, IPv6ModeDisabled}
+		ipv6Values := []string{"auto", "manual", "disabled"}
+		dns := func(servers []string, i int) string {
+			if i < len(servers) {
+				return servers[i]
+			}
+			return ""
+		}
+
+		ipv4Mode := 0
+		if cfg.IP.IPv4Mode == "manual" {
+			ipv4Mode = 1
+		}
+		form.AddDropDown(i18n.T("network_ipv4_mode"), ipv4Modes, ipv4Mode, nil)
+		form.AddInputField(i18n.T("network_ipv4_address"), cfg.IP.IPv4Address, 20, nil, nil)
+		form.AddInputField(i18n.T("network_ipv4_netmask"), cfg.IP.IPv4Netmask, 20, nil, nil)
+		form.AddInputField(i18n.T("network_ipv4_gateway"), cfg.IP.IPv4Gateway, 20, nil, nil)
+		form.AddInputField(i18n.T("network_ipv4_dns1"), dns(cfg.IP.IPv4DNS, 0), 20, nil, nil)
+		form.AddInputField(i18n.T("network_ipv4_dns2"), dns(cfg.IP.IPv4DNS, 1), 20, nil, nil)
+		form.AddDropDown(i18n.T("network_ipv6_mode"), ipv6Modes, indexOf(ipv6Values, cfg.IP.IPv6Mode), nil)
+		form.AddInputField(i18n.T("network_ipv6_address"), cfg.IP.IPv6Address, 40, nil, nil)
+		form.AddInputField(i18n.T("network_ipv6_prefix"), cfg.IP.IPv6Prefix, 20, nil, nil)
+		form.AddInputField(i18n.T("network_ipv6_gateway"), cfg.IP.IPv6Gateway, 40, nil, nil)
+		form.AddInputField(i18n.T("network_ipv6_dns1"), dns(cfg.IP.IPv6DNS, 0), 40, nil, nil)
+
+		text := func(label string) string {
+			return strings.TrimSpace(form.GetFormItemByLabel(i18n.T(label)).(*tview.InputField).GetText())
+		}
+		read := func() {
+			index, _ := form.GetFormItemByLabel(i18n.T("network_ipv4_mode")).(*tview.DropDown).GetCurrentOption()
+			cfg.IP.IPv4Mode = "auto"
+			if index == 1 {
+				cfg.IP.IPv4Mode = "manual"
+			}
+			index, _ = form.GetFormItemByLabel(i18n.T("network_ipv6_mode")).(*tview.DropDown).GetCurrentOption()
+			cfg.IP.IPv6Mode = ipv6Values[index]
+
+			cfg.IP.IPv4Address = text("network_ipv4_address")
+			cfg.IP.IPv4Netmask = text("network_ipv4_netmask")
+			cfg.IP.IPv4Gateway = text("network_ipv4_gateway")
+			cfg.IP.IPv4DNS = []string{text("network_ipv4_dns1"), text("network_ipv4_dns2")}
+			cfg.IP.IPv6Address = text("network_ipv6_address")
+			cfg.IP.IPv6Prefix = text("network_ipv6_prefix")
+			cfg.IP.IPv6Gateway = text("network_ipv6_gateway")
+			cfg.IP.IPv6DNS = []string{text("network_ipv6_dns1")}
+		}
+
+		form.AddButton(i18n.T("network_back"), func() {
+			read()
+			optionsStep()
+		})
+		form.AddButton(i18n.T("virtual_create"), func() {
+			read()
+			profiles, err := CreateVirtualInterface(cfg)
+			RecordVirtualInterfaces([]VirtualInterfaceConfig{cfg}, profiles, "tui", err)
+			if err != nil {
+				showMessage(app, i18n.T("error_title"), err.Error())
+				return
+			}
+			showMessage(app, i18n.T("success_title"),
+				fmt.Sprintf(i18n.T("virtual_created"), cfg.Name, strings.Join(profiles, ", ")))
+		})
+		showWizardStep(app, form, fmt.Sprintf(i18n.T("virtual_ip_help"), cfg.Name))
+	}
+
+	kindStep()
+}
+
+// showWizardStep mostra uma etapa do assistente com o texto de ajuda abaixo
+func showWizardStep(app *tview.Application, form *tview.Form, help string) {
+	helpText := tview.NewTextView()
+	helpText.SetTextAlign(tview.AlignCenter)
+	helpText.SetDynamicColors(true)
+	if help != "" {
+		help += " "
+	}
+	helpText.SetText("[yellow]" + help + i18n.T("press_esc_return") + "[white]")
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(form, 0, 1, true).
+		AddItem(helpText, 2, 0, false)
+	app.SetRoot(flex, true).SetFocus(form)
+}
+
+// suggestedVirtualName sugere um nome para a interface do tipo escolhido
+func suggestedVirtualName(cfg VirtualInterfaceConfig) string {
+	switch cfg.Kind {
+	case VirtualVLAN:
+		return fmt.Sprintf("%s.%d", cfg.Parent, cfg.VLANID)
+	case VirtualBridge:
+		return "br0"
+	default:
+		return cfg.Kind + "0"
+	}
+}
+
+// indexOf retorna a posição de value em list, ou 0 se não estiver presente
+func indexOf(list []string, value string) int {
+	for i, item := range list {
+		if item == value {
+			return i
+		}
+	}
+	return 0
+}
+
+// contains informa se value está em list
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,619 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"networkmanager-tui/backend"
+	"networkmanager-tui/history"
+	"networkmanager-tui/i18n"
+	"networkmanager-tui/logger"
+)
+
+// VPNTunnel resume um perfil de VPN e o estado do túnel
+type VPNTunnel struct {
+	Name            string               `json:"name" yaml:"name"`
+	UUID            string               `json:"uuid" yaml:"uuid"`
+	Kind            string               `json:"kind" yaml:"kind"` // wireguard, openvpn ou vpn (outros plugins)
+	Device          string               `json:"device" yaml:"device"`
+	Active          bool                 `json:"active" yaml:"active"`
+	Remote          string               `json:"remote" yaml:"remote"`                     // Servidor OpenVPN ou endpoint do primeiro peer
+	LatestHandshake time.Time            `json:"latest_handshake" yaml:"latest_handshake"` // WireGuard: handshake mais recente entre os peers
+	Peers           []backend.PeerStatus `json:"peers" yaml:"peers"`                       // WireGuard: estado dos peers (túnel ativo)
+}
+
+// VPNTunnels lista os perfis de VPN com o estado de cada túnel. Para túneis
+// WireGuard ativos, inclui o último handshake de cada peer.
+func VPNTunnels() ([]VPNTunnel, error) {
+	b := backend.Default()
+	profiles, err := b.Profiles()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar perfis: %w", err)
+	}
+
+	tunnels := []VPNTunnel{}
+	for _, p := range profiles {
+		if p.Type != backend.VPNWireGuard && p.Type != "vpn" {
+			continue
+		}
+		// A lista não traz as propriedades, necessárias para o tipo e o servidor
+		full, err := b.Profile(p.ID())
+		if err != nil {
+			logger.LogError("Erro ao ler perfil %s: %v", p.Name, err)
+			full = p
+		}
+		full.Active = p.Active
+		if full.Device == "" {
+			full.Device = p.Device
+		}
+
+		tunnel := VPNTunnel{
+			Name:   p.Name,
+			UUID:   p.UUID,
+			Kind:   backend.VPNKind(full),
+			Device: full.Device,
+			Active: full.Active,
+			Peers:  []backend.PeerStatus{},
+		}
+
+		switch tunnel.Kind {
+		case backend.VPNWireGuard:
+			if peers, err := backend.ParseWireGuardPeers(full.Settings["wireguard.peers"]); err == nil && len(peers) > 0 {
+				tunnel.Remote = peers[0].Endpoint
+			}
+			if v, ok := b.(backend.VPN); ok && tunnel.Active && tunnel.Device != "" {
+				if status, err := v.WireGuardStatus(tunnel.Device); err == nil {
+					tunnel.Peers = status
+					for _, peer := range status {
+						if peer.LatestHandshake.After(tunnel.LatestHandshake) {
+							tunnel.LatestHandshake = peer.LatestHandshake
+						}
+					}
+				}
+			}
+		default:
+			tunnel.Remote = vpnDataValue(full.Settings["vpn.data"], "remote")
+		}
+		tunnels = append(tunnels, tunnel)
+	}
+	return tunnels, nil
+}
+
+// vpnDataValue lê uma opção de vpn.data ("chave = valor, chave = valor")
+func vpnDataValue(data, key string) string {
+	for _, item := range strings.Split(data, ",") {
+		if k, v, ok := strings.Cut(item, "="); ok && strings.TrimSpace(k) == key {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// ImportVPN importa um arquivo WireGuard (.conf) ou OpenVPN (.ovpn) como
+// perfil de conexão. O nome do perfil é o nome do arquivo sem a extensão.
+func ImportVPN(path string) (backend.Profile, error) {
+	var kind string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".conf":
+		kind = backend.VPNWireGuard
+	case ".ovpn":
+		kind = backend.VPNOpenVPN
+	default:
+		return backend.Profile{}, fmt.Errorf("%w: use um arquivo WireGuard (.conf) ou OpenVPN (.ovpn): %s", ErrInvalidConfig, path)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return backend.Profile{}, fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if kind == backend.VPNWireGuard && !ifaceNameRegex.MatchString(name) {
+		// O nome do arquivo vira o nome da interface WireGuard
+		return backend.Profile{}, fmt.Errorf("%w: nome de interface inválido: %q (renomeie o arquivo, ex.: wg0.conf)", ErrInvalidConfig, name)
+	}
+
+	v, ok := backend.Default().(backend.VPN)
+	if !ok {
+		return backend.Profile{}, fmt.Errorf("importação de VPN: %w", backend.ErrNotSupported)
+	}
+	p, err := v.ImportVPN(kind, path)
+	if err != nil {
+		return backend.Profile{}, err
+	}
+	logger.LogInfo("VPN %s importada de %s (%s)", p.Name, path, kind)
+	return p, nil
+}
+
+// WireGuardPeers retorna os peers do perfil WireGuard
+func WireGuardPeers(id string) ([]backend.WireGuardPeer, error) {
+	p, err := backend.Default().Profile(id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Type != backend.VPNWireGuard {
+		return nil, fmt.Errorf("%w: o perfil %s não é WireGuard", ErrInvalidConfig, p.Name)
+	}
+	return backend.ParseWireGuardPeers(p.Settings["wireguard.peers"])
+}
+
+// presharedKeys lê as chaves compartilhadas dos peers do perfil, pela chave
+// pública de cada peer
+func presharedKeys(id string) (map[string]string, error) {
+	withSecrets, err := backend.ProfileWithSecrets(backend.Default(), id)
+	if err != nil {
+		return nil, err
+	}
+	current, err := backend.ParseWireGuardPeers(withSecrets.Settings["wireguard.peers"])
+	if err != nil {
+		return nil, err
+	}
+	psk := map[string]string{}
+	for _, peer := range current {
+		psk[peer.PublicKey] = peer.PresharedKey
+	}
+	return psk, nil
+}
+
+// SetWireGuardPeers valida e substitui os peers do perfil WireGuard. Peers
+// sem chave compartilhada mantêm a atual. Se o túnel estiver ativo, ele é
+// reativado para aplicar os peers.
+func SetWireGuardPeers(id string, peers []backend.WireGuardPeer) error {
+	if _, err := WireGuardPeers(id); err != nil {
+		return err
+	}
+	psk, err := presharedKeys(id)
+	if err != nil {
+		return err
+	}
+
+	seen := map[string]bool{}
+	for i := range peers {
+		if err := validatePeer(peers[i]); err != nil {
+			return err
+		}
+		if seen[peers[i].PublicKey] {
+			return fmt.Errorf("%w: peer repetido: %s", ErrInvalidConfig, peers[i].PublicKey)
+		}
+		seen[peers[i].PublicKey] = true
+		if peers[i].PresharedKey == "" {
+			peers[i].PresharedKey = psk[peers[i].PublicKey]
+		}
+	}
+
+	b := backend.Default()
+	p, err := b.Profile(id)
+	if err != nil {
+		return err
+	}
+	if err := b.ModifyProfile(id, backend.Settings{"wireguard.peers": backend.FormatWireGuardPeers(peers)}); err != nil {
+		return err
+	}
+	if p.Active {
+		if err := b.Activate(id); err != nil {
+			return fmt.Errorf("peers salvos, mas erro ao reativar %s: %w", p.Name, err)
+		}
+	}
+	logger.LogInfo("Peers do túnel %s alterados (%d peers)", p.Name, len(peers))
+	return nil
+}
+
+// validatePeer verifica chave, allowed-ips, endpoint e keepalive de um peer
+func validatePeer(peer backend.WireGuardPeer) error {
+	if !backend.ValidWireGuardKey(peer.PublicKey) {
+		return fmt.Errorf("%w: chave pública inválida: %s", ErrInvalidConfig, peer.PublicKey)
+	}
+	if peer.PresharedKey != "" && !backend.ValidWireGuardKey(peer.PresharedKey) {
+		return fmt.Errorf("%w: chave compartilhada inválida no peer %s", ErrInvalidConfig, peer.PublicKey)
+	}
+	if len(peer.AllowedIPs) == 0 {
+		return fmt.Errorf("%w: o peer %s precisa de allowed-ips", ErrInvalidConfig, peer.PublicKey)
+	}
+	for _, ip := range peer.AllowedIPs {
+		if !validCIDR(ip, false) && !validCIDR(ip, true) {
+			return fmt.Errorf("%w: allowed-ips inválido: %s", ErrInvalidConfig, ip)
+		}
+	}
+	if peer.Endpoint != "" {
+		host, port, err := net.SplitHostPort(peer.Endpoint)
+		if n, perr := strconv.Atoi(port); err != nil || host == "" || perr != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("%w: endpoint inválido (use host:porta): %s", ErrInvalidConfig, peer.Endpoint)
+		}
+	}
+	if peer.PersistentKeepalive < 0 || peer.PersistentKeepalive > 65535 {
+		return fmt.Errorf("%w: persistent-keepalive inválido: %d", ErrInvalidConfig, peer.PersistentKeepalive)
+	}
+	return nil
+}
+
+// SetVPNActive conecta (up) ou desconecta o túnel
+func SetVPNActive(id string, up bool) error {
+	b := backend.Default()
+	if up {
+		if err := b.Activate(id); err != nil {
+			return err
+		}
+		logger.LogInfo("VPN %s conectada", id)
+		return nil
+	}
+	if err := b.Deactivate(id); err != nil {
+		return err
+	}
+	logger.LogInfo("VPN %s desconectada", id)
+	return nil
+}
+
+// RecordVPNActive registra no histórico a ativação (up) ou desativação do
+// túnel, com o estado da conexão antes e depois
+func RecordVPNActive(name, profile string, up bool, via string, err error) {
+	action, before, after := "vpn_up", "inactive", "active"
+	if !up {
+		action, before, after = "vpn_down", "active", "inactive"
+	}
+	if err != nil {
+		after = before
+	}
+	history.Record(history.Action{
+		Action:    action,
+		Interface: name,
+		Profile:   profile,
+		Details:   fmt.Sprintf("VPN %s (%s)", name, outcomeText(err)),
+		Diff: history.Diff(backend.Settings{"connection.state": before},
+			backend.Settings{"connection.state": after}),
+		ModifiedBy: via,
+	}.WithResult(err))
+}
+
+// RecordVPNImport registra no histórico a importação do arquivo, com as
+// propriedades do perfil criado
+func RecordVPNImport(path string, p backend.Profile, via string, err error) {
+	history.Record(history.Action{
+		Action:     "vpn_import",
+		Interface:  p.Device,
+		Profile:    p.ID(),
+		Details:    fmt.Sprintf("%s (%s)", path, outcomeText(err)),
+		Diff:       history.Diff(backend.Settings{}, p.Settings),
+		ModifiedBy: via,
+	}.WithResult(err))
+}
+
+// RecordVPNPeers registra no histórico a troca dos peers do túnel, com os
+// peers de before e os lidos do backend depois da troca (chaves
+// compartilhadas mascaradas)
+func RecordVPNPeers(before backend.Profile, count int, via string, err error) {
+	after := backend.Settings{}
+	if p, perr := backend.Default().Profile(before.ID()); perr == nil {
+		after = p.Settings
+	}
+	diff := history.Diff(before.Settings, after)
+	action := history.Action{
+		Action:     "vpn_peers",
+		Interface:  before.Name,
+		Profile:    before.ID(),
+		Details:    fmt.Sprintf("VPN %s: %d peers (%s)", before.Name, count, outcomeText(err)),
+		Diff:       diff,
+		ModifiedBy: via,
+	}.WithResult(err)
+	if err == nil {
+		action.Snapshot = history.Snapshot(before.Settings, diff)
+	}
+	history.Record(action)
+}
+
+// HandshakeAge descreve há quanto tempo ocorreu o handshake
+func HandshakeAge(t time.Time) string {
+	if t.IsZero() {
+		return i18n.T("vpn_never")
+	}
+	return fmt.Sprintf(i18n.T("vpn_ago"), time.Since(t).Round(time.Second))
+}
+
+// ShowVPN mostra os túneis VPN, com estado e último handshake atualizados
+// periodicamente, e as ações de conectar, desconectar, importar e editar peers
+func ShowVPN(app *tview.Application) {
+	table := tview.NewTable()
+	table.SetBorders(true)
+	table.SetBorderColor(borderColor)
+	table.SetTitle(" 🔐 " + i18n.T("vpn_title") + " 🔐 ")
+	table.SetTitleColor(titleColor)
+	table.SetTitleAlign(tview.AlignCenter)
+	table.SetSelectable(true, false)
+	table.SetFixed(1, 0)
+	table.SetBackgroundColor(backgroundColor)
+
+	headers := []string{
+		i18n.T("network_name"),
+		i18n.T("network_type"),
+		i18n.T("network_device"),
+		i18n.T("network_state"),
+		i18n.T("vpn_remote"),
+		i18n.T("vpn_handshake"),
+	}
+	for col, header := range headers {
+		table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(headerColor).
+			SetAlign(tview.AlignCenter).
+			SetSelectable(false))
+	}
+
+	var tunnels []VPNTunnel
+	refresh := func() {
+		var err error
+		tunnels, err = VPNTunnels()
+		fillVPNTable(table, tunnels, err)
+	}
+	refresh()
+
+	selected := func() (VPNTunnel, bool) {
+		row, _ := table.GetSelection()
+		if row < 1 || row > len(tunnels) {
+			return VPNTunnel{}, false
+		}
+		return tunnels[row-1], true
+	}
+
+	var flex *tview.Flex
+	back := func() {
+		refresh()
+		watchVPNStatus(app, table, &tunnels)
+		app.SetRoot(flex, true).SetFocus(table)
+	}
+
+	buttons := tview.NewForm()
+	buttons.SetBackgroundColor(backgroundColor)
+	buttons.SetButtonBackgroundColor(buttonBgColor)
+	buttons.SetButtonTextColor(buttonTextColor)
+
+	buttons.AddButton(i18n.T("vpn_toggle"), func() {
+		tunnel, ok := selected()
+		if !ok {
+			return
+		}
+		err := SetVPNActive(tunnel.UUID, !tunnel.Active)
+		RecordVPNActive(tunnel.Name, tunnel.UUID, !tunnel.Active, "tui", err)
+		if err != nil {
+			StopNetworkStatus()
+			showMessage(app, i18n.T("error_title"), err.Error())
+			return
+		}
+		refresh()
+		app.SetFocus(table)
+	})
+	buttons.AddButton(i18n.T("vpn_peers"), func() {
+		tunnel, ok := selected()
+		if !ok || tunnel.Kind != backend.VPNWireGuard {
+			return
+		}
+		StopNetworkStatus()
+		showPeerEditor(app, tunnel, back)
+	})
+	buttons.AddButton(i18n.T("vpn_import"), func() {
+		StopNetworkStatus()
+		showVPNImport(app, back)
+	})
+	buttons.AddButton(i18n.T("network_back"), func() {
+		StopNetworkStatus()
+		app.Stop() // Retorna ao menu principal
+	})
+
+	helpText := tview.NewTextView()
+	helpText.SetTextAlign(tview.AlignCenter)
+	helpText.SetDynamicColors(true)
+	helpText.SetText("[green]● " + i18n.T("network_live_updates") + " [yellow]• Tab: " + i18n.T("vpn_actions") + " • " + i18n.T("press_esc_return") + "[white]")
+
+	flex = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(buttons, 3, 0, false).
+		AddItem(helpText, 1, 0, false)
+
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyTab && table.HasFocus():
+			app.SetFocus(buttons)
+			return nil
+		case event.Key() == tcell.KeyBacktab && buttons.HasFocus():
+			app.SetFocus(table)
+			return nil
+		}
+		return event
+	})
+
+	watchVPNStatus(app, table, &tunnels)
+	app.SetRoot(flex, true).SetFocus(table)
+}
+
+// fillVPNTable substitui as linhas de dados da tabela de túneis
+func fillVPNTable(table *tview.Table, tunnels []VPNTunnel, err error) {
+	row, _ := table.GetSelection()
+	for r := table.GetRowCount() - 1; r > 0; r-- {
+		table.RemoveRow(r)
+	}
+
+	if err != nil {
+		table.SetCell(1, 0, tview.NewTableCell(err.Error()).
+			SetTextColor(errorColor).
+			SetSelectable(false))
+		return
+	}
+	if len(tunnels) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell(i18n.T("vpn_empty")).
+			SetTextColor(infoColor).
+			SetSelectable(false))
+		return
+	}
+
+	for i, tunnel := range tunnels {
+		state, stateColor := i18n.T("vpn_inactive"), errorColor
+		if tunnel.Active {
+			state, stateColor = i18n.T("vpn_active"), successColor
+		}
+		handshake := "-"
+		if tunnel.Kind == backend.VPNWireGuard && tunnel.Active {
+			handshake = HandshakeAge(tunnel.LatestHandshake)
+		}
+
+		cells := []struct {
+			text  string
+			color tcell.Color
+		}{
+			{tunnel.Name, fieldTextColor},
+			{tunnel.Kind, fieldTextColor},
+			{tunnel.Device, fieldTextColor},
+			{state, stateColor},
+			{tunnel.Remote, fieldTextColor},
+			{handshake, fieldTextColor},
+		}
+		for col, cell := range cells {
+			table.SetCell(i+1, col, tview.NewTableCell(cell.text).SetTextColor(cell.color))
+		}
+	}
+
+	if row < 1 || row > len(tunnels) {
+		row = 1
+	}
+	table.Select(row, 0)
+}
+
+// watchVPNStatus recarrega a tabela de túneis periodicamente, para mostrar o
+// handshake atualizado. Usa a mesma parada da tela de status, de modo que
+// StopNetworkStatus também a interrompe.
+func watchVPNStatus(app *tview.Application, table *tview.Table, tunnels *[]VPNTunnel) {
+	StopNetworkStatus()
+
+	stop := make(chan struct{})
+	statusMu.Lock()
+	statusStop = stop
+	statusMu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(statusPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+
+			list, err := VPNTunnels()
+			app.QueueUpdateDraw(func() {
+				select {
+				case <-stop:
+				default:
+					if err == nil {
+						*tunnels = list
+					}
+					fillVPNTable(table, list, err)
+				}
+			})
+		}
+	}()
+}
+
+// showPeerEditor edita os peers de um túnel WireGuard, um por linha, e mostra
+// o estado de cada peer no túnel ativo
+func showPeerEditor(app *tview.Application, tunnel VPNTunnel, back func()) {
+	peers, err := WireGuardPeers(tunnel.UUID)
+	if err != nil {
+		showMessage(app, i18n.T("error_title"), err.Error())
+		return
+	}
+
+	// As chaves compartilhadas não são exibidas; peers sem a chave mantêm a atual
+	lines := make([]string, len(peers))
+	for i, peer := range peers {
+		peer.PresharedKey = ""
+		lines[i] = backend.FormatWireGuardPeers([]backend.WireGuardPeer{peer})
+	}
+
+	form := newForm(fmt.Sprintf("%s: %s", i18n.T("vpn_peers"), tunnel.Name))
+	form.AddTextArea(i18n.T("vpn_peers"), strings.Join(lines, "\n"), 0, 8, 0, nil)
+	form.AddButton(i18n.T("network_save"), func() {
+		var edited []backend.WireGuardPeer
+		for _, line := range splitLines(form.GetFormItem(0).(*tview.TextArea).GetText()) {
+			parsed, err := backend.ParseWireGuardPeers(line)
+			if err != nil {
+				showMessage(app, i18n.T("error_title"), fmt.Sprintf("%v: %v", ErrInvalidConfig, err))
+				return
+			}
+			edited = append(edited, parsed...)
+		}
+
+		before, err := backend.Default().Profile(tunnel.UUID)
+		if err != nil {
+			showMessage(app, i18n.T("error_title"), err.Error())
+			return
+		}
+		err = SetWireGuardPeers(tunnel.UUID, edited)
+		RecordVPNPeers(before, len(edited), "tui", err)
+		if err != nil {
+			showMessage(app, i18n.T("error_title"), err.Error())
+			return
+		}
+		back()
+	})
+	form.AddButton(i18n.T("network_cancel"), back)
+
+	// Estado dos peers no túnel ativo
+	status := tview.NewTextView()
+	status.SetDynamicColors(true)
+	status.SetBackgroundColor(backgroundColor)
+	var text strings.Builder
+	text.WriteString("[yellow]" + i18n.T("vpn_peer_help") + "[white]\n\n")
+	for _, peer := range tunnel.Peers {
+		fmt.Fprintf(&text, "[aqua]%s[white]  %s: %s  %s: %s  rx %d B / tx %d B\n",
+			peer.PublicKey, i18n.T("vpn_remote"), orNone(peer.Endpoint),
+			i18n.T("vpn_handshake"), HandshakeAge(peer.LatestHandshake), peer.RxBytes, peer.TxBytes)
+	}
+	status.SetText(text.String())
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(form, 0, 1, true).
+		AddItem(status, 0, 1, false)
+	app.SetRoot(flex, true).SetFocus(form)
+}
+
+// showVPNImport pede o caminho do arquivo a importar
+func showVPNImport(app *tview.Application, back func()) {
+	form := newForm(i18n.T("vpn_import"))
+	form.AddInputField(i18n.T("vpn_file"), "", 50, nil, nil)
+	form.GetFormItem(0).(*tview.InputField).SetPlaceholder("/etc/wireguard/wg0.conf")
+
+	form.AddButton(i18n.T("vpn_import"), func() {
+		path := strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
+		p, err := ImportVPN(path)
+		RecordVPNImport(path, p, "tui", err)
+		if err != nil {
+			showMessage(app, i18n.T("error_title"), err.Error())
+			return
+		}
+		back()
+	})
+	form.AddButton(i18n.T("network_cancel"), back)
+
+	helpText := tview.NewTextView()
+	helpText.SetDynamicColors(true)
+	helpText.SetText("[yellow]" + i18n.T("vpn_import_help") + "[white]")
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(form, 0, 1, true).
+		AddItem(helpText, 2, 0, false)
+	app.SetRoot(flex, true).SetFocus(form)
+}
+
+// outcomeText descreve o resultado de uma ação para o histórico
+func outcomeText(err error) string {
+	if err != nil {
+		return "falha: " + err.Error()
+	}
+	return "sucesso"
+}
@@ -0,0 +1,627 @@
+package network
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"networkmanager-tui/backend"
+	"networkmanager-tui/history"
+	"networkmanager-tui/i18n"
+)
+
+// WiFiCredentials descreve a segurança usada na conexão a uma rede Wi-Fi
+type WiFiCredentials struct {
+	Security string    // backend.Security*; vazio deduz: 802.1X com EAP, WPA2-PSK com senha ou aberta
+	Password string    // Senha da rede (WEP, WPA2-PSK ou WPA3-SAE)
+	EAP      EAPConfig // Autenticação do WPA-Enterprise
+}
+
+// settings valida as credenciais e as converte nas propriedades de segurança
+// do perfil
+func (c WiFiCredentials) settings() (backend.Settings, error) {
+	security := c.Security
+	if security == "" {
+		switch {
+		case c.EAP.Method != "":
+			security = backend.SecurityEAP
+		case c.Password != "":
+			security = backend.SecurityPSK
+		default:
+			security = backend.SecurityOpen
+		}
+	}
+
+	switch security {
+	case backend.SecurityEAP:
+		if c.EAP.Method == "" || c.EAP.Method == EAPNone {
+			return nil, fmt.Errorf("%w: o WPA-Enterprise exige o método EAP", ErrInvalidConfig)
+		}
+		eap, err := c.EAP.Settings()
+		if err != nil {
+			return nil, err
+		}
+		settings := backend.WiFiSecurity(security, "")
+		for key, value := range eap {
+			settings[key] = value
+		}
+		return settings, nil
+	case backend.SecurityPSK, backend.SecuritySAE:
+		if c.Password != "" && (len(c.Password) < 8 || len(c.Password) > 64) {
+			return nil, fmt.Errorf("%w: a senha WPA deve ter de 8 a 63 caracteres (ou 64 hexadecimais)", ErrInvalidConfig)
+		}
+	case backend.SecurityOpen, backend.SecurityWEP:
+	default:
+		return nil, fmt.Errorf("%w: tipo de segurança desconhecido: %s", ErrInvalidConfig, security)
+	}
+	return backend.WiFiSecurity(security, c.Password), nil
+}
+
+// Tamanho máximo do SSID, em bytes
+const maxSSIDLength = 32
+
+// Tipos de segurança oferecidos na conexão a redes ocultas, o mais comum
+// primeiro
+var WiFiSecurityTypes = []string{
+	backend.SecurityPSK, backend.SecuritySAE, backend.SecurityEAP, backend.SecurityWEP, backend.SecurityOpen,
+}
+
+// ConnectWiFi conecta à rede Wi-Fi informada, reaproveitando o perfil salvo
+// da rede ou criando um novo com conexão automática. Se device estiver vazio,
+// usa o primeiro dispositivo Wi-Fi encontrado.
+func ConnectWiFi(ssid, device string, cred WiFiCredentials) error {
+	return connectWiFi(ssid, device, cred, false)
+}
+
+// ConnectHiddenWiFi conecta a uma rede que não anuncia o SSID, digitado pelo
+// usuário. O perfil fica salvo com 802-11-wireless.hidden=yes, para que o
+// dispositivo procure a rede pelo nome.
+func ConnectHiddenWiFi(ssid, device string, cred WiFiCredentials) error {
+	return connectWiFi(ssid, device, cred, true)
+}
+
+// connectWiFi valida o SSID e as credenciais e conecta à rede
+func connectWiFi(ssid, device string, cred WiFiCredentials, hidden bool) error {
+	if ssid == "" {
+		return fmt.Errorf("%w: SSID não informado", ErrInvalidConfig)
+	}
+	if len(ssid) > maxSSIDLength {
+		return fmt.Errorf("%w: o SSID deve ter no máximo %d bytes: %s", ErrInvalidConfig, maxSSIDLength, ssid)
+	}
+	security, err := cred.settings()
+	if err != nil {
+		return err
+	}
+	if hidden {
+		security["802-11-wireless.hidden"] = "yes"
+	}
+	_, err = backend.ConnectWiFi(backend.Default(), device, ssid, security)
+	return err
+}
+
+// WiFiConnection retorna o dispositivo Wi-Fi usado na conexão (o informado ou
+// o primeiro encontrado) e o perfil ativo nele
+func WiFiConnection(device string) (string, string) {
+	b := backend.Default()
+	if device == "" {
+		var err error
+		if device, err = backend.WiFiDevice(b); err != nil {
+			return "", ""
+		}
+	}
+	dev, err := findDevice(b, device)
+	if err != nil {
+		return device, ""
+	}
+	return dev.Name, dev.Connection
+}
+
+// RecordWiFiConnect registra no histórico a conexão à rede ssid, com o perfil
+// ativo no dispositivo antes (before) e depois da conexão. As senhas nunca
+// são registradas.
+func RecordWiFiConnect(ssid, device, before, details, via string, err error) {
+	_, after := WiFiConnection(device)
+	profile := ssid
+	if err == nil && after != "" {
+		profile = after
+	}
+	history.Record(history.Action{
+		Action:    "wifi_connect",
+		Interface: device,
+		Profile:   profile,
+		Details:   fmt.Sprintf("%s (%s)", details, outcomeText(err)),
+		Diff: history.Diff(backend.Settings{"connection.active": before},
+			backend.Settings{"connection.active": after}),
+		ModifiedBy: via,
+	}.WithResult(err))
+}
+
+// WiFiNetwork agrupa os pontos de acesso que anunciam o mesmo SSID. Os campos
+// do ponto de acesso embutido são os do melhor sinal.
+type WiFiNetwork struct {
+	backend.AccessPoint `yaml:",inline"`
+	AccessPoints        []backend.AccessPoint `json:"access_points" yaml:"access_points"` // Do sinal mais forte para o mais fraco
+}
+
+// Bands lista as faixas de frequência em que a rede é anunciada
+func (n WiFiNetwork) Bands() string {
+	var bands []string
+	for _, ap := range n.AccessPoints {
+		if band := ap.Band(); band != "" && !contains(bands, band) {
+			bands = append(bands, band)
+		}
+	}
+	sort.Strings(bands)
+	return strings.Join(bands, ", ")
+}
+
+// Ordens aceitas pela listagem das redes Wi-Fi
+const (
+	WiFiSortSignal  = "signal"
+	WiFiSortChannel = "channel"
+	WiFiSortSSID    = "ssid"
+)
+
+// WiFiSortModes lista as ordens na sequência em que a tela alterna entre elas
+var WiFiSortModes = []string{WiFiSortSignal, WiFiSortChannel, WiFiSortSSID}
+
+// Intervalo entre as varreduras automáticas da tela de redes Wi-Fi
+const wifiRescanInterval = 30 * time.Second
+
+// ScanWiFi lista as redes Wi-Fi visíveis, uma por SSID, da mais forte para a
+// mais fraca. Com rescan, pede antes uma nova varredura quando o backend
+// permite.
+func ScanWiFi(rescan bool) ([]WiFiNetwork, error) {
+	b := backend.Default()
+	if w, ok := b.(backend.WiFi); ok && rescan {
+		if err := w.Rescan(""); err != nil {
+			return nil, err
+		}
+	}
+	aps, err := b.Scan()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(aps, func(i, j int) bool {
+		return aps[i].Signal > aps[j].Signal
+	})
+
+	index := map[string]int{}
+	var networks []WiFiNetwork
+	for _, ap := range aps {
+		if ap.SSID == "" {
+			continue
+		}
+		if i, ok := index[ap.SSID]; ok {
+			networks[i].AccessPoints = append(networks[i].AccessPoints, ap)
+			continue
+		}
+		index[ap.SSID] = len(networks)
+		networks = append(networks, WiFiNetwork{AccessPoint: ap, AccessPoints: []backend.AccessPoint{ap}})
+	}
+	return networks, nil
+}
+
+// SortWiFiNetworks ordena as redes pelo sinal (mais forte primeiro), pelo
+// canal (na ordem das frequências, de 2.4 a 6 GHz) ou pelo SSID
+func SortWiFiNetworks(networks []WiFiNetwork, by string) error {
+	var less func(a, b WiFiNetwork) bool
+	switch by {
+	case WiFiSortSignal:
+		less = func(a, b WiFiNetwork) bool { return a.Signal > b.Signal }
+	case WiFiSortChannel:
+		less = func(a, b WiFiNetwork) bool {
+			if a.Frequency != b.Frequency {
+				return a.Frequency < b.Frequency
+			}
+			if a.Channel != b.Channel {
+				return a.Channel < b.Channel
+			}
+			return a.Signal > b.Signal
+		}
+	case WiFiSortSSID:
+		less = func(a, b WiFiNetwork) bool { return strings.ToLower(a.SSID) < strings.ToLower(b.SSID) }
+	default:
+		return fmt.Errorf("%w: ordem desconhecida: %s (use signal, channel ou ssid)", ErrInvalidConfig, by)
+	}
+	sort.SliceStable(networks, func(i, j int) bool {
+		return less(networks[i], networks[j])
+	})
+	return nil
+}
+
+// savedEAPConfig retorna a autenticação 802.1X do perfil salvo da rede, para
+// preencher o formulário
+func savedEAPConfig(ssid string) EAPConfig {
+	p, err := backend.SavedWiFiProfile(backend.Default(), ssid)
+	if err != nil {
+		return EAPConfig{}
+	}
+	return eapConfigFromSettings(p.Settings)
+}
+
+// ShowWiFi mostra as redes Wi-Fi visíveis, com sinal, canal, faixa e
+// segurança, e conecta à rede escolhida, pedindo a senha ou a autenticação
+// 802.1X. A lista é atualizada por novas varreduras periódicas.
+func ShowWiFi(app *tview.Application) {
+	table := newWiFiTable(i18n.T("wifi_title"), []string{
+		i18n.T("wifi_ssid"), i18n.T("wifi_signal"), "dBm", i18n.T("wifi_channel"),
+		i18n.T("wifi_band"), i18n.T("wifi_security"), i18n.T("wifi_bssids"),
+	})
+
+	var networks []WiFiNetwork
+	sortBy := WiFiSortSignal
+	refresh := func(rescan bool) {
+		list, err := ScanWiFi(rescan)
+		if err == nil {
+			SortWiFiNetworks(list, sortBy)
+			networks = list
+		}
+		fillWiFiTable(table, list, err)
+	}
+	refresh(false)
+
+	selected := func() (WiFiNetwork, bool) {
+		row, _ := table.GetSelection()
+		if row < 1 || row > len(networks) {
+			return WiFiNetwork{}, false
+		}
+		return networks[row-1], true
+	}
+
+	var flex *tview.Flex
+	back := func() {
+		refresh(false)
+		watchWiFiScan(app, table, &networks, &sortBy)
+		app.SetRoot(flex, true).SetFocus(table)
+	}
+
+	connect := func(ssid string, hidden bool, cred WiFiCredentials) {
+		var err error
+		details := "SSID " + ssid
+		device, before := WiFiConnection("")
+		if hidden {
+			err = ConnectHiddenWiFi(ssid, "", cred)
+			details += ", rede oculta"
+		} else {
+			err = ConnectWiFi(ssid, "", cred)
+		}
+		RecordWiFiConnect(ssid, device, before, details, "tui", err)
+		if err != nil {
+			showMessage(app, i18n.T("error_title"), err.Error())
+			return
+		}
+		showMessage(app, i18n.T("success_title"), fmt.Sprintf("%s %s", i18n.T("cli_wifi_connected"), ssid))
+	}
+
+	buttons := tview.NewForm()
+	buttons.SetBackgroundColor(backgroundColor)
+	buttons.SetButtonBackgroundColor(buttonBgColor)
+	buttons.SetButtonTextColor(buttonTextColor)
+
+	buttons.AddButton(i18n.T("wifi_connect"), func() {
+		ap, ok := selected()
+		if !ok {
+			return
+		}
+		StopNetworkStatus()
+		switch ap.Security {
+		case backend.SecurityOpen:
+			connect(ap.SSID, false, WiFiCredentials{Security: ap.Security})
+		case backend.SecurityEAP:
+			eap := savedEAPConfig(ap.SSID)
+			showEAPForm(app, ap.SSID+" (802.1X)", &eap, false, func() {
+				connect(ap.SSID, false, WiFiCredentials{Security: ap.Security, EAP: eap})
+			}, back)
+		default:
+			showWiFiPassword(app, ap.AccessPoint, func(password string) {
+				connect(ap.SSID, false, WiFiCredentials{Security: ap.Security, Password: password})
+			}, back)
+		}
+	})
+	buttons.AddButton(i18n.T("wifi_details"), func() {
+		network, ok := selected()
+		if !ok {
+			return
+		}
+		StopNetworkStatus()
+		showAccessPoints(app, network, back)
+	})
+	buttons.AddButton(wifiSortLabel(sortBy), nil)
+	sortButton := buttons.GetButton(buttons.GetButtonCount() - 1)
+	sortButton.SetSelectedFunc(func() {
+		sortBy = WiFiSortModes[(indexOf(WiFiSortModes, sortBy)+1)%len(WiFiSortModes)]
+		sortButton.SetLabel(wifiSortLabel(sortBy))
+		SortWiFiNetworks(networks, sortBy)
+		fillWiFiTable(table, networks, nil)
+	})
+	buttons.AddButton(i18n.T("wifi_hidden"), func() {
+		StopNetworkStatus()
+		showHiddenWiFi(app, func(ssid string, cred WiFiCredentials) {
+			if cred.Security != backend.SecurityEAP {
+				connect(ssid, true, cred)
+				return
+			}
+			eap := savedEAPConfig(ssid)
+			showEAPForm(app, ssid+" (802.1X)", &eap, false, func() {
+				cred.EAP = eap
+				connect(ssid, true, cred)
+			}, back)
+		}, back)
+	})
+	buttons.AddButton(i18n.T("wifi_rescan"), func() {
+		refresh(true)
+		app.SetFocus(table)
+	})
+	buttons.AddButton(i18n.T("network_back"), func() {
+		StopNetworkStatus()
+		app.Stop() // Retorna ao menu principal
+	})
+
+	helpText := tview.NewTextView()
+	helpText.SetTextAlign(tview.AlignCenter)
+	helpText.SetDynamicColors(true)
+	helpText.SetText(fmt.Sprintf("[green]● %s [yellow]• Tab: %s • %s[white]",
+		fmt.Sprintf(i18n.T("wifi_auto_rescan"), int(wifiRescanInterval.Seconds())),
+		i18n.T("vpn_actions"), i18n.T("press_esc_return")))
+
+	flex = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(buttons, 3, 0, false).
+		AddItem(helpText, 1, 0, false)
+
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyTab && table.HasFocus():
+			app.SetFocus(buttons)
+			return nil
+		case event.Key() == tcell.KeyBacktab && buttons.HasFocus():
+			app.SetFocus(table)
+			return nil
+		}
+		return event
+	})
+
+	watchWiFiScan(app, table, &networks, &sortBy)
+	app.SetRoot(flex, true).SetFocus(table)
+}
+
+// watchWiFiScan pede novas varreduras periodicamente e recarrega a tabela de
+// redes até que StopNetworkStatus seja chamada
+func watchWiFiScan(app *tview.Application, table *tview.Table, networks *[]WiFiNetwork, sortBy *string) {
+	StopNetworkStatus()
+
+	stop := make(chan struct{})
+	statusMu.Lock()
+	statusStop = stop
+	statusMu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(wifiRescanInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+
+			// O NetworkManager recusa varreduras muito próximas; nesse caso a
+			// lista atual do backend é mostrada
+			list, err := ScanWiFi(true)
+			if err != nil {
+				list, err = ScanWiFi(false)
+			}
+			app.QueueUpdateDraw(func() {
+				select {
+				case <-stop:
+				default:
+					if err == nil {
+						SortWiFiNetworks(list, *sortBy)
+						*networks = list
+					}
+					fillWiFiTable(table, list, err)
+				}
+			})
+		}
+	}()
+}
+
+// wifiSortLabel retorna o texto do botão que alterna a ordem da lista
+func wifiSortLabel(sortBy string) string {
+	return fmt.Sprintf("%s: %s", i18n.T("wifi_sort"), i18n.T("wifi_sort_"+sortBy))
+}
+
+// newWiFiTable cria uma tabela no estilo das telas de rede com o cabeçalho dado
+func newWiFiTable(title string, headers []string) *tview.Table {
+	table := tview.NewTable()
+	table.SetBorders(true)
+	table.SetBorderColor(borderColor)
+	table.SetTitle(" 📶 " + title + " 📶 ")
+	table.SetTitleColor(titleColor)
+	table.SetTitleAlign(tview.AlignCenter)
+	table.SetSelectable(true, false)
+	table.SetFixed(1, 0)
+	table.SetBackgroundColor(backgroundColor)
+
+	for col, header := range headers {
+		table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(headerColor).
+			SetAlign(tview.AlignCenter).
+			SetSelectable(false))
+	}
+	return table
+}
+
+// fillWiFiTable preenche a tabela com as redes encontradas, mantendo
+// selecionada a rede que já estava
+func fillWiFiTable(table *tview.Table, networks []WiFiNetwork, err error) {
+	row, _ := table.GetSelection()
+	var current string
+	if cell := table.GetCell(row, 0); row > 0 && cell != nil {
+		current = cell.Text
+	}
+	for r := table.GetRowCount() - 1; r > 0; r-- {
+		table.RemoveRow(r)
+	}
+
+	if err != nil {
+		table.SetCell(1, 0, tview.NewTableCell(err.Error()).
+			SetTextColor(errorColor).
+			SetSelectable(false))
+		return
+	}
+	if len(networks) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell(i18n.T("wifi_empty")).
+			SetTextColor(infoColor).
+			SetSelectable(false))
+		return
+	}
+
+	selected := 1
+	for i, network := range networks {
+		if network.SSID == current {
+			selected = i + 1
+		}
+		table.SetCell(i+1, 0, tview.NewTableCell(network.SSID).SetTextColor(fieldTextColor))
+		table.SetCell(i+1, 1, tview.NewTableCell(fmt.Sprintf("%d%%", network.Signal)).SetTextColor(signalColor(network.Signal)))
+		table.SetCell(i+1, 2, tview.NewTableCell(strconv.Itoa(network.DBm)).SetTextColor(fieldTextColor).SetAlign(tview.AlignRight))
+		table.SetCell(i+1, 3, tview.NewTableCell(channelText(network.Channel)).SetTextColor(fieldTextColor).SetAlign(tview.AlignRight))
+		table.SetCell(i+1, 4, tview.NewTableCell(orDash(network.Bands())).SetTextColor(fieldTextColor))
+		table.SetCell(i+1, 5, tview.NewTableCell(backend.SecurityLabel(network.Security)).SetTextColor(securityColor(network.Security)))
+		table.SetCell(i+1, 6, tview.NewTableCell(strconv.Itoa(len(network.AccessPoints))).SetTextColor(fieldTextColor).SetAlign(tview.AlignRight))
+	}
+	table.Select(selected, 0)
+}
+
+// showAccessPoints mostra os pontos de acesso de uma rede, um por BSSID
+func showAccessPoints(app *tview.Application, network WiFiNetwork, back func()) {
+	table := newWiFiTable(network.SSID, []string{
+		i18n.T("wifi_bssid"), i18n.T("wifi_signal"), "dBm", i18n.T("wifi_channel"),
+		i18n.T("wifi_band"), i18n.T("wifi_frequency"), i18n.T("wifi_security"),
+	})
+	for i, ap := range network.AccessPoints {
+		frequency := "-"
+		if ap.Frequency > 0 {
+			frequency = fmt.Sprintf("%d MHz", ap.Frequency)
+		}
+		table.SetCell(i+1, 0, tview.NewTableCell(ap.BSSID).SetTextColor(fieldTextColor))
+		table.SetCell(i+1, 1, tview.NewTableCell(fmt.Sprintf("%d%%", ap.Signal)).SetTextColor(signalColor(ap.Signal)))
+		table.SetCell(i+1, 2, tview.NewTableCell(strconv.Itoa(ap.DBm)).SetTextColor(fieldTextColor).SetAlign(tview.AlignRight))
+		table.SetCell(i+1, 3, tview.NewTableCell(channelText(ap.Channel)).SetTextColor(fieldTextColor).SetAlign(tview.AlignRight))
+		table.SetCell(i+1, 4, tview.NewTableCell(orDash(ap.Band())).SetTextColor(fieldTextColor))
+		table.SetCell(i+1, 5, tview.NewTableCell(frequency).SetTextColor(fieldTextColor).SetAlign(tview.AlignRight))
+		table.SetCell(i+1, 6, tview.NewTableCell(backend.SecurityLabel(ap.Security)).SetTextColor(securityColor(ap.Security)))
+	}
+	table.Select(1, 0)
+
+	buttons := tview.NewForm()
+	buttons.SetBackgroundColor(backgroundColor)
+	buttons.SetButtonBackgroundColor(buttonBgColor)
+	buttons.SetButtonTextColor(buttonTextColor)
+	buttons.AddButton(i18n.T("network_back"), back)
+
+	table.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			back()
+		}
+	})
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(buttons, 3, 0, false)
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyTab && table.HasFocus():
+			app.SetFocus(buttons)
+			return nil
+		case event.Key() == tcell.KeyBacktab && buttons.HasFocus():
+			app.SetFocus(table)
+			return nil
+		}
+		return event
+	})
+
+	app.SetRoot(flex, true).SetFocus(table)
+}
+
+// signalColor destaca sinais fracos (abaixo de 40%) e médios (abaixo de 70%)
+func signalColor(signal int) tcell.Color {
+	switch {
+	case signal < 40:
+		return errorColor
+	case signal < 70:
+		return infoColor
+	}
+	return successColor
+}
+
+// securityColor destaca as redes abertas e as que usam WEP
+func securityColor(security string) tcell.Color {
+	if security == backend.SecurityOpen || security == backend.SecurityWEP {
+		return errorColor
+	}
+	return successColor
+}
+
+// channelText formata o canal, com traço quando desconhecido
+func channelText(channel int) string {
+	if channel == 0 {
+		return "-"
+	}
+	return strconv.Itoa(channel)
+}
+
+// orDash mostra um traço no lugar de valores desconhecidos
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// Pede a senha da rede (WEP, WPA2-PSK ou WPA3-SAE). Vazia, usa a senha do
+// perfil salvo.
+func showWiFiPassword(app *tview.Application, ap backend.AccessPoint, ok func(password string), cancel func()) {
+	form := newForm(fmt.Sprintf("%s (%s)", ap.SSID, backend.SecurityLabel(ap.Security)))
+	form.AddPasswordField(i18n.T("wifi_password"), "", 40, '*', nil)
+	form.AddButton(i18n.T("wifi_connect"), func() {
+		ok(form.GetFormItem(0).(*tview.InputField).GetText())
+	})
+	form.AddButton(i18n.T("network_cancel"), cancel)
+
+	showWizardStep(app, form, i18n.T("wifi_password_help"))
+}
+
+// Pede o SSID, o tipo de segurança e a senha de uma rede oculta. No 802.1X, a
+// senha é pedida depois, no formulário da autenticação.
+func showHiddenWiFi(app *tview.Application, ok func(ssid string, cred WiFiCredentials), cancel func()) {
+	form := newForm(i18n.T("wifi_hidden"))
+
+	labels := make([]string, len(WiFiSecurityTypes))
+	for i, security := range WiFiSecurityTypes {
+		labels[i] = backend.SecurityLabel(security)
+	}
+	form.AddInputField(i18n.T("wifi_ssid"), "", 32, nil, nil)
+	form.AddDropDown(i18n.T("wifi_security"), labels, 0, nil)
+	form.AddPasswordField(i18n.T("wifi_password"), "", 40, '*', nil)
+
+	form.AddButton(i18n.T("wifi_connect"), func() {
+		index, _ := form.GetFormItem(1).(*tview.DropDown).GetCurrentOption()
+		cred := WiFiCredentials{Security: WiFiSecurityTypes[index]}
+		if cred.Security != backend.SecurityEAP {
+			cred.Password = form.GetFormItem(2).(*tview.InputField).GetText()
+		}
+		ok(form.GetFormItem(0).(*tview.InputField).GetText(), cred)
+	})
+	form.AddButton(i18n.T("network_cancel"), cancel)
+
+	showWizardStep(app, form, i18n.T("wifi_hidden_help"))
+}
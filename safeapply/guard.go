@@ -0,0 +1,107 @@
+package safeapply
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+
+	"networkmanager-tui/backend"
+	"networkmanager-tui/forward"
+	"networkmanager-tui/logger"
+)
+
+// GuardCommand é o subcomando interno que executa a guarda em um processo
+// separado
+const GuardCommand = "rollback-guard"
+
+// startGuard inicia a guarda de restauração. O backend em memória do modo
+// -dev só existe neste processo, então nesse caso a guarda roda aqui mesmo;
+// nos demais, roda em um processo próprio, em nova sessão, para sobreviver à
+// queda do terminal ou da sessão SSH.
+func startGuard(b backend.Backend, path string, snap Snapshot) error {
+	if _, ok := b.(*backend.Fake); ok {
+		go watch(b, path, snap)
+		return nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("erro ao localizar o executável: %w", err)
+	}
+
+	cmd := exec.Command(exe, GuardCommand, path)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	// A guarda grava os logs no mesmo destino desta execução e encaminha as
+	// restaurações ao mesmo coletor
+	cmd.Env = append(append(os.Environ(), logger.Environ()...), forward.Environ()...)
+	if err := cmd.Start(); err != nil {
+		logger.Command(exe, cmd.Args[1:], 0, err)
+		return fmt.Errorf("erro ao iniciar guarda de restauração: %w", err)
+	}
+	logger.Debug("Comando iniciado", "cmd", exe, "args", strings.Join(cmd.Args[1:], " "), "pid", cmd.Process.Pid)
+	logger.LogInfo("Guarda de restauração iniciada (PID %d) para %s", cmd.Process.Pid, snap.Interface)
+	return cmd.Process.Release()
+}
+
+// Guard executa a guarda para o estado gravado em path: restaura as
+// configurações anteriores se o teste de alcance falhar ou se o prazo
+// terminar sem confirmação
+func Guard(b backend.Backend, path string) error {
+	snap, err := readSnapshot(path)
+	if err != nil {
+		return err
+	}
+	return watch(b, path, snap)
+}
+
+// watch acompanha o estado pendente até a confirmação ou a restauração
+func watch(b backend.Backend, path string, snap Snapshot) error {
+	if snap.CheckHost != "" {
+		time.Sleep(settleDelay)
+		if !exists(path) {
+			return nil
+		}
+		if !reachable(snap.CheckHost) {
+			logger.LogError("Teste de alcance para %s falhou após alterar %s", snap.CheckHost, snap.Interface)
+			return ignoreConfirmed(rollback(b, path, snap, "teste de alcance falhou: "+snap.CheckHost))
+		}
+	}
+
+	for time.Now().Before(snap.Deadline) {
+		if !exists(path) {
+			// Confirmada ou revertida por outro processo
+			return nil
+		}
+		time.Sleep(pollInterval)
+	}
+	return ignoreConfirmed(rollback(b, path, snap, "prazo de confirmação esgotado"))
+}
+
+// ignoreConfirmed trata como sucesso o caso em que a alteração foi
+// confirmada pouco antes da restauração
+func ignoreConfirmed(err error) error {
+	if err == ErrRolledBack {
+		return nil
+	}
+	return err
+}
+
+// reachable testa o host com ping
+func reachable(host string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	args := []string{"-c", "3", "-W", "2", host}
+	start := time.Now()
+	err := exec.CommandContext(ctx, "ping", args...).Run()
+	logger.Command("ping", args, time.Since(start), err)
+	return err == nil
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,341 @@
+// Package safeapply aplica alterações de rede no modo "commit confirmed": guarda
+// o estado anterior do perfil, aplica a mudança e restaura o estado anterior
+// automaticamente se o operador não confirmar dentro do prazo ou se o teste de
+// alcance falhar. A restauração é feita por um processo separado, para que
+// funcione mesmo se a sessão SSH da interface cair.
+package safeapply
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"networkmanager-tui/backend"
+	"networkmanager-tui/history"
+	"networkmanager-tui/logger"
+)
+
+// Prazo padrão para confirmar uma alteração
+const DefaultTimeout = 60 * time.Second
+
+// Diretório padrão dos estados pendentes de confirmação
+const DefaultStateDir = "/var/lib/networkmanager-tui/pending"
+
+// Tempo de espera após a ativação antes do teste de alcance
+const settleDelay = 5 * time.Second
+
+// Intervalo com que a guarda verifica se a alteração foi confirmada
+const pollInterval = time.Second
+
+var (
+	// ErrRolledBack indica que a alteração já foi revertida
+	ErrRolledBack = errors.New("a alteração já foi revertida")
+	// ErrNoPending indica que não há alterações aguardando confirmação
+	ErrNoPending = errors.New("nenhuma alteração pendente")
+)
+
+var (
+	dirMu    sync.RWMutex
+	stateDir = DefaultStateDir
+)
+
+// SetStateDir define o diretório dos estados pendentes (usado no modo -dev)
+func SetStateDir(dir string) {
+	dirMu.Lock()
+	defer dirMu.Unlock()
+	stateDir = dir
+}
+
+func currentStateDir() string {
+	dirMu.RLock()
+	defer dirMu.RUnlock()
+	return stateDir
+}
+
+// Options configura a aplicação segura
+type Options struct {
+	Timeout   time.Duration // Prazo para confirmação (padrão: DefaultTimeout)
+	CheckHost string        // Host testado com ping após a aplicação (vazio: sem teste)
+	Created   bool          // O perfil foi criado para esta alteração e é removido na restauração
+}
+
+// Snapshot guarda o estado anterior do perfil e o prazo de confirmação
+type Snapshot struct {
+	ProfileID string           `json:"profile_id"`
+	Interface string           `json:"interface"`
+	Previous  backend.Settings `json:"previous"`
+	Applied   backend.Settings `json:"applied"`
+	WasActive bool             `json:"was_active"`
+	Deadline  time.Time        `json:"deadline"`
+	CheckHost string           `json:"check_host,omitempty"`
+	Created   bool             `json:"created,omitempty"`
+}
+
+// Pending é uma alteração aplicada que aguarda confirmação
+type Pending struct {
+	Snapshot
+
+	b         backend.Backend
+	path      string
+	mu        sync.Mutex
+	confirmed bool
+}
+
+// Apply guarda o estado atual do perfil, inicia a guarda de restauração e
+// aplica as novas configurações. Se a aplicação falhar, o estado anterior é
+// restaurado na hora.
+func Apply(b backend.Backend, profileID, iface string, settings backend.Settings, opts Options) (*Pending, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+
+	// O estado anterior inclui os segredos, para que a restauração não apague
+	// senhas e chaves alteradas
+	current, err := backend.ProfileWithSecrets(b, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := Snapshot{
+		ProfileID: current.ID(),
+		Interface: iface,
+		Previous:  backend.Settings{},
+		Applied:   settings.Clone(),
+		WasActive: current.Active,
+		Deadline:  time.Now().Add(opts.Timeout),
+		CheckHost: opts.CheckHost,
+		Created:   opts.Created,
+	}
+	for key := range settings {
+		snap.Previous[key] = current.Settings[key]
+	}
+
+	path, err := writeSnapshot(snap)
+	if err != nil {
+		return nil, err
+	}
+	p := &Pending{Snapshot: snap, b: b, path: path}
+
+	// A guarda é iniciada antes da aplicação, para que a restauração aconteça
+	// mesmo se este processo morrer durante a ativação
+	if err := startGuard(b, path, snap); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	err = b.ModifyProfile(snap.ProfileID, settings)
+	if err == nil {
+		err = b.Activate(snap.ProfileID)
+	}
+	if err != nil {
+		if rbErr := p.Rollback(); rbErr != nil && rbErr != ErrRolledBack {
+			return nil, fmt.Errorf("erro ao aplicar: %v; erro ao restaurar: %w", err, rbErr)
+		}
+		return nil, fmt.Errorf("erro ao aplicar, configurações anteriores restauradas: %w", err)
+	}
+
+	logger.LogInfo("Alteração em %s aplicada, aguardando confirmação até %s",
+		iface, snap.Deadline.Format("15:04:05"))
+	return p, nil
+}
+
+// Remaining retorna o tempo restante para a confirmação
+func (p *Pending) Remaining() time.Duration {
+	remaining := time.Until(p.Deadline)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// RolledBack informa se a alteração foi revertida pela guarda
+func (p *Pending) RolledBack() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.confirmed {
+		return false
+	}
+	_, err := os.Stat(p.path)
+	return os.IsNotExist(err)
+}
+
+// Confirm mantém a alteração e encerra a guarda
+func (p *Pending) Confirm() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	claimed, err := claim(p.path)
+	if err != nil {
+		return err
+	}
+	p.confirmed = true
+	os.Remove(claimed)
+
+	logger.LogInfo("Alteração em %s confirmada", p.Interface)
+	return nil
+}
+
+// Rollback restaura o estado anterior imediatamente
+func (p *Pending) Rollback() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return rollback(p.b, p.path, p.Snapshot, "manual")
+}
+
+// Load carrega uma alteração pendente gravada em path
+func Load(b backend.Backend, path string) (*Pending, error) {
+	snap, err := readSnapshot(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Pending{Snapshot: snap, b: b, path: path}, nil
+}
+
+// List retorna as alterações que aguardam confirmação
+func List(b backend.Backend) ([]*Pending, error) {
+	files, err := filepath.Glob(filepath.Join(currentStateDir(), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var pending []*Pending
+	for _, file := range files {
+		p, err := Load(b, file)
+		if err != nil {
+			logger.LogError("Estado pendente inválido %s: %v", file, err)
+			continue
+		}
+		pending = append(pending, p)
+	}
+	return pending, nil
+}
+
+// Restore aplica as configurações anteriores do snapshot; perfis criados para
+// a alteração são removidos
+func Restore(b backend.Backend, snap Snapshot) error {
+	if snap.Created {
+		if err := b.DeleteProfile(snap.ProfileID); err != nil {
+			return fmt.Errorf("erro ao remover perfil criado: %w", err)
+		}
+		return nil
+	}
+	if err := b.ModifyProfile(snap.ProfileID, restorable(snap.Previous)); err != nil {
+		return fmt.Errorf("erro ao restaurar perfil: %w", err)
+	}
+	if snap.WasActive {
+		return b.Activate(snap.ProfileID)
+	}
+
+	// Se a ativação falhou, o perfil já está inativo
+	if current, err := b.Profile(snap.ProfileID); err == nil && !current.Active {
+		return nil
+	}
+	if err := b.Deactivate(snap.ProfileID); err != nil && !errors.Is(err, backend.ErrNotActive) {
+		return fmt.Errorf("erro ao desativar perfil: %w", err)
+	}
+	return nil
+}
+
+// restorable retira do estado anterior os segredos vazios: o backend pode
+// não ter informado o segredo guardado, e restaurá-lo vazio o apagaria
+func restorable(previous backend.Settings) backend.Settings {
+	settings := previous.Clone()
+	for key, value := range settings {
+		if value == "" && backend.IsSecret(key) {
+			delete(settings, key)
+		}
+	}
+	return settings
+}
+
+// rollback restaura o snapshot se ninguém tiver confirmado ou revertido antes
+func rollback(b backend.Backend, path string, snap Snapshot, reason string) error {
+	claimed, err := claim(path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(claimed)
+
+	err = Restore(b, snap)
+	outcome := "sucesso"
+	if err != nil {
+		outcome = "falha: " + err.Error()
+		logger.LogError("Erro ao reverter alteração em %s: %v", snap.Interface, err)
+	} else {
+		logger.LogInfo("Alteração em %s revertida (%s)", snap.Interface, reason)
+	}
+	history.Record(history.Action{
+		Action:     "network_rollback",
+		Interface:  snap.Interface,
+		Profile:    snap.ProfileID,
+		Details:    fmt.Sprintf("Interface %s revertida (%s): %s", snap.Interface, reason, outcome),
+		Diff:       history.Diff(snap.Applied, snap.Previous),
+		ModifiedBy: "safeapply",
+	}.WithResult(err))
+	return err
+}
+
+// claim renomeia o arquivo de estado para que apenas um entre confirmação e
+// restauração aconteça, mesmo entre processos diferentes
+func claim(path string) (string, error) {
+	claimed := path + ".claimed"
+	if err := os.Rename(path, claimed); err != nil {
+		if os.IsNotExist(err) {
+			return "", ErrRolledBack
+		}
+		return "", fmt.Errorf("erro ao acessar estado pendente: %w", err)
+	}
+	return claimed, nil
+}
+
+// writeSnapshot grava o snapshot com permissão restrita ao root
+func writeSnapshot(snap Snapshot) (string, error) {
+	dir := currentStateDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("erro ao criar diretório de estado: %w", err)
+	}
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("%s-%d.json", sanitize(snap.Interface), time.Now().UnixNano())
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("erro ao gravar estado pendente: %w", err)
+	}
+	return path, nil
+}
+
+// readSnapshot lê um snapshot gravado por writeSnapshot
+func readSnapshot(path string) (Snapshot, error) {
+	var snap Snapshot
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return snap, err
+	}
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return snap, fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+	return snap, nil
+}
+
+// sanitize mantém apenas caracteres seguros para nome de arquivo
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
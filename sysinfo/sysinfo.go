@@ -11,39 +11,97 @@ import (
 	"time"
 )
 
-func GetSystemInfo() string {
-	now := time.Now().Format("Mon Jan 2 15:04:05 MST 2006")
-	cores, err := countCPUCores()
-	if err != nil {
-		cores = runtime.NumCPU()
-	}
+// UsageInfo descreve o uso de memória ou de disco
+type UsageInfo struct {
+	TotalBytes  uint64  `json:"total_bytes" yaml:"total_bytes"`
+	UsedBytes   uint64  `json:"used_bytes" yaml:"used_bytes"`
+	FreeBytes   uint64  `json:"free_bytes" yaml:"free_bytes"`
+	UsedPercent float64 `json:"used_percent" yaml:"used_percent"`
+}
 
-	cpuModel := getCPUModel()
+// SystemInfo reúne as informações do sistema exibidas na tela de informações,
+// em formato estruturado para exportação em JSON ou YAML
+type SystemInfo struct {
+	Time          time.Time  `json:"time" yaml:"time"`
+	Hostname      string     `json:"hostname" yaml:"hostname"`
+	OS            string     `json:"os" yaml:"os"`
+	Kernel        string     `json:"kernel" yaml:"kernel"`
+	Architecture  string     `json:"architecture" yaml:"architecture"`
+	UptimeSeconds float64    `json:"uptime_seconds" yaml:"uptime_seconds"`
+	CPUModel      string     `json:"cpu_model" yaml:"cpu_model"`
+	CPUCores      int        `json:"cpu_cores" yaml:"cpu_cores"`
+	LoadAverage   float64    `json:"load_average" yaml:"load_average"`
+	Memory        *UsageInfo `json:"memory,omitempty" yaml:"memory,omitempty"`
+	Disk          *UsageInfo `json:"disk,omitempty" yaml:"disk,omitempty"`
+}
 
-	loadAvg, err := getLoadAverage()
-	if err != nil {
-		loadAvg = 0.0
+// CollectSystemInfo coleta as informações do sistema. Valores que não puderem
+// ser lidos ficam vazios (memória e disco ficam nulos).
+func CollectSystemInfo() SystemInfo {
+	info := SystemInfo{
+		Time:         time.Now(),
+		OS:           runtime.GOOS,
+		Kernel:       getKernelVersion(),
+		Architecture: runtime.GOARCH,
+		CPUModel:     getCPUModel(),
 	}
 
-	memInfo, memPercent, err := getMemoryInfo()
+	cores, err := countCPUCores()
 	if err != nil {
-		memInfo = "N/A"
-		memPercent = 0.0
+		cores = runtime.NumCPU()
 	}
+	info.CPUCores = cores
 
-	diskInfo, diskPercent, err := getDiskInfo()
-	if err != nil {
-		diskInfo = "N/A"
-		diskPercent = 0.0
+	if loadAvg, err := getLoadAverage(); err == nil {
+		info.LoadAverage = loadAvg
+	}
+	if uptime, err := getUptimeSeconds(); err == nil {
+		info.UptimeSeconds = uptime
+	}
+	if mem, err := getMemoryInfo(); err == nil {
+		info.Memory = mem
+	}
+	if disk, err := getDiskInfo(); err == nil {
+		info.Disk = disk
 	}
 
 	hostname, err := os.Hostname()
 	if err != nil {
 		hostname = "Unknown"
 	}
+	info.Hostname = hostname
+
+	return info
+}
+
+// GetSystemInfo coleta e formata as informações do sistema para o tview
+func GetSystemInfo() string {
+	return RenderSystemInfo(CollectSystemInfo())
+}
+
+// RenderSystemInfo formata as informações do sistema com as cores do tview
+func RenderSystemInfo(info SystemInfo) string {
+	now := info.Time.Format("Mon Jan 2 15:04:05 MST 2006")
+	hostname := info.Hostname
+	cores := info.CPUCores
+	cpuModel := info.CPUModel
+	loadAvg := info.LoadAverage
+	kernelVer := info.Kernel
+	uptime := formatUptime(info.UptimeSeconds)
+
+	memInfo, memPercent := "N/A", 0.0
+	if info.Memory != nil {
+		memInfo = fmt.Sprintf("%.2f GB / %.2f GB", gigabytes(info.Memory.UsedBytes), gigabytes(info.Memory.TotalBytes))
+		memPercent = info.Memory.UsedPercent
+	}
 
-	uptime := getUptime()
-	kernelVer := getKernelVersion()
+	diskInfo, diskPercent := "N/A", 0.0
+	if info.Disk != nil && info.Disk.TotalBytes > 0 {
+		diskInfo = fmt.Sprintf("%.2f GB / %.2f GB (%.2f%% free)",
+			gigabytes(info.Disk.UsedBytes), gigabytes(info.Disk.TotalBytes),
+			float64(info.Disk.FreeBytes)*100/float64(info.Disk.TotalBytes))
+		diskPercent = info.Disk.UsedPercent
+	}
 
 	width := 65 // Total width for centering
 	centerText := func(text string) string {
@@ -66,9 +124,9 @@ func GetSystemInfo() string {
 	output += fmt.Sprintf("[yellow]%s[white]\n", centerText("├─────────────────────────────────────────────────────────────────┤"))
 	output += fmt.Sprintf("[yellow]%s[white]\n", centerText("│[cyan]                      SYSTEM SPECIFICATIONS                      [yellow]│"))
 	output += fmt.Sprintf("[yellow]%s[white]\n", centerText("├─────────────────────────────────────────────────────────────────┤"))
-	output += fmt.Sprintf("[yellow]%s[white]\n", centerText(fmt.Sprintf("│[white] [green]🐧 OS:[white]          %-47s [yellow]│", runtime.GOOS)))
+	output += fmt.Sprintf("[yellow]%s[white]\n", centerText(fmt.Sprintf("│[white] [green]🐧 OS:[white]          %-47s [yellow]│", info.OS)))
 	output += fmt.Sprintf("[yellow]%s[white]\n", centerText(fmt.Sprintf("│[white] [green]🔄 Kernel:[white]      %-47s [yellow]│", kernelVer)))
-	output += fmt.Sprintf("[yellow]%s[white]\n", centerText(fmt.Sprintf("│[white] [green]⚙️  Architecture:[white] %-47s [yellow]│", runtime.GOARCH)))
+	output += fmt.Sprintf("[yellow]%s[white]\n", centerText(fmt.Sprintf("│[white] [green]⚙️  Architecture:[white] %-47s [yellow]│", info.Architecture)))
 	output += fmt.Sprintf("[yellow]%s[white]\n", centerText(fmt.Sprintf("│[white] [green]⏱️  Uptime:[white]      %-47s [yellow]│", uptime)))
 	output += fmt.Sprintf("[yellow]%s[white]\n", centerText("├─────────────────────────────────────────────────────────────────┤"))
 	output += fmt.Sprintf("[yellow]%s[white]\n", centerText("│[cyan]                        HARDWARE STATUS                         [yellow]│"))
@@ -90,6 +148,12 @@ func GetSystemInfo() string {
 	return output
 }
 
+// RenderSystemInfoText formata as informações do sistema sem as marcações de
+// cor do tview, para exibição fora da interface
+func RenderSystemInfoText(info SystemInfo) string {
+	return stripColor(RenderSystemInfo(info))
+}
+
 // Helper function to strip color codes for proper centering
 func stripColor(text string) string {
 	re := regexp.MustCompile(`\[[^\]]*\]`)
@@ -151,24 +215,23 @@ func getKernelVersion() string {
 	return string(data)[:30] + "..." // Trunca se for muito longo
 }
 
-// Obtém o tempo de atividade do sistema
-func getUptime() string {
+// Obtém o tempo de atividade do sistema em segundos
+func getUptimeSeconds() (float64, error) {
 	data, err := os.ReadFile("/proc/uptime")
 	if err != nil {
-		return "Unknown"
+		return 0, err
 	}
 
 	parts := strings.Split(string(data), " ")
-	if len(parts) < 1 {
-		return "Unknown"
-	}
+	return strconv.ParseFloat(parts[0], 64)
+}
 
-	uptime, err := strconv.ParseFloat(parts[0], 64)
-	if err != nil {
+// Converte o tempo de atividade em um formato mais legível
+func formatUptime(uptime float64) string {
+	if uptime <= 0 {
 		return "Unknown"
 	}
 
-	// Converte segundos em um formato mais legível
 	days := int(uptime / 86400)
 	hours := int((uptime - float64(days)*86400) / 3600)
 	minutes := int((uptime - float64(days)*86400 - float64(hours)*3600) / 60)
@@ -182,6 +245,11 @@ func getUptime() string {
 	return result
 }
 
+// Converte bytes para GB
+func gigabytes(bytes uint64) float64 {
+	return float64(bytes) / (1024 * 1024 * 1024)
+}
+
 // Conta o número de núcleos da CPU
 func countCPUCores() (int, error) {
 	data, err := os.ReadFile("/proc/cpuinfo")
@@ -212,55 +280,61 @@ func getLoadAverage() (float64, error) {
 }
 
 // Obtém informações sobre a memória
-func getMemoryInfo() (string, float64, error) {
+func getMemoryInfo() (*UsageInfo, error) {
 	data, err := os.ReadFile("/proc/meminfo")
 	if err != nil {
-		return "", 0.0, err
+		return nil, err
 	}
 
 	lines := strings.Split(string(data), "\n")
-	var total, free int
+	var total, free uint64
 	for _, line := range lines {
 		if strings.HasPrefix(line, "MemTotal:") {
-			total, err = strconv.Atoi(strings.Fields(line)[1])
+			total, err = strconv.ParseUint(strings.Fields(line)[1], 10, 64)
 			if err != nil {
-				return "", 0.0, err
+				return nil, err
 			}
 		}
 		if strings.HasPrefix(line, "MemAvailable:") {
-			free, err = strconv.Atoi(strings.Fields(line)[1])
+			free, err = strconv.ParseUint(strings.Fields(line)[1], 10, 64)
 			if err != nil {
-				return "", 0.0, err
+				return nil, err
 			}
 		}
 	}
+	if total == 0 {
+		return nil, fmt.Errorf("MemTotal não encontrado em /proc/meminfo")
+	}
+
+	// Os valores de /proc/meminfo são em kB
 	used := total - free
-	usedPercent := float64(used) * 100.0 / float64(total)
-	memUsage := fmt.Sprintf("%.2f GB / %.2f GB", float64(used)/1048576, float64(total)/1048576)
-	return memUsage, usedPercent, nil
+	return &UsageInfo{
+		TotalBytes:  total * 1024,
+		UsedBytes:   used * 1024,
+		FreeBytes:   free * 1024,
+		UsedPercent: float64(used) * 100.0 / float64(total),
+	}, nil
 }
 
 // Obtém informações sobre o uso do disco
-func getDiskInfo() (string, float64, error) {
+func getDiskInfo() (*UsageInfo, error) {
 	var stat syscall.Statfs_t
 	err := syscall.Statfs("/", &stat)
 	if err != nil {
-		return "", 0.0, err
+		return nil, err
 	}
 
 	total := stat.Blocks * uint64(stat.Bsize) // total em bytes
 	free := stat.Bfree * uint64(stat.Bsize)   // livre em bytes
 	used := total - free                      // usado em bytes
+	if total == 0 {
+		return nil, fmt.Errorf("tamanho do disco desconhecido")
+	}
 
-	// Calculando a porcentagem de uso
-	usedPercent := float64(used) * 100.0 / float64(total)
-
-	// Convertendo para GB com duas casas decimais
-	totalGB := float64(total) / (1024 * 1024 * 1024)
-	usedGB := float64(used) / (1024 * 1024 * 1024)
-	freeGB := float64(free) / (1024 * 1024 * 1024)
-
-	diskUsage := fmt.Sprintf("%.2f GB / %.2f GB (%.2f%% free)",
-		usedGB, totalGB, (freeGB/totalGB)*100)
-	return diskUsage, usedPercent, nil
-}
\ No newline at end of file
+	return &UsageInfo{
+		TotalBytes:  total,
+		UsedBytes:   used,
+		FreeBytes:   free,
+		UsedPercent: float64(used) * 100.0 / float64(total),
+	}, nil
+}